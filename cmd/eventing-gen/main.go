@@ -1,14 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
+	"fmt"
 	"github.com/rsmidt/soccerbuddy/internal/core"
 	"go/ast"
+	"go/format"
 	"go/token"
 	"golang.org/x/tools/go/packages"
+	"io"
 	"log"
 	"os"
 	"path"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 )
@@ -16,6 +23,7 @@ import (
 var (
 	eventTypRe = regexp.MustCompile(`^(.*Event)Type$`)
 	eventVerRe = regexp.MustCompile(`^(.*Event)Version$`)
+	upcasterRe = regexp.MustCompile(`^(.*Event)V(\d+)ToV(\d+)$`)
 )
 
 type event struct {
@@ -25,6 +33,17 @@ type event struct {
 	PkgName string
 	PkgPath string
 	Agg     string
+
+	// Upcasters are ordered by their source version and end at the current version.
+	Upcasters []upcaster
+}
+
+type upcaster struct {
+	Func        string
+	FromVersion string
+
+	from int
+	to   int
 }
 
 type eventmeta struct {
@@ -41,9 +60,14 @@ type eventlocator struct {
 	name string
 }
 
+type upcasterlocator struct {
+	pkgPath string
+	name    string
+}
+
 func main() {
 	config := packages.Config{
-		Mode: packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
 		Dir:  path.Join(core.Root, "internal"),
 	}
 	pkgs, err := packages.Load(&config, "./...")
@@ -55,117 +79,232 @@ func main() {
 		return
 	}
 
-	var events []event
+	events, err := collectEvents(pkgs)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	var fnameToAggTyp = make(map[string]string)
-	var locToEventMeta = make(map[eventlocator]eventmeta)
+	// Create gen directory if not exists.
+	if _, err := os.Stat(path.Join(core.Root, "gen", "eventregistry")); os.IsNotExist(err) {
+		if err := os.MkdirAll(path.Join(core.Root, "gen", "eventregistry"), 0755); err != nil {
+			log.Fatalf("failed to create directory: %v", err)
+		}
+	}
+	destFile := path.Join(core.Root, "gen", "eventregistry", "registry_gen.go")
+	f, err := os.Create(destFile)
+	if err != nil {
+		log.Fatalf("failed to create file: %v", err)
+	}
+	defer f.Close()
+	if err := render(f, events); err != nil {
+		log.Fatalf("failed to render registry: %v", err)
+	}
+}
 
+// collector gathers the events, their metadata and upcasters of the inspected packages.
+type collector struct {
+	events         []event
+	fnameToAggTyp  map[string]string
+	locToEventMeta map[eventlocator]eventmeta
+	locToUpcasters map[upcasterlocator][]upcaster
+}
+
+// collectEvents returns all events declared in pkgs along with their chained upcasters.
+func collectEvents(pkgs []*packages.Package) ([]event, error) {
+	c := collector{
+		fnameToAggTyp:  make(map[string]string),
+		locToEventMeta: make(map[eventlocator]eventmeta),
+		locToUpcasters: make(map[upcasterlocator][]upcaster),
+	}
 	for _, pkg := range pkgs {
 		for _, file := range pkg.Syntax {
-			ast.Inspect(file, func(n ast.Node) bool {
-				decl, ok := n.(*ast.GenDecl)
-				if !ok {
-					return true
+			filename := pkg.Fset.Position(file.Package).Filename
+			for _, decl := range file.Decls {
+				var err error
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					err = c.inspectFunc(pkg, decl)
+				case *ast.GenDecl:
+					err = c.inspectGenDecl(pkg, filename, decl)
 				}
-
-				switch decl.Tok {
-				case token.CONST:
-					for _, spec := range decl.Specs {
-						vspec := spec.(*ast.ValueSpec)
-						if len(vspec.Values) != 1 {
-							continue
-						}
-						name := vspec.Names[0].Name
-						filename := pkg.Fset.Position(file.Package).Filename
-						if strings.HasSuffix(name, "AggregateType") {
-							typ := vspec.Values[0].(*ast.CallExpr).Args[0].(*ast.BasicLit).Value
-							fnameToAggTyp[pkg.PkgPath] = strings.Replace(typ, `"`, "", -1)
-							continue
-						}
-						eventTypRes := eventTypRe.FindStringSubmatch(name)
-						if len(eventTypRes) == 2 {
-							name := eventTypRes[1]
-							// Update or create eventmeta.
-							loc := eventlocator{path: filename, name: name}
-							em, ok := locToEventMeta[loc]
-							if !ok {
-								em = eventmeta{}
-							}
-							em.Typ = strings.Replace(vspec.Values[0].(*ast.CallExpr).Args[0].(*ast.BasicLit).Value, `"`, "", -1)
-							locToEventMeta[loc] = em
-							continue
-						}
-						eventVerRes := eventVerRe.FindStringSubmatch(name)
-						if len(eventVerRes) == 2 {
-							name := eventVerRes[1]
-							// Update or create eventmeta.
-							loc := eventlocator{path: filename, name: name}
-							em, ok := locToEventMeta[loc]
-							if !ok {
-								em = eventmeta{}
-							}
-							em.Version = strings.Replace(vspec.Values[0].(*ast.CallExpr).Args[0].(*ast.BasicLit).Value, `"`, "", -1)
-							locToEventMeta[loc] = em
-							continue
-						}
-					}
-					return false
-				case token.TYPE:
-					for _, spec := range decl.Specs {
-						tspec := spec.(*ast.TypeSpec)
-						stype, ok := tspec.Type.(*ast.StructType)
-						if !ok {
-							continue
-						}
-						for _, field := range stype.Fields.List {
-							starxpr, ok := field.Type.(*ast.StarExpr)
-							if !ok {
-								continue
-							}
-							selxpr, ok := starxpr.X.(*ast.SelectorExpr)
-							if !ok {
-								continue
-							}
-							if selxpr.Sel.Name == "EventBase" {
-								name := tspec.Name.Name
-								filename := pkg.Fset.Position(file.Package).Filename
-								loc := eventlocator{path: filename, name: name}
-								em, ok := locToEventMeta[loc]
-								if !ok || !em.Isset() {
-									log.Fatalf("eventmeta not found for %s", name)
-								}
-								agg, ok := fnameToAggTyp[pkg.PkgPath]
-								if !ok {
-									log.Fatalf("aggregate type not found for %s", filename)
-								}
-								events = append(events, event{
-									eventmeta: em,
-									Name:      name,
-									PkgName:   pkg.Name,
-									PkgPath:   pkg.PkgPath,
-									Agg:       agg,
-								})
-								break
-							}
-						}
-					}
-					return false
+				if err != nil {
+					return nil, err
 				}
+			}
+		}
+	}
+
+	// Chain the upcasters of each event.
+	for i, e := range c.events {
+		loc := upcasterlocator{pkgPath: e.PkgPath, name: e.Name}
+		upcasters, ok := c.locToUpcasters[loc]
+		if !ok {
+			continue
+		}
+		delete(c.locToUpcasters, loc)
+		chained, err := chainUpcasters(e, upcasters)
+		if err != nil {
+			return nil, err
+		}
+		c.events[i].Upcasters = chained
+	}
+	for loc, upcasters := range c.locToUpcasters {
+		return nil, fmt.Errorf("event %s not found for upcaster %s", loc.name, upcasters[0].Func)
+	}
+	return c.events, nil
+}
+
+func (c *collector) inspectFunc(pkg *packages.Package, fn *ast.FuncDecl) error {
+	upcasterRes := upcasterRe.FindStringSubmatch(fn.Name.Name)
+	if fn.Recv == nil && len(upcasterRes) == 4 {
+		from, _ := strconv.Atoi(upcasterRes[2])
+		to, _ := strconv.Atoi(upcasterRes[3])
+		loc := upcasterlocator{pkgPath: pkg.PkgPath, name: upcasterRes[1]}
+		c.locToUpcasters[loc] = append(c.locToUpcasters[loc], upcaster{
+			Func:        fn.Name.Name,
+			FromVersion: "v" + upcasterRes[2],
+			from:        from,
+			to:          to,
+		})
+	}
+	if fn.Body == nil {
+		return nil
+	}
+
+	// The registry can only refer to package level declarations, so events
+	// declared inside function bodies are rejected instead of being skipped.
+	var err error
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		decl, ok := n.(*ast.GenDecl)
+		if !ok || err != nil {
+			return err == nil
+		}
+		if name, ok := declaresEvent(decl); ok {
+			err = fmt.Errorf("%s: event %s must be declared at package level", pkg.Fset.Position(decl.Pos()), name)
+		}
+		return true
+	})
+	return err
+}
 
-				return true
+func (c *collector) inspectGenDecl(pkg *packages.Package, filename string, decl *ast.GenDecl) error {
+	switch decl.Tok {
+	case token.CONST:
+		for _, spec := range decl.Specs {
+			vspec := spec.(*ast.ValueSpec)
+			if len(vspec.Values) != 1 {
+				continue
+			}
+			name := vspec.Names[0].Name
+			if strings.HasSuffix(name, "AggregateType") {
+				c.fnameToAggTyp[pkg.PkgPath] = constString(vspec)
+				continue
+			}
+			eventTypRes := eventTypRe.FindStringSubmatch(name)
+			if len(eventTypRes) == 2 {
+				// Update or create eventmeta.
+				loc := eventlocator{path: filename, name: eventTypRes[1]}
+				em := c.locToEventMeta[loc]
+				em.Typ = constString(vspec)
+				c.locToEventMeta[loc] = em
+				continue
+			}
+			eventVerRes := eventVerRe.FindStringSubmatch(name)
+			if len(eventVerRes) == 2 {
+				// Update or create eventmeta.
+				loc := eventlocator{path: filename, name: eventVerRes[1]}
+				em := c.locToEventMeta[loc]
+				em.Version = constString(vspec)
+				c.locToEventMeta[loc] = em
+				continue
+			}
+		}
+	case token.TYPE:
+		for _, spec := range decl.Specs {
+			tspec := spec.(*ast.TypeSpec)
+			// Unexported events, like the ones of test helpers, cannot be referred to by the registry.
+			if !tspec.Name.IsExported() || !embedsEventBase(tspec) {
+				continue
+			}
+			name := tspec.Name.Name
+			em, ok := c.locToEventMeta[eventlocator{path: filename, name: name}]
+			if !ok || !em.Isset() {
+				return fmt.Errorf("eventmeta not found for %s", name)
+			}
+			agg, ok := c.fnameToAggTyp[pkg.PkgPath]
+			if !ok {
+				return fmt.Errorf("aggregate type not found for %s", filename)
+			}
+			c.events = append(c.events, event{
+				eventmeta: em,
+				Name:      name,
+				PkgName:   pkg.Name,
+				PkgPath:   pkg.PkgPath,
+				Agg:       agg,
 			})
 		}
 	}
+	return nil
+}
 
-	// Generate registry.
-	var uniqueImports = make(map[string]struct{})
-	for _, e := range events {
-		uniqueImports[e.PkgPath] = struct{}{}
+// declaresEvent reports whether decl declares an event or its metadata and returns the name of the event.
+func declaresEvent(decl *ast.GenDecl) (string, bool) {
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.ValueSpec:
+			for _, ident := range spec.Names {
+				if res := eventTypRe.FindStringSubmatch(ident.Name); len(res) == 2 {
+					return res[1], true
+				}
+				if res := eventVerRe.FindStringSubmatch(ident.Name); len(res) == 2 {
+					return res[1], true
+				}
+			}
+		case *ast.TypeSpec:
+			if embedsEventBase(spec) {
+				return spec.Name.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// embedsEventBase reports whether the type is a struct embedding *eventing.EventBase.
+func embedsEventBase(tspec *ast.TypeSpec) bool {
+	stype, ok := tspec.Type.(*ast.StructType)
+	if !ok {
+		return false
 	}
+	for _, field := range stype.Fields.List {
+		starxpr, ok := field.Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		selxpr, ok := starxpr.X.(*ast.SelectorExpr)
+		if ok && selxpr.Sel.Name == "EventBase" {
+			return true
+		}
+	}
+	return false
+}
+
+// constString returns the string literal a typed constant like eventing.EventType("x") is declared with.
+func constString(vspec *ast.ValueSpec) string {
+	return strings.Replace(vspec.Values[0].(*ast.CallExpr).Args[0].(*ast.BasicLit).Value, `"`, "", -1)
+}
+
+// render writes the registry of the events to w.
+func render(w io.Writer, events []event) error {
+	// Sort everything so that the generated registry is stable across runs.
+	events = slices.Clone(events)
+	slices.SortFunc(events, func(a, b event) int {
+		return cmp.Or(cmp.Compare(a.PkgPath, b.PkgPath), cmp.Compare(a.Name, b.Name))
+	})
 	var imports []string
-	for imp := range uniqueImports {
-		imports = append(imports, imp)
+	for _, e := range events {
+		imports = append(imports, e.PkgPath)
 	}
+	imports = slices.Compact(imports)
 
 	type registryData struct {
 		Imports []string
@@ -173,24 +312,44 @@ func main() {
 	}
 
 	tmpl := template.Must(template.New("registry").Parse(registryTmpl))
-	data := registryData{
-		Imports: imports,
-		Events:  events,
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, &registryData{Imports: imports, Events: events}); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
 	}
-	// Create gen directory if not exists.
-	if _, err := os.Stat(path.Join(core.Root, "gen", "eventregistry")); os.IsNotExist(err) {
-		if err := os.MkdirAll(path.Join(core.Root, "gen", "eventregistry"), 0755); err != nil {
-			log.Fatalf("failed to create directory: %v", err)
-		}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format registry: %w", err)
 	}
-	destFile := path.Join(core.Root, "gen", "eventregistry", "registry_gen.go")
-	f, err := os.Create(destFile)
+	_, err = w.Write(src)
+	return err
+}
+
+// chainUpcasters orders the upcasters of the event and verifies that they form
+// a gapless chain of single version steps ending at the current version of the event.
+func chainUpcasters(e event, upcasters []upcaster) ([]upcaster, error) {
+	current, err := strconv.Atoi(strings.TrimPrefix(e.Version, "v"))
 	if err != nil {
-		log.Fatalf("failed to create file: %v", err)
+		return nil, fmt.Errorf("version %s of %s does not support upcasting", e.Version, e.Name)
 	}
-	if err := tmpl.Execute(f, &data); err != nil {
-		log.Fatalf("failed to execute template: %v", err)
+	for _, u := range upcasters {
+		// Only allowing single steps forward rules out cycles in the chain.
+		if u.to != u.from+1 {
+			return nil, fmt.Errorf("upcaster %s must migrate to the next version", u.Func)
+		}
+	}
+	upcasters = slices.Clone(upcasters)
+	slices.SortFunc(upcasters, func(a, b upcaster) int {
+		return a.from - b.from
+	})
+	for i, u := range upcasters {
+		if i > 0 && upcasters[i-1].to != u.from {
+			return nil, fmt.Errorf("upcasters of %s have a gap or duplicate before %s", e.Name, u.Func)
+		}
 	}
+	if last := upcasters[len(upcasters)-1]; last.to != current {
+		return nil, fmt.Errorf("upcaster %s does not end at the current version %s of %s", last.Func, e.Version, e.Name)
+	}
+	return upcasters, nil
 }
 
 const registryTmpl = `package eventregistry
@@ -206,6 +365,11 @@ import (
 	{{end}}
 )
 
+var (
+	{{range .Events}}{{$e := .}}{{range .Upcasters}}
+	_ eventing.Upcaster = {{$e.PkgName}}.{{.Func}}{{end}}{{end}}
+)
+
 var Default = &genRegistry{}
 
 type genRegistry struct {}
@@ -221,14 +385,23 @@ func (r *genRegistry) MapFrom(
 	insertedAt       time.Time,
 	payload          []byte,
 ) (*eventing.JournalEvent, error) {
-	base := eventing.NewEventBase(aggregateID, aggregateType, eventVersion, eventType)
 	combinedID := fmt.Sprintf("%s::%s%s", aggregateType, eventType, eventVersion)
 
 	switch combinedID {
-	{{range .Events}}
+	{{range .Events}}{{$e := .}}
+	{{range .Upcasters}}
+	case "{{$e.Agg}}::{{$e.Typ}}{{.FromVersion}}":
+		upcasted, err := {{$e.PkgName}}.{{.Func}}(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upcast event: %w", err)
+		}
+		payload = upcasted
+		fallthrough
+	{{end}}
 	case "{{.Agg}}::{{.Typ}}{{.Version}}":
+		// Upcasted events always carry the current version.
 		event := &{{.PkgName}}.{{.Name}}{
-			EventBase: base,
+			EventBase: eventing.NewEventBase(aggregateID, aggregateType, {{.PkgName}}.{{.Name}}Version, eventType),
 		}
 		if err := json.Unmarshal(payload, event); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal event: %w", err)
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var update = flag.Bool("update", false, "update the golden registry in testdata")
+
+func TestChainUpcasters(t *testing.T) {
+	v1ToV2 := upcaster{Func: "EV1ToV2", FromVersion: "v1", from: 1, to: 2}
+	v2ToV3 := upcaster{Func: "EV2ToV3", FromVersion: "v2", from: 2, to: 3}
+	v3ToV4 := upcaster{Func: "EV3ToV4", FromVersion: "v3", from: 3, to: 4}
+	v2ToV1 := upcaster{Func: "EV2ToV1", FromVersion: "v2", from: 2, to: 1}
+	v1ToV3 := upcaster{Func: "EV1ToV3", FromVersion: "v1", from: 1, to: 3}
+
+	tests := []struct {
+		name      string
+		version   string
+		upcasters []upcaster
+		expected  []upcaster
+		err       string
+	}{
+		{name: "single hop", version: "v2", upcasters: []upcaster{v1ToV2}, expected: []upcaster{v1ToV2}},
+		{name: "multi hop in any order", version: "v4", upcasters: []upcaster{v3ToV4, v1ToV2, v2ToV3}, expected: []upcaster{v1ToV2, v2ToV3, v3ToV4}},
+		{name: "chain starting after v1", version: "v4", upcasters: []upcaster{v3ToV4, v2ToV3}, expected: []upcaster{v2ToV3, v3ToV4}},
+		{name: "gap", version: "v4", upcasters: []upcaster{v1ToV2, v3ToV4}, err: "gap"},
+		{name: "duplicate", version: "v2", upcasters: []upcaster{v1ToV2, v1ToV2}, err: "duplicate"},
+		{name: "cycle", version: "v2", upcasters: []upcaster{v1ToV2, v2ToV1}, err: "next version"},
+		{name: "skipping versions", version: "v3", upcasters: []upcaster{v1ToV3}, err: "next version"},
+		{name: "ending before current version", version: "v3", upcasters: []upcaster{v1ToV2}, err: "current version"},
+		{name: "ending after current version", version: "v2", upcasters: []upcaster{v1ToV2, v2ToV3}, err: "current version"},
+		{name: "unversioned event", version: "latest", upcasters: []upcaster{v1ToV2}, err: "does not support upcasting"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := event{eventmeta: eventmeta{Typ: "e", Version: tt.version}, Name: "E"}
+			chained, err := chainUpcasters(e, tt.upcasters)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to chain upcasters: %v", err)
+			}
+			if len(chained) != len(tt.expected) {
+				t.Fatalf("expected %d upcasters, got %d", len(tt.expected), len(chained))
+			}
+			for i := range chained {
+				if chained[i] != tt.expected[i] {
+					t.Errorf("expected upcaster %d to be %s, got %s", i, tt.expected[i].Func, chained[i].Func)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectEventsRejectsNestedEvents(t *testing.T) {
+	pkgs := loadPackages(t, "./testdata/nested")
+
+	_, err := collectEvents(pkgs)
+	if err == nil || !strings.Contains(err.Error(), "NestedEvent must be declared at package level") {
+		t.Errorf("expected the nested event to be rejected, got %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	events, err := collectEvents(loadPackages(t, "./testdata/fixture"))
+	if err != nil {
+		t.Fatalf("failed to collect events: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := render(&buf, events); err != nil {
+		t.Fatalf("failed to render registry: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "registry", "registry_gen.go")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("failed to update golden registry: %v", err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden registry: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("generated registry differs from %s, rerun with -update:\n%s", golden, buf.String())
+	}
+
+	// The golden registry is a package of its own, so type-checking it proves that the generated code compiles.
+	pkgs := loadPackages(t, "./testdata/registry")
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Errorf("generated registry does not compile")
+	}
+}
+
+func loadPackages(t *testing.T, pattern string) []*packages.Package {
+	t.Helper()
+	config := packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+	}
+	pkgs, err := packages.Load(&config, pattern)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", pattern, err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatalf("failed to load %s", pattern)
+	}
+	return pkgs
+}
@@ -0,0 +1,8 @@
+// Package fixture declares events the registry generator is tested against.
+package fixture
+
+import "github.com/rsmidt/soccerbuddy/internal/eventing"
+
+const (
+	WidgetAggregateType = eventing.AggregateType("widget")
+)
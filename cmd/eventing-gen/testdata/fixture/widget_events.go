@@ -0,0 +1,50 @@
+package fixture
+
+import (
+	"bytes"
+
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+)
+
+const (
+	WidgetCreatedEventType    = eventing.EventType("widget_created")
+	WidgetCreatedEventVersion = eventing.EventVersion("v3")
+)
+
+type WidgetCreatedEvent struct {
+	*eventing.EventBase
+
+	Name string `json:"name"`
+}
+
+func (w *WidgetCreatedEvent) IsShredded() bool {
+	return false
+}
+
+func WidgetCreatedEventV2ToV3(payload []byte) ([]byte, error) {
+	return bytes.ReplaceAll(payload, []byte(`"title"`), []byte(`"name"`)), nil
+}
+
+func WidgetCreatedEventV1ToV2(payload []byte) ([]byte, error) {
+	// Declarations in function bodies that are no events are fine.
+	const label = `"label"`
+	return bytes.ReplaceAll(payload, []byte(label), []byte(`"title"`)), nil
+}
+
+const (
+	WidgetDeletedEventType    = eventing.EventType("widget_deleted")
+	WidgetDeletedEventVersion = eventing.EventVersion("v1")
+)
+
+type WidgetDeletedEvent struct {
+	*eventing.EventBase
+}
+
+func (w *WidgetDeletedEvent) IsShredded() bool {
+	return false
+}
+
+// widgetTestEvent is unexported and therefore not registered.
+type widgetTestEvent struct {
+	*eventing.EventBase
+}
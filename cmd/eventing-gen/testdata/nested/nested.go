@@ -0,0 +1,15 @@
+// Package nested declares an event in a function body, which the registry cannot refer to.
+package nested
+
+import "github.com/rsmidt/soccerbuddy/internal/eventing"
+
+const (
+	NestedAggregateType = eventing.AggregateType("nested")
+)
+
+func newEvent() *eventing.EventBase {
+	type NestedEvent struct {
+		*eventing.EventBase
+	}
+	return (&NestedEvent{}).EventBase
+}
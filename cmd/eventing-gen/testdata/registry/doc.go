@@ -0,0 +1,7 @@
+// Package eventregistry holds the registry generated from the fixture package.
+// It is type-checked by the tests to prove that the generated code compiles.
+package eventregistry
+
+import "github.com/rsmidt/soccerbuddy/internal/eventing"
+
+var _ eventing.JournalEventMapper = Default
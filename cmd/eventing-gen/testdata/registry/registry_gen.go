@@ -0,0 +1,76 @@
+package eventregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"time"
+
+	"github.com/rsmidt/soccerbuddy/cmd/eventing-gen/testdata/fixture"
+)
+
+var (
+	_ eventing.Upcaster = fixture.WidgetCreatedEventV1ToV2
+	_ eventing.Upcaster = fixture.WidgetCreatedEventV2ToV3
+)
+
+var Default = &genRegistry{}
+
+type genRegistry struct{}
+
+func (r *genRegistry) MapFrom(
+	aggregateID eventing.AggregateID,
+	aggregateType eventing.AggregateType,
+	eventVersion eventing.EventVersion,
+	eventType eventing.EventType,
+	eventID eventing.EventID,
+	aggregateVersion eventing.AggregateVersion,
+	journalPosition eventing.JournalPosition,
+	insertedAt time.Time,
+	payload []byte,
+) (*eventing.JournalEvent, error) {
+	combinedID := fmt.Sprintf("%s::%s%s", aggregateType, eventType, eventVersion)
+
+	switch combinedID {
+
+	case "widget::widget_createdv1":
+		upcasted, err := fixture.WidgetCreatedEventV1ToV2(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upcast event: %w", err)
+		}
+		payload = upcasted
+		fallthrough
+
+	case "widget::widget_createdv2":
+		upcasted, err := fixture.WidgetCreatedEventV2ToV3(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upcast event: %w", err)
+		}
+		payload = upcasted
+		fallthrough
+
+	case "widget::widget_createdv3":
+		// Upcasted events always carry the current version.
+		event := &fixture.WidgetCreatedEvent{
+			EventBase: eventing.NewEventBase(aggregateID, aggregateType, fixture.WidgetCreatedEventVersion, eventType),
+		}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+		return eventing.NewJournalEvent(event, eventID, aggregateVersion, journalPosition, insertedAt), nil
+
+	case "widget::widget_deletedv1":
+		// Upcasted events always carry the current version.
+		event := &fixture.WidgetDeletedEvent{
+			EventBase: eventing.NewEventBase(aggregateID, aggregateType, fixture.WidgetDeletedEventVersion, eventType),
+		}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+		return eventing.NewJournalEvent(event, eventID, aggregateVersion, journalPosition, insertedAt), nil
+
+	default:
+		return nil, errors.New("event not registered")
+	}
+}
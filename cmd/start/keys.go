@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/gen/eventregistry"
+	"github.com/rsmidt/soccerbuddy/internal/config"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	pgeventing "github.com/rsmidt/soccerbuddy/internal/postgres/eventing"
+	"log/slog"
+	"os"
+	"os/signal"
+)
+
+// rotateKeys wraps all crypto-shredding keys with the active key-encryption key.
+// To rotate, configure the new key as active while keeping the old one until the command succeeded.
+func rotateKeys(ctx context.Context, c *config.Config, log *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	pool, err := setupPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	keyring, err := setupKeyring(c)
+	if err != nil {
+		return fmt.Errorf("failed to setup keyring: %w", err)
+	}
+	rotated, err := pgeventing.RotateKeys(ctx, pool, keyring)
+	log.Info("Rotated keys", slog.Int("count", rotated), slog.String("key_id", string(keyring.ActiveKeyID())))
+	if err != nil {
+		return fmt.Errorf("failed to rotate keys: %w", err)
+	}
+	return nil
+}
+
+// encryptPlaintextEvents encrypts the personal data of events that have stored it in plaintext before it was encrypted.
+// Until then, such data stays readable in the journal, even once its owner has been shredded.
+func encryptPlaintextEvents(ctx context.Context, c *config.Config, log *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	pool, err := setupPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	eventCrypto, _, err := setupEventCrypto(ctx, c, log, pool)
+	if err != nil {
+		return err
+	}
+	encrypted, err := pgeventing.EncryptPlaintextEvents(ctx, pool, eventregistry.Default, eventCrypto, domain.PlaintextEventVersions...)
+	log.Info("Encrypted plaintext events", slog.Int("count", encrypted))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt plaintext events: %w", err)
+	}
+	return nil
+}
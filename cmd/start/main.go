@@ -18,9 +18,11 @@ import (
 	"github.com/rsmidt/soccerbuddy/internal/app/queries"
 	"github.com/rsmidt/soccerbuddy/internal/config"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/grpc"
 	"github.com/rsmidt/soccerbuddy/internal/permify"
+	pgauthz "github.com/rsmidt/soccerbuddy/internal/postgres/authz"
 	pgeventing "github.com/rsmidt/soccerbuddy/internal/postgres/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/projector"
 	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
@@ -53,10 +55,30 @@ func main() {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	if err := run(ctx, conf, slog.New(handler)); err != nil && !errors.Is(err, context.Canceled) {
+	command := run
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "rotate-keys":
+			command = rotateKeys
+		case "encrypt-plaintext-events":
+			command = encryptPlaintextEvents
+		case "reset-projection":
+			command = resetProjection
+		case "dead-letters":
+			command = listDeadLetters
+		case "redrive-dead-letter":
+			command = redriveDeadLetter
+		case "reconcile-permissions":
+			command = reconcilePermissions
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+			os.Exit(2)
+		}
+	}
+	if err := command(ctx, conf, slog.New(handler)); err != nil && !errors.Is(err, context.Canceled) {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
 	}
-	os.Exit(1)
 }
 
 func run(ctx context.Context, c *config.Config, log *slog.Logger) (err error) {
@@ -73,80 +95,106 @@ func run(ctx context.Context, c *config.Config, log *slog.Logger) (err error) {
 	}()
 
 	// Setup the postgres connection.
-	dbconfig, err := pgxpool.ParseConfig(c.EventJournal.PG.ConnStr())
+	pool, err := setupPool(ctx, c)
 	if err != nil {
 		return err
 	}
-	dbconfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
-		_, err := conn.Exec(ctx, "SET application_name = 'soccerbuddy'")
+
+	// Setup redis, unless the read models are projected to postgres.
+	var (
+		rdClient rueidis.Client
+		rdLocker rueidislock.Locker
+	)
+	if c.Projection.ReadModels == config.ReadModelsRedis {
+		rdClient, rdLocker, err = setupRedis(c)
 		if err != nil {
 			return err
 		}
-		pgxdecimal.Register(conn.TypeMap())
-		return err
 	}
-	dbconfig.ConnConfig.Tracer = otelpgx.NewTracer()
-	pool, err := pgxpool.NewWithConfig(ctx, dbconfig)
+
+	// Setup the authorizer of the configured engine.
+	authorizer, relationStore, _, err := setupAuthz(ctx, c, log, pool)
 	if err != nil {
-		return fmt.Errorf("failed to create pg pool: %w", err)
+		return err
 	}
-	err = otelpgx.RecordStats(pool)
+
+	// Setup event store.
+	eventCrypto, lookupHasher, err := setupEventCrypto(ctx, c, log, pool)
 	if err != nil {
-		return fmt.Errorf("failed to record postgres OTel stats: %w", err)
+		return err
 	}
-	err = pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) error {
-		return runMigration(ctx, conn.Conn())
-	})
+	// Lookups of personal data might still be stored in plaintext.
+	hashed, err := pgeventing.HashSensitiveLookups(ctx, pool, lookupHasher, domain.SensitiveLookupFields, domain.SensitiveUniqueConstraintFields)
 	if err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		return fmt.Errorf("failed to hash sensitive lookups: %w", err)
 	}
-
-	// Setup redis.
-	rdOpts := rueidis.ClientOption{
-		InitAddress: []string{c.EventJournal.Redis.Host},
-	}
-	rdClient, err := rueidisotel.NewClient(rdOpts)
-	if err != nil {
-		return fmt.Errorf("failed to create redis client: %w", err)
+	if hashed > 0 {
+		log.Info("Hashed sensitive lookups", slog.Int("count", hashed))
 	}
-	rdLocker, err := rueidislock.NewLocker(rueidislock.LockerOption{
-		ClientOption:   rdOpts,
-		KeyMajority:    1,
-		NoLoopTracking: true,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create redis locker: %w", err)
+	outbox := pgeventing.NewOutbox(log, pool, eventregistry.Default, eventCrypto)
+	esOpts := []pgeventing.EventStoreOpt{pgeventing.WithOutbox(outbox)}
+	if !c.EventJournal.Snapshot.Disabled {
+		snapshots := pgeventing.NewSnapshotStore(pool, eventCrypto)
+		esOpts = append(esOpts, pgeventing.WithSnapshots(snapshots, c.EventJournal.Snapshot.Threshold))
 	}
+	es := pgeventing.NewEventStore(log, pool, eventregistry.Default, eventCrypto, esOpts...)
 
-	// Setup permify authorizer.
-	client, err := setupPermifyClient(ctx, c)
-	if err != nil {
-		return fmt.Errorf("failed to setup permify client: %w", err)
+	// Setup projectors.
+	failures := pgeventing.NewProjectionFailureStore(pool)
+	ps := pgeventing.NewProjectorSupervisor(log, pool, es, failures)
+	supervisors := &projector.Supervisors{Postgres: ps, Failures: failures}
+	readModels := projector.NewPostgresReadModels(pool)
+	readModelProjectors := projector.NewPostgresReadModelProjectors()
+	if rdClient != nil {
+		supervisors.Redis = rdeventing.NewProjectorSupervisor(log, es, failures, rdClient, rdLocker)
+		readModels = projector.NewRedisReadModels(rdClient)
+		readModelProjectors = projector.NewRedisReadModelProjectors(rdClient)
+	}
+	if err := supervisors.Register(ctx, relationStore, readModelProjectors); err != nil {
+		return fmt.Errorf("failed to register and init projectors: %v", err)
 	}
-	authorizer := permify.NewAuthorizer(log, client, permifyTenantID)
-	relationStore := permify.NewRelationStore(log, client)
-
-	// Setup event store.
-	eventCrypto := pgeventing.NewEventCrypto(pool)
-	es := pgeventing.NewEventStore(log, pool, eventregistry.Default, eventCrypto)
 
 	// Setup application.
 	repos := assembleRepositories(es)
-	cmds := commands.NewCommands(log, es, authorizer, rdClient, repos)
-	qs := queries.NewQueries(log, es, authorizer, rdClient, repos)
-
-	// Setup projectors.
-	ps := pgeventing.NewProjectorSupervisor(log, pool, es)
-	rds := rdeventing.NewProjectorSupervisor(log, es, rdClient, rdLocker)
-	supervisors := projector.Supervisors{Postgres: ps, Redis: rds}
-	if err := supervisors.Register(ctx, relationStore, rdClient); err != nil {
-		return fmt.Errorf("failed to register and init projectors: %v", err)
-	}
+	cmds := commands.NewCommands(log, es, eventCrypto, authorizer, readModels, repos, supervisors, commands.WithRetry(commands.RetryConfig{
+		Attempts: c.Commands.RetryAttempts,
+		Jitter:   c.Commands.RetryJitter,
+	}))
+	broadcaster := eventing.NewEventBroadcaster(projector.Interests(
+		projector.ProjectionAccountName,
+		projector.ProjectionPersonName,
+		projector.ProjectionTeamName,
+		projector.ProjectionTrainingName,
+	)...)
+	qs := queries.NewQueries(log, es, authorizer, readModels, repos, supervisors,
+		queries.WithConsistencyTimeout(c.Queries.ConsistencyTimeout),
+		queries.WithEventBroadcaster(broadcaster),
+		queries.WithWatchRefreshInterval(c.Queries.WatchRefreshInterval),
+	)
 	supervisors.Enable()
+	metrics, err := supervisors.RegisterMetrics()
+	if err != nil {
+		return fmt.Errorf("failed to register projection metrics: %w", err)
+	}
+	defer metrics.Unregister()
+	if supervisors.Redis != nil {
+		// Activate the projections or start building their new versions without waiting for the first polling run.
+		go supervisors.Redis.Trigger(ctx)
+	}
+	outbox.Register(projector.NewPermissionTrigger(ps))
+	go func() {
+		if err := outbox.Run(ctx); err != nil {
+			log.Error("Outbox dispatcher failed", "error", err)
+		}
+	}()
 
 	pgEn := pgeventing.NewEventNotifier(log, pool)
 	pgEn.AddListener(ps)
-	pgEn.AddListener(rds)
+	if supervisors.Redis != nil {
+		pgEn.AddListener(supervisors.Redis)
+	}
+	// Notify watching clients last, so that the projections have been advanced already.
+	pgEn.AddListener(broadcaster)
 	go func() {
 		// Delay starting of the event listener to allow for initial triggers to not compete directly.
 		// This is not strictly necessary, but will ease the startup a bit.
@@ -172,15 +220,16 @@ func run(ctx context.Context, c *config.Config, log *slog.Logger) (err error) {
 			case <-timer.C:
 				log.Debug("Triggering projection polling run.")
 				var wg sync.WaitGroup
-				wg.Add(2)
-				go func() {
-					defer wg.Done()
-					rds.Trigger(ctx)
-				}()
-				go func() {
-					defer wg.Done()
-					ps.Trigger(ctx)
-				}()
+				for _, supervisor := range []eventing.ProjectorSupervisor{supervisors.Redis, ps} {
+					if supervisor == nil {
+						continue
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						supervisor.Trigger(ctx)
+					}()
+				}
 				wg.Wait()
 
 				timer.Reset(interval)
@@ -188,13 +237,20 @@ func run(ctx context.Context, c *config.Config, log *slog.Logger) (err error) {
 		}
 	}()
 
-	// On every persisted event, we want to trigger the account permission projector.
-	// Here, we can't really deal with eventual consistency.
+	// On every persisted event, we want to advance the account permission projection.
+	// Here, we can't really deal with eventual consistency, so the projection is advanced right away,
+	// even if the outbox dispatcher currently holds the lease of its entries.
+	// The outbox entries remain the durable retry path and are delivered by the dispatcher afterward.
 	// The projector will wait for any locks on the projection to avoid race conditions when any
 	// NOTIFY triggered projector is faster.
 	es.AddHook(eventing.NewPostPersistHook(func(ctx context.Context) error {
-		ps.Trigger(ctx, projector.PermissionProjectorName)
-		return nil
+		defer outbox.Trigger()
+		err := ps.Advance(ctx, projector.PermissionProjectorName)
+		if errors.Is(err, eventing.ErrProjectionPaused) {
+			// Resuming the projection catches up on the event.
+			return nil
+		}
+		return err
 	}))
 
 	// Create root account if it doesn't exist.
@@ -213,6 +269,7 @@ func run(ctx context.Context, c *config.Config, log *slog.Logger) (err error) {
 	if err := grpcServer.Register(mux); err != nil {
 		return err
 	}
+	mux.Handle("GET /health/projections", projector.NewHealthHandler(log, supervisors, pgEn, c.Projection.MaxLag))
 	rootHandler := h2c.NewHandler(mux, &http2.Server{})
 
 	srv := &http.Server{
@@ -239,6 +296,94 @@ func run(ctx context.Context, c *config.Config, log *slog.Logger) (err error) {
 	return
 }
 
+// setupPool connects to postgres and migrates the database.
+func setupPool(ctx context.Context, c *config.Config) (*pgxpool.Pool, error) {
+	dbconfig, err := pgxpool.ParseConfig(c.EventJournal.PG.ConnStr())
+	if err != nil {
+		return nil, err
+	}
+	dbconfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, "SET application_name = 'soccerbuddy'")
+		if err != nil {
+			return err
+		}
+		pgxdecimal.Register(conn.TypeMap())
+		return err
+	}
+	dbconfig.ConnConfig.Tracer = otelpgx.NewTracer()
+	pool, err := pgxpool.NewWithConfig(ctx, dbconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pg pool: %w", err)
+	}
+	err = otelpgx.RecordStats(pool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record postgres OTel stats: %w", err)
+	}
+	err = pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) error {
+		return runMigration(ctx, conn.Conn())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return pool, nil
+}
+
+// setupRedis creates the redis client and the locker of the projections.
+func setupRedis(c *config.Config) (rueidis.Client, rueidislock.Locker, error) {
+	rdOpts := rueidis.ClientOption{
+		InitAddress: []string{c.EventJournal.Redis.Host},
+	}
+	rdClient, err := rueidisotel.NewClient(rdOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create redis client: %w", err)
+	}
+	rdLocker, err := rueidislock.NewLocker(rueidislock.LockerOption{
+		ClientOption:   rdOpts,
+		KeyMajority:    1,
+		NoLoopTracking: true,
+	})
+	if err != nil {
+		rdClient.Close()
+		return nil, nil, fmt.Errorf("failed to create redis locker: %w", err)
+	}
+	return rdClient, rdLocker, nil
+}
+
+// setupEventCrypto creates the event crypto and the hasher of sensitive lookups.
+func setupEventCrypto(ctx context.Context, c *config.Config, log *slog.Logger, pool *pgxpool.Pool) (eventing.EventCrypto, eventing.LookupHasher, error) {
+	keyring, err := setupKeyring(c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to setup keyring: %w", err)
+	}
+	// Data keys might still be stored in plaintext, which are rejected on use.
+	wrapped, err := pgeventing.WrapKeys(ctx, pool, keyring)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to wrap data keys: %w", err)
+	}
+	if wrapped > 0 {
+		log.Warn("Wrapped data keys that were stored in plaintext", slog.Int("count", wrapped), slog.String("key_id", string(keyring.ActiveKeyID())))
+	}
+	lookupKey, err := c.EventJournal.Encryption.LoadLookupKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	lookupHasher := eventing.NewHMACLookupHasher(lookupKey)
+	return pgeventing.NewEventCrypto(pool, keyring, lookupHasher), lookupHasher, nil
+}
+
+// setupKeyring loads the key-encryption keys of the event journal.
+func setupKeyring(c *config.Config) (*pgeventing.Keyring, error) {
+	keys, err := c.EventJournal.Encryption.LoadKeys()
+	if err != nil {
+		return nil, err
+	}
+	var keks []pgeventing.KeyEncryptionKey
+	for id, key := range keys {
+		keks = append(keks, pgeventing.KeyEncryptionKey{ID: pgeventing.KeyID(id), Key: key})
+	}
+	return pgeventing.NewKeyring(pgeventing.KeyID(c.EventJournal.Encryption.ActiveKeyID), keks...)
+}
+
 func runMigration(ctx context.Context, conn *pgx.Conn) error {
 	migrator, err := migrate.NewMigrator(ctx, conn, "public.schema_version")
 	if err != nil {
@@ -252,6 +397,23 @@ func runMigration(ctx context.Context, conn *pgx.Conn) error {
 	return migrator.Migrate(ctx)
 }
 
+// setupAuthz sets up the authorizer and the relation store of the configured engine.
+func setupAuthz(ctx context.Context, c *config.Config, log *slog.Logger, pool *pgxpool.Pool) (authz.Authorizer, authz.RelationStore, authz.RelationReader, error) {
+	if c.Authz.Engine == config.AuthzEngineEmbedded {
+		schema, err := pgauthz.LoadSchema(c.Authz.SchemaFile)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load authz schema: %w", err)
+		}
+		store := pgauthz.NewRelationStore(log, pool, schema)
+		return pgauthz.NewAuthorizer(log, store), store, store, nil
+	}
+	client, err := setupPermifyClient(ctx, c)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to setup permify client: %w", err)
+	}
+	return permify.NewAuthorizer(log, client, permifyTenantID), permify.NewRelationStore(log, client), permify.NewRelationReader(log, client), nil
+}
+
 func setupPermifyClient(ctx context.Context, c *config.Config) (*permify_grpc.Client, error) {
 	client, err := permify_grpc.NewClient(
 		permify_grpc.Config{
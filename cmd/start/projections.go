@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/gen/eventregistry"
+	"github.com/rsmidt/soccerbuddy/internal/config"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	pgeventing "github.com/rsmidt/soccerbuddy/internal/postgres/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/projector"
+	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// resetProjection drops the projection given as argument and replays it from the start of the journal.
+// Running servers keep serving the projection while it is rebuilt, so queries might see partial results.
+func resetProjection(ctx context.Context, c *config.Config, log *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	if len(os.Args) < 3 || os.Args[2] == "" {
+		return errors.New("usage: reset-projection <projection>")
+	}
+	projection := eventing.ProjectionName(os.Args[2])
+
+	return withSupervisors(ctx, c, log, func(supervisors *projector.Supervisors) error {
+		if err := supervisors.Reset(ctx, projection); err != nil {
+			return fmt.Errorf("failed to reset projection %s: %w", projection, err)
+		}
+		log.Info("Reset projection, replaying", slog.String("projection", string(projection)))
+		err := supervisors.TriggerProjection(ctx, projection)
+		if errors.Is(err, eventing.ErrProjectionPaused) {
+			log.Info("Projection is paused, it is replayed once resumed", slog.String("projection", string(projection)))
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to replay projection %s: %w", projection, err)
+		}
+		log.Info("Replayed projection", slog.String("projection", string(projection)))
+		return nil
+	})
+}
+
+// listDeadLetters prints the failed events of the projection given as argument, or of all projections.
+func listDeadLetters(ctx context.Context, c *config.Config, log *slog.Logger) error {
+	var projection eventing.ProjectionName
+	if len(os.Args) > 2 {
+		projection = eventing.ProjectionName(os.Args[2])
+	}
+
+	pool, err := setupPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	failures, err := pgeventing.NewProjectionFailureStore(pool).List(ctx, projection)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECTION\tEVENT ID\tEVENT TYPE\tAGGREGATE\tSTATE\tATTEMPTS\tFAILED AT\tERROR")
+	for _, f := range failures {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\t%d\t%s\t%s\n",
+			f.Projection, f.EventID, f.EventType, f.AggregateType, f.AggregateID, f.State, f.Attempts, f.UpdatedAt.Format(time.RFC3339), f.LastError)
+	}
+	return w.Flush()
+}
+
+// redriveDeadLetter projects a failed event of a projection once more, e.g. after the projector has been fixed.
+func redriveDeadLetter(ctx context.Context, c *config.Config, log *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	if len(os.Args) < 4 || os.Args[2] == "" || os.Args[3] == "" {
+		return errors.New("usage: redrive-dead-letter <projection> <event-id>")
+	}
+	projection := eventing.ProjectionName(os.Args[2])
+	eventID := eventing.EventID(os.Args[3])
+
+	return withSupervisors(ctx, c, log, func(supervisors *projector.Supervisors) error {
+		if err := supervisors.Redrive(ctx, projection, eventID); err != nil {
+			return fmt.Errorf("failed to redrive event %s of projection %s: %w", eventID, projection, err)
+		}
+		log.Info("Re-drove event", slog.String("projection", string(projection)), slog.String("event_id", string(eventID)))
+		return nil
+	})
+}
+
+// reconcilePermissions compares the stored relations with the ones implied by the journal and repairs the drift.
+// With --dry-run, the differences are only printed.
+func reconcilePermissions(ctx context.Context, c *config.Config, log *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	dryRun := slices.Contains(os.Args[2:], "--dry-run")
+
+	return withProjectionEnv(ctx, c, log, func(env *projectionEnv) error {
+		reconciler := projector.NewPermissionReconciler(log, env.es, env.supervisors, env.relationStore, env.relationReader)
+		diff, err := reconciler.Reconcile(ctx, dryRun)
+		if err != nil {
+			return fmt.Errorf("failed to reconcile permissions: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DRIFT\tRELATION")
+		for _, relation := range diff.Missing {
+			fmt.Fprintf(w, "missing\t%s\n", relation)
+		}
+		for _, relation := range diff.Stale {
+			fmt.Fprintf(w, "stale\t%s\n", relation)
+		}
+		return w.Flush()
+	})
+}
+
+// withSupervisors sets up the supervisors with all projectors registered for a one-off command.
+func withSupervisors(ctx context.Context, c *config.Config, log *slog.Logger, fn func(supervisors *projector.Supervisors) error) error {
+	return withProjectionEnv(ctx, c, log, func(env *projectionEnv) error {
+		return fn(env.supervisors)
+	})
+}
+
+// projectionEnv are the dependencies of the projections set up for a one-off command.
+type projectionEnv struct {
+	es             eventing.EventStore
+	supervisors    *projector.Supervisors
+	relationStore  authz.RelationStore
+	relationReader authz.RelationReader
+}
+
+func withProjectionEnv(ctx context.Context, c *config.Config, log *slog.Logger, fn func(env *projectionEnv) error) error {
+	pool, err := setupPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	_, relationStore, relationReader, err := setupAuthz(ctx, c, log, pool)
+	if err != nil {
+		return err
+	}
+	eventCrypto, _, err := setupEventCrypto(ctx, c, log, pool)
+	if err != nil {
+		return err
+	}
+	es := pgeventing.NewEventStore(log, pool, eventregistry.Default, eventCrypto)
+
+	failures := pgeventing.NewProjectionFailureStore(pool)
+	supervisors := &projector.Supervisors{
+		Postgres: pgeventing.NewProjectorSupervisor(log, pool, es, failures),
+		Failures: failures,
+	}
+	readModelProjectors := projector.NewPostgresReadModelProjectors()
+	if c.Projection.ReadModels == config.ReadModelsRedis {
+		rdClient, rdLocker, err := setupRedis(c)
+		if err != nil {
+			return err
+		}
+		defer rdClient.Close()
+		defer rdLocker.Close()
+		supervisors.Redis = rdeventing.NewProjectorSupervisor(log, es, failures, rdClient, rdLocker)
+		readModelProjectors = projector.NewRedisReadModelProjectors(rdClient)
+	}
+	if err := supervisors.Register(ctx, relationStore, readModelProjectors); err != nil {
+		return fmt.Errorf("failed to register and init projectors: %w", err)
+	}
+	return fn(&projectionEnv{es: es, supervisors: supervisors, relationStore: relationStore, relationReader: relationReader})
+}
@@ -195,17 +195,16 @@ func (c *Commands) AttachMobileDevice(ctx context.Context, cmd *AttachMobileDevi
 		return err
 	}
 
-	account, err := c.repos.Account().FindByID(ctx, principal.AccountID)
-	if err != nil {
-		return err
-	}
-	if err := account.AttachMobileDevice(cmd.InstallationID, cmd.NotificationDeviceToken); err != nil {
-		return err
-	}
-	if err := c.repos.Account().Save(ctx, account); err != nil {
-		return err
-	}
-	return nil
+	return c.retryOnConflict(ctx, func(ctx context.Context) error {
+		account, err := c.repos.Account().FindByID(ctx, principal.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := account.AttachMobileDevice(cmd.InstallationID, cmd.NotificationDeviceToken); err != nil {
+			return err
+		}
+		return c.repos.Account().Save(ctx, account)
+	})
 }
 
 func generateSessionToken() (domain.SessionToken, error) {
@@ -92,15 +92,14 @@ func (c *Commands) PromoteUserToClubAdmin(ctx context.Context, cmd *PromoteUserT
 		return err
 	}
 
-	club, err := c.repos.Club().FindByID(ctx, cmd.ClubID)
-	if err != nil {
-		return err
-	}
-	if err := club.AddAdmin(cmd.UserID, time.Now(), operator); err != nil {
-		return err
-	}
-	if err := c.repos.Club().Save(ctx, club); err != nil {
-		return err
-	}
-	return nil
+	return c.retryOnConflict(ctx, func(ctx context.Context) error {
+		club, err := c.repos.Club().FindByID(ctx, cmd.ClubID)
+		if err != nil {
+			return err
+		}
+		if err := club.AddAdmin(cmd.UserID, time.Now(), operator); err != nil {
+			return err
+		}
+		return c.repos.Club().Save(ctx, club)
+	})
 }
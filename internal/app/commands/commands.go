@@ -1,27 +1,37 @@
 package commands
 
 import (
-	"github.com/redis/rueidis"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/projector"
 	"log/slog"
 )
 
 type Commands struct {
 	log        *slog.Logger
 	es         eventing.EventStore
+	keys       eventing.KeyShredder
 	authorizer authz.Authorizer
-	rd         rueidis.Client
+	readModels projector.ReadModels
 	repos      domain.Repositories
+	projectors *projector.Supervisors
+	retry      RetryConfig
 }
 
 func NewCommands(
 	log *slog.Logger,
 	es eventing.EventStore,
+	keys eventing.KeyShredder,
 	authorizer authz.Authorizer,
-	rd rueidis.Client,
+	readModels projector.ReadModels,
 	repos domain.Repositories,
+	projectors *projector.Supervisors,
+	opts ...CommandsOpt,
 ) *Commands {
-	return &Commands{log: log, es: es, authorizer: authorizer, rd: rd, repos: repos}
+	c := &Commands{log: log, es: es, keys: keys, authorizer: authorizer, readModels: readModels, repos: repos, projectors: projectors, retry: DefaultRetryConfig}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
@@ -3,13 +3,12 @@ package commands
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/rsmidt/soccerbuddy/internal/app/validation"
 	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/projector"
-	"github.com/rsmidt/soccerbuddy/internal/redis"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
 	"time"
 )
@@ -124,20 +123,19 @@ func (c *Commands) AddPersonToTeam(ctx context.Context, cmd AddPersonToTeamComma
 	} else if !exists {
 		return domain.ErrPersonNotFound
 	}
-	member, err := c.repos.TeamMember().FindByTeamAndPerson(ctx, cmd.TeamID, cmd.PersonID)
-	if errors.Is(err, domain.ErrTeamMemberNotFound) {
-		// If the team member does not exist, create a new one.
-		member = domain.NewTeamMember(idgen.New[domain.TeamMemberID](), cmd.TeamID, cmd.PersonID)
-	} else if err != nil {
-		return err
-	}
-	if err := member.Invite(operator, cmd.Role); err != nil {
-		return err
-	}
-	if err := c.repos.TeamMember().Save(ctx, member); err != nil {
-		return err
-	}
-	return nil
+	return c.retryOnConflict(ctx, func(ctx context.Context) error {
+		member, err := c.repos.TeamMember().FindByTeamAndPerson(ctx, cmd.TeamID, cmd.PersonID)
+		if errors.Is(err, domain.ErrTeamMemberNotFound) {
+			// If the team member does not exist, create a new one.
+			member = domain.NewTeamMember(idgen.New[domain.TeamMemberID](), cmd.TeamID, cmd.PersonID)
+		} else if err != nil {
+			return err
+		}
+		if err := member.Invite(operator, cmd.Role); err != nil {
+			return err
+		}
+		return c.repos.TeamMember().Save(ctx, member)
+	})
 }
 
 type InitiatePersonAccountLinkCommand struct {
@@ -179,21 +177,24 @@ func (c *Commands) InitiatePersonAccountLink(ctx context.Context, command Initia
 	if err := command.Validate(); err != nil {
 		return nil, err
 	}
-	// If the desired role is self, we need to verify that there does not yet exist a link for it.
-	person, err := c.repos.Person().FindByID(ctx, command.PersonID)
-	if err != nil {
-		return nil, err
-	}
 	expiresAt := time.Now().Add(time.Hour * 24 * 7)
 	token, err := randomString(16)
 	if err != nil {
 		return nil, err
 	}
 	linkToken := domain.PersonLinkToken(token)
-	if err := person.InitiateNewLink(operator, command.LinkAs, linkToken, expiresAt); err != nil {
-		return nil, err
-	}
-	if err := c.repos.Person().Save(ctx, person); err != nil {
+	err = c.retryOnConflict(ctx, func(ctx context.Context) error {
+		// If the desired role is self, we need to verify that there does not yet exist a link for it.
+		person, err := c.repos.Person().FindByID(ctx, command.PersonID)
+		if err != nil {
+			return err
+		}
+		if err := person.InitiateNewLink(operator, command.LinkAs, linkToken, expiresAt); err != nil {
+			return err
+		}
+		return c.repos.Person().Save(ctx, person)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &InitiatePersonAccountLinkResult{
@@ -235,45 +236,84 @@ func (c *Commands) ClaimPersonLink(ctx context.Context, cmd ClaimPersonLinkComma
 	}
 	persProjection := pers[0]
 
-	// Claim the token on the person side.
-	person, err := c.repos.Person().FindByID(ctx, persProjection.ID)
-	if err != nil {
-		return err
+	return c.retryOnConflict(ctx, func(ctx context.Context) error {
+		// Claim the token on the person side.
+		person, err := c.repos.Person().FindByID(ctx, persProjection.ID)
+		if err != nil {
+			return err
+		}
+		if err := person.Claim(cmd.LinkToken, principal.AccountID); err != nil {
+			return err
+		}
+		pl, err := person.FindPendingLink(cmd.LinkToken)
+		if err != nil {
+			return err
+		}
+
+		// Make the link on the account side.
+		account, err := c.repos.Account().FindByID(ctx, principal.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := account.Link(person.ID, pl.LinkAs, nil, persProjection.OwningClubID, &cmd.LinkToken); err != nil {
+			return err
+		}
+
+		// Save both aggregates in the same transaction.
+		return c.es.ProduceAppend(ctx, person, account)
+	})
+}
+
+type ShredPersonCommand struct {
+	PersonID domain.PersonID
+}
+
+func (c *ShredPersonCommand) Validate() error {
+	var errs validation.Errors
+	if c.PersonID == "" {
+		errs = append(errs, validation.NewFieldError("person_id", validation.ErrRequired))
 	}
-	if err := person.Claim(cmd.LinkToken, principal.AccountID); err != nil {
-		return err
+	if len(errs) > 0 {
+		return errs
 	}
-	pl, err := person.FindPendingLink(cmd.LinkToken)
-	if err != nil {
+	return nil
+}
+
+func (c *Commands) ShredPerson(ctx context.Context, cmd ShredPersonCommand) error {
+	ctx, span := tracing.Tracer.Start(ctx, "commands.ShredPerson")
+	defer span.End()
+
+	if err := c.authorizer.Authorize(ctx, authz.ActionPersonShred, authz.NewPersonResource(cmd.PersonID)); err != nil {
 		return err
 	}
-
-	// Make the link on the account side.
-	account, err := c.repos.Account().FindByID(ctx, principal.AccountID)
+	operator, err := c.authorizer.OptionalActingOperator(ctx, nil)
 	if err != nil {
 		return err
 	}
-	if err := account.Link(person.ID, pl.LinkAs, nil, persProjection.OwningClubID, &cmd.LinkToken); err != nil {
+	if err := cmd.Validate(); err != nil {
 		return err
 	}
 
-	// Save both aggregates.
-	if err := c.repos.Person().Save(ctx, person); err != nil {
-		return err
-	}
-	// TODO: Emit a compensation event on person if the append fails or append both in the same transaction (basically we need a saga...).
-	if err := c.repos.Account().Save(ctx, account); err != nil {
-		return err
-	}
-	return nil
+	return c.retryOnConflict(ctx, func(ctx context.Context) error {
+		person, err := c.repos.Person().FindByID(ctx, cmd.PersonID)
+		if err != nil {
+			return err
+		}
+		if person.State == domain.PersonStateUnspecified {
+			return domain.ErrPersonNotFound
+		}
+
+		// Delete the key first. Should appending the event fail, the data is gone nevertheless and the command can be retried.
+		if err := c.keys.ShredKeys(ctx, eventing.AggregateID(person.ID)); err != nil {
+			return err
+		}
+		if err := person.Shred(operator); err != nil {
+			return err
+		}
+		return c.repos.Person().Save(ctx, person)
+	})
 }
 
 func (c *Commands) getPersonProjectionByPendingToken(ctx context.Context, token domain.PersonLinkToken) ([]*projector.PersonProjection, error) {
-	rdq := fmt.Sprintf("@pending_link_token:{%s}", token)
-	cmd := c.rd.B().FtSearch().Index(projector.ProjectionPersonIDXName).Query(rdq).Dialect(4).Build()
-	_, docs, err := c.rd.Do(ctx, cmd).AsFtSearch()
-	if err != nil {
-		return nil, err
-	}
-	return redis.UnmarshalDocs[projector.PersonProjection](docs)
+	return c.readModels.PersonsByPendingLinkToken(ctx, token)
 }
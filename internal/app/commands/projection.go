@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"github.com/rsmidt/soccerbuddy/internal/app/validation"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"log/slog"
+)
+
+type ResetProjectionCommand struct {
+	Projection eventing.ProjectionName
+}
+
+func (c *ResetProjectionCommand) Validate() error {
+	var errs validation.Errors
+	if c.Projection == "" {
+		errs = append(errs, validation.NewFieldError("projection", validation.ErrRequired))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ResetProjection drops the projection and replays it from the start of the journal.
+// Returns once the projection has been dropped, the replay continues in the background.
+func (c *Commands) ResetProjection(ctx context.Context, cmd ResetProjectionCommand) error {
+	ctx, span := tracing.Tracer.Start(ctx, "commands.ResetProjection")
+	defer span.End()
+
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	if err := c.authorizer.Authorize(ctx, authz.ActionManageProjections, authz.SystemResource); err != nil {
+		return err
+	}
+	if err := c.projectors.Reset(ctx, cmd.Projection); err != nil {
+		return err
+	}
+	// Replaying the whole journal outlasts any request, so it must not be cancelled along with it.
+	go func() {
+		err := c.projectors.TriggerProjection(context.WithoutCancel(ctx), cmd.Projection)
+		if errors.Is(err, eventing.ErrProjectionPaused) {
+			c.log.Info("Projection is paused, replaying it once resumed", slog.String("projection", string(cmd.Projection)))
+		} else if err != nil {
+			c.log.Error("Failed to replay projection", slog.String("projection", string(cmd.Projection)), slog.Any("error", err))
+		}
+	}()
+	return nil
+}
+
+type RedriveDeadLetterCommand struct {
+	Projection eventing.ProjectionName
+	EventID    eventing.EventID
+}
+
+func (c *RedriveDeadLetterCommand) Validate() error {
+	var errs validation.Errors
+	if c.Projection == "" {
+		errs = append(errs, validation.NewFieldError("projection", validation.ErrRequired))
+	}
+	if c.EventID == "" {
+		errs = append(errs, validation.NewFieldError("event_id", validation.ErrRequired))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// RedriveDeadLetter projects a failed event once more and advances the projection afterward.
+func (c *Commands) RedriveDeadLetter(ctx context.Context, cmd RedriveDeadLetterCommand) error {
+	ctx, span := tracing.Tracer.Start(ctx, "commands.RedriveDeadLetter")
+	defer span.End()
+
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	if err := c.authorizer.Authorize(ctx, authz.ActionManageProjections, authz.SystemResource); err != nil {
+		return err
+	}
+	return c.projectors.Redrive(ctx, cmd.Projection, cmd.EventID)
+}
+
+type TriggerProjectionCommand struct {
+	Projection eventing.ProjectionName
+}
+
+func (c *TriggerProjectionCommand) Validate() error {
+	var errs validation.Errors
+	if c.Projection == "" {
+		errs = append(errs, validation.NewFieldError("projection", validation.ErrRequired))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// TriggerProjection advances the projection without waiting for the next notification or polling run.
+// Returns once the projection has caught up. Returns [eventing.ErrProjectionPaused] if the projection is paused.
+func (c *Commands) TriggerProjection(ctx context.Context, cmd TriggerProjectionCommand) error {
+	ctx, span := tracing.Tracer.Start(ctx, "commands.TriggerProjection")
+	defer span.End()
+
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	if err := c.authorizer.Authorize(ctx, authz.ActionManageProjections, authz.SystemResource); err != nil {
+		return err
+	}
+	return c.projectors.TriggerProjection(ctx, cmd.Projection)
+}
+
+type PauseProjectionCommand struct {
+	Projection eventing.ProjectionName
+}
+
+func (c *PauseProjectionCommand) Validate() error {
+	var errs validation.Errors
+	if c.Projection == "" {
+		errs = append(errs, validation.NewFieldError("projection", validation.ErrRequired))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// PauseProjection stops advancing the projection on all instances until it is resumed.
+// Queries keep reading the projection as it was when it has been paused.
+func (c *Commands) PauseProjection(ctx context.Context, cmd PauseProjectionCommand) error {
+	ctx, span := tracing.Tracer.Start(ctx, "commands.PauseProjection")
+	defer span.End()
+
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	if err := c.authorizer.Authorize(ctx, authz.ActionManageProjections, authz.SystemResource); err != nil {
+		return err
+	}
+	return c.projectors.Pause(ctx, cmd.Projection)
+}
+
+type ResumeProjectionCommand struct {
+	Projection eventing.ProjectionName
+}
+
+func (c *ResumeProjectionCommand) Validate() error {
+	var errs validation.Errors
+	if c.Projection == "" {
+		errs = append(errs, validation.NewFieldError("projection", validation.ErrRequired))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ResumeProjection resumes a paused projection. Returns once the projection has caught up.
+func (c *Commands) ResumeProjection(ctx context.Context, cmd ResumeProjectionCommand) error {
+	ctx, span := tracing.Tracer.Start(ctx, "commands.ResumeProjection")
+	defer span.End()
+
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	if err := c.authorizer.Authorize(ctx, authz.ActionManageProjections, authz.SystemResource); err != nil {
+		return err
+	}
+	return c.projectors.Resume(ctx, cmd.Projection)
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+)
+
+// RetryConfig configures how commands are retried on version conflicts.
+type RetryConfig struct {
+	// Attempts is the maximum number of times a command is run, including the first one.
+	Attempts int
+	// Jitter is the upper bound of the random delay before each retry.
+	// Spreading retries apart keeps concurrent commands from conflicting again.
+	Jitter time.Duration
+}
+
+var DefaultRetryConfig = RetryConfig{
+	Attempts: 3,
+	Jitter:   50 * time.Millisecond,
+}
+
+type CommandsOpt func(c *Commands)
+
+// WithRetry overrides the DefaultRetryConfig.
+// Fields left at their zero value keep the default.
+func WithRetry(config RetryConfig) CommandsOpt {
+	return func(c *Commands) {
+		if config.Attempts != 0 {
+			c.retry.Attempts = config.Attempts
+		}
+		if config.Jitter != 0 {
+			c.retry.Jitter = config.Jitter
+		}
+	}
+}
+
+// isConflict reports whether err is caused by a concurrent modification of the same aggregate.
+func isConflict(err error) bool {
+	return errors.Is(err, eventing.ErrVersionMismatch) || errors.Is(err, eventing.ErrIntentOutdated)
+}
+
+// retryOnConflict runs fn until it no longer fails with a version conflict or the attempts are exhausted.
+// fn must reload the aggregates it modifies, so that the domain method is re-run on the latest state.
+func (c *Commands) retryOnConflict(ctx context.Context, fn func(ctx context.Context) error) error {
+	attempts := max(c.retry.Attempts, 1)
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || !isConflict(err) {
+			return err
+		}
+		if attempt >= attempts {
+			break
+		}
+		c.log.Debug("Retrying command after version conflict", slog.Int("attempt", attempt), slog.Any("error", err))
+		if c.retry.Jitter > 0 {
+			timer := time.NewTimer(rand.N(c.retry.Jitter))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+	return fmt.Errorf("conflict persisted after %d attempts: %w", attempts, err)
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+)
+
+func TestCommands_retryOnConflict(t *testing.T) {
+	c := &Commands{log: slog.New(slog.NewTextHandler(io.Discard, nil)), retry: RetryConfig{Attempts: 3}}
+	ctx := context.Background()
+
+	t.Run("resolved conflict", func(t *testing.T) {
+		var runs int
+		err := c.retryOnConflict(ctx, func(ctx context.Context) error {
+			runs++
+			if runs < 3 {
+				return eventing.ErrVersionMismatch
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if runs != 3 {
+			t.Errorf("expected 3 runs, got %d", runs)
+		}
+	})
+
+	t.Run("persisting conflict", func(t *testing.T) {
+		var runs int
+		err := c.retryOnConflict(ctx, func(ctx context.Context) error {
+			runs++
+			return eventing.ErrIntentOutdated
+		})
+		if !errors.Is(err, eventing.ErrIntentOutdated) {
+			t.Errorf("expected %v, got %v", eventing.ErrIntentOutdated, err)
+		}
+		if runs != 3 {
+			t.Errorf("expected 3 runs, got %d", runs)
+		}
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		var runs int
+		other := errors.New("other")
+		err := c.retryOnConflict(ctx, func(ctx context.Context) error {
+			runs++
+			return other
+		})
+		if !errors.Is(err, other) {
+			t.Errorf("expected %v, got %v", other, err)
+		}
+		if runs != 1 {
+			t.Errorf("expected 1 run, got %d", runs)
+		}
+	})
+}
@@ -92,12 +92,14 @@ func (c *Commands) DeleteTeam(ctx context.Context, cmd DeleteTeamCommand) error
 	if err != nil {
 		return err
 	}
-	team, err := c.repos.Team().FindByID(ctx, cmd.ID)
-	if err != nil {
-		return err
-	}
-	if err := team.Delete(operator); err != nil {
-		return err
-	}
-	return c.repos.Team().Save(ctx, team)
+	return c.retryOnConflict(ctx, func(ctx context.Context) error {
+		team, err := c.repos.Team().FindByID(ctx, cmd.ID)
+		if err != nil {
+			return err
+		}
+		if err := team.Delete(operator); err != nil {
+			return err
+		}
+		return c.repos.Team().Save(ctx, team)
+	})
 }
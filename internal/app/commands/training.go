@@ -152,14 +152,17 @@ func (c *Commands) ScheduleTraining(ctx context.Context, cmd *ScheduleTrainingCo
 
 	if cmd.Nominations != nil {
 		// TODO: Replace with a single operation.
-		training, err = c.repos.Training().FindByID(ctx, training.ID)
-		if err != nil {
-			return err
-		}
-		if err := training.NominatePersons(cmd.Nominations.PlayerIDs, cmd.Nominations.StaffIDs, operator, cmd.Nominations.NotificationPolicy); err != nil {
-			return err
-		}
-		return c.repos.Training().Save(ctx, training)
+		trainingID := training.ID
+		return c.retryOnConflict(ctx, func(ctx context.Context) error {
+			training, err := c.repos.Training().FindByID(ctx, trainingID)
+			if err != nil {
+				return err
+			}
+			if err := training.NominatePersons(cmd.Nominations.PlayerIDs, cmd.Nominations.StaffIDs, operator, cmd.Nominations.NotificationPolicy); err != nil {
+				return err
+			}
+			return c.repos.Training().Save(ctx, training)
+		})
 	}
 	return nil
 }
@@ -199,12 +202,14 @@ func (c *Commands) NominatePersonsForTraining(ctx context.Context, cmd *Nominate
 		return err
 	}
 
-	training, err := c.repos.Training().FindByID(ctx, cmd.TrainingID)
-	if err != nil {
-		return err
-	}
-	if err := training.NominatePersons(cmd.PlayerIDs, cmd.StaffIDs, operator, domain.TrainingNominationNotificationPolicySilent); err != nil {
-		return err
-	}
-	return c.repos.Training().Save(ctx, training)
+	return c.retryOnConflict(ctx, func(ctx context.Context) error {
+		training, err := c.repos.Training().FindByID(ctx, cmd.TrainingID)
+		if err != nil {
+			return err
+		}
+		if err := training.NominatePersons(cmd.PlayerIDs, cmd.StaffIDs, operator, domain.TrainingNominationNotificationPolicySilent); err != nil {
+			return err
+		}
+		return c.repos.Training().Save(ctx, training)
+	})
 }
@@ -6,7 +6,6 @@ import (
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
 	"github.com/rsmidt/soccerbuddy/internal/projector"
-	"github.com/rsmidt/soccerbuddy/internal/redis"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
 	"log/slog"
 	"time"
@@ -36,15 +35,19 @@ func (q *Queries) ListPersonsInClub(ctx context.Context, query ListPersonsInClub
 		return nil, err
 	}
 
-	rdq := fmt.Sprintf("@owning_club_id:{%s}", query.OwningClubID)
-	cmd := q.rd.B().FtSearch().Index(projector.ProjectionPersonIDXName).Query(rdq).Dialect(4).Build()
-	_, docs, err := q.rd.Do(ctx, cmd).AsFtSearch()
+	q.awaitProjection(ctx, projector.ProjectionPersonName)
+	projections, err := q.readModels.PersonsInClub(ctx, query.OwningClubID)
 	if err != nil {
 		return nil, err
 	}
-	persons, err := redis.UnmarshalDocs[personInClubView](docs)
-	if err != nil {
-		return nil, err
+	persons := make([]*personInClubView, len(projections))
+	for i, p := range projections {
+		persons[i] = &personInClubView{
+			ID:        p.ID,
+			FirstName: p.FirstName,
+			LastName:  p.LastName,
+			Birthdate: p.BirthDate,
+		}
 	}
 	return &PersonsInClubView{Persons: persons}, nil
 }
@@ -0,0 +1,44 @@
+package queries
+
+import (
+	"cmp"
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"slices"
+)
+
+type ListDeadLettersQuery struct {
+	// Projection restricts the list to a single projection, if set.
+	Projection eventing.ProjectionName
+}
+
+// ListDeadLetters lists the events the projectors failed on, including those that are still retried.
+func (q *Queries) ListDeadLetters(ctx context.Context, query ListDeadLettersQuery) ([]*eventing.ProjectionFailure, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "queries.ListDeadLetters")
+	defer span.End()
+
+	if err := q.authorizer.Authorize(ctx, authz.ActionManageProjections, authz.SystemResource); err != nil {
+		return nil, err
+	}
+	return q.projectors.Failures.List(ctx, query.Projection)
+}
+
+// ListProjections lists the projections of all supervisors with their progress, ordered by name.
+func (q *Queries) ListProjections(ctx context.Context) ([]eventing.ProjectionStatus, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "queries.ListProjections")
+	defer span.End()
+
+	if err := q.authorizer.Authorize(ctx, authz.ActionManageProjections, authz.SystemResource); err != nil {
+		return nil, err
+	}
+	statuses, err := q.projectors.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(statuses, func(a, b eventing.ProjectionStatus) int {
+		return cmp.Compare(a.State.Name, b.State.Name)
+	})
+	return statuses, nil
+}
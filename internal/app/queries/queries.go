@@ -2,15 +2,11 @@ package queries
 
 import (
 	"context"
-	"fmt"
-	"github.com/redis/rueidis"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/projector"
-	"github.com/rsmidt/soccerbuddy/internal/redis"
 	"log/slog"
-	"slices"
 	"time"
 )
 
@@ -18,7 +14,13 @@ type Queries struct {
 	log        *slog.Logger
 	es         eventing.EventStore
 	authorizer authz.Authorizer
-	rd         rueidis.Client
+	readModels projector.ReadModels
+	projectors *projector.Supervisors
+
+	consistencyTimeout time.Duration
+
+	broadcaster          *eventing.EventBroadcaster
+	watchRefreshInterval time.Duration
 
 	// Deprecated: use a proper view model.
 	repos domain.Repositories
@@ -28,105 +30,117 @@ func NewQueries(
 	log *slog.Logger,
 	es eventing.EventStore,
 	authorizer authz.Authorizer,
-	rd rueidis.Client,
+	readModels projector.ReadModels,
 	repos domain.Repositories,
+	projectors *projector.Supervisors,
+	opts ...QueriesOpt,
 ) *Queries {
-	return &Queries{
-		log:        log,
-		es:         es,
-		authorizer: authorizer,
-		rd:         rd,
-		repos:      repos,
+	q := &Queries{
+		log:                  log,
+		es:                   es,
+		authorizer:           authorizer,
+		readModels:           readModels,
+		repos:                repos,
+		projectors:           projectors,
+		consistencyTimeout:   DefaultConsistencyTimeout,
+		watchRefreshInterval: DefaultWatchRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// DefaultConsistencyTimeout bounds how long a query waits for a projection to catch up with the writes of the client.
+const DefaultConsistencyTimeout = 2 * time.Second
+
+type QueriesOpt func(q *Queries)
+
+// WithConsistencyTimeout overrides the DefaultConsistencyTimeout, unless the timeout is zero.
+func WithConsistencyTimeout(timeout time.Duration) QueriesOpt {
+	return func(q *Queries) {
+		if timeout != 0 {
+			q.consistencyTimeout = timeout
+		}
+	}
+}
+
+// WithEventBroadcaster lets watches re-run their views as soon as events of interest have been appended.
+// The broadcaster has to be notified after the supervisors, so that the projections are advanced already.
+func WithEventBroadcaster(broadcaster *eventing.EventBroadcaster) QueriesOpt {
+	return func(q *Queries) {
+		q.broadcaster = broadcaster
+	}
+}
+
+// WithWatchRefreshInterval overrides the DefaultWatchRefreshInterval, unless the interval is zero.
+func WithWatchRefreshInterval(interval time.Duration) QueriesOpt {
+	return func(q *Queries) {
+		if interval != 0 {
+			q.watchRefreshInterval = interval
+		}
+	}
+}
+
+// awaitProjection waits until the projection reflects the writes the client passed a consistency token for.
+// Once the timeout elapses the query proceeds anyway, serving possibly stale data is preferred over failing.
+func (q *Queries) awaitProjection(ctx context.Context, projection eventing.ProjectionName) {
+	position, ok := eventing.MinPositionFromContext(ctx)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, q.consistencyTimeout)
+	defer cancel()
+	if err := q.projectors.Await(ctx, projection, position); err != nil {
+		q.log.WarnContext(ctx, "Serving projection that might not reflect the consistency token",
+			slog.String("projection", string(projection)),
+			slog.String("position", position.String()),
+			slog.String("err", err.Error()))
 	}
 }
 
 func (q *Queries) getAccountProjection(ctx context.Context, id domain.AccountID) (*projector.AccountProjection, error) {
-	var a projector.AccountProjection
-	cmd := q.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", projector.ProjectionAccountPrefix, id)).Path(".").Build()
-	return &a, q.rd.Do(ctx, cmd).DecodeJSON(&a)
+	q.awaitProjection(ctx, projector.ProjectionAccountName)
+	return q.readModels.Account(ctx, id)
 }
 
 func (q *Queries) getPersonProjection(ctx context.Context, id domain.PersonID) (*projector.PersonProjection, error) {
-	var p projector.PersonProjection
-	cmd := q.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", projector.ProjectionPersonPrefix, id)).Path(".").Build()
-	if err := q.rd.Do(ctx, cmd).DecodeJSON(&p); err != nil {
-		return nil, err
-	}
-	return &p, nil
+	q.awaitProjection(ctx, projector.ProjectionPersonName)
+	return q.readModels.Person(ctx, id)
 }
 
 func (q *Queries) getPersonProjectionByPendingToken(ctx context.Context, token domain.PersonLinkToken) ([]*projector.PersonProjection, error) {
-	rdq := fmt.Sprintf("@pending_link_token:{%s}", token)
-	cmd := q.rd.B().FtSearch().Index(projector.ProjectionPersonIDXName).Query(rdq).Dialect(4).Build()
-	_, docs, err := q.rd.Do(ctx, cmd).AsFtSearch()
-	if err != nil {
-		return nil, err
-	}
-	return redis.UnmarshalDocs[projector.PersonProjection](docs)
+	q.awaitProjection(ctx, projector.ProjectionPersonName)
+	return q.readModels.PersonsByPendingLinkToken(ctx, token)
 }
 
 func (q *Queries) getPersonProjections(ctx context.Context, ds []domain.PersonID) ([]*projector.PersonProjection, error) {
 	if len(ds) == 0 {
 		return nil, nil
 	}
-
-	var p []*projector.PersonProjection
-	keys := make([]string, len(ds))
-	for i, d := range ds {
-		keys[i] = fmt.Sprintf("%s%s", projector.ProjectionPersonPrefix, d)
-	}
-	cmd := q.rd.B().JsonMget().Key(keys...).Path(".").Build()
-	if err := rueidis.DecodeSliceOfJSON(q.rd.Do(ctx, cmd), &p); err != nil {
-		return nil, err
-	}
-	return removeNils(p), nil
+	q.awaitProjection(ctx, projector.ProjectionPersonName)
+	return q.readModels.Persons(ctx, ds)
 }
 
 func (q *Queries) getTeamProjection(ctx context.Context, id domain.TeamID) (*projector.TeamProjection, error) {
-	var a projector.TeamProjection
-	cmd := q.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", projector.ProjectionTeamPrefix, id)).Path(".").Build()
-	return &a, q.rd.Do(ctx, cmd).DecodeJSON(&a)
+	q.awaitProjection(ctx, projector.ProjectionTeamName)
+	return q.readModels.Team(ctx, id)
 }
 
 func (q *Queries) getTrainingProjectionsByTeamID(ctx context.Context, teamID domain.TeamID, minTime time.Time) ([]*projector.TrainingProjection, error) {
-	rdq := fmt.Sprintf("@owning_team_id:{%s} @scheduled_at_ts:[%d +inf]", teamID, minTime.Unix())
-	cmd := q.rd.B().FtSearch().Index(projector.ProjectionTrainingIDXName).Query(rdq).Sortby("scheduled_at_ts").Asc().Dialect(4).Build()
-	_, docs, err := q.rd.Do(ctx, cmd).AsFtSearch()
-	if err != nil {
-		return nil, err
-	}
-	return redis.UnmarshalDocs[projector.TrainingProjection](docs)
+	q.awaitProjection(ctx, projector.ProjectionTrainingName)
+	return q.readModels.Trainings(ctx, teamID, minTime)
 }
 
 func (q *Queries) getTrainingProjectionsByTeamIDAndPersonID(ctx context.Context, teamID domain.TeamID, personId domain.PersonID, minTime time.Time) ([]*projector.TrainingProjection, error) {
-	rdq := fmt.Sprintf("@owning_team_id:{%s} @scheduled_at_ts:[%d +inf] @nominated_person_ids:{%s}", teamID, minTime.Unix(), personId)
-	cmd := q.rd.B().FtSearch().Index(projector.ProjectionTrainingIDXName).Query(rdq).Sortby("scheduled_at_ts").Asc().Dialect(4).Build()
-	_, docs, err := q.rd.Do(ctx, cmd).AsFtSearch()
-	if err != nil {
-		return nil, err
-	}
-	return redis.UnmarshalDocs[projector.TrainingProjection](docs)
+	q.awaitProjection(ctx, projector.ProjectionTrainingName)
+	return q.readModels.NominatedTrainings(ctx, teamID, personId, minTime)
 }
 
 func (q *Queries) getClubProjections(ctx context.Context, ds []domain.ClubID) ([]*projector.ClubProjection, error) {
 	if len(ds) == 0 {
 		return nil, nil
 	}
-
-	var p []*projector.ClubProjection
-	keys := make([]string, len(ds))
-	for i, d := range ds {
-		keys[i] = fmt.Sprintf("%s%s", projector.ProjectionClubPrefix, d)
-	}
-	cmd := q.rd.B().JsonMget().Key(keys...).Path(".").Build()
-	if err := rueidis.DecodeSliceOfJSON(q.rd.Do(ctx, cmd), &p); err != nil {
-		return nil, err
-	}
-	return removeNils(p), nil
-}
-
-func removeNils[T any](s []*T) []*T {
-	return slices.DeleteFunc(s, func(t *T) bool {
-		return t == nil
-	})
+	q.awaitProjection(ctx, projector.ProjectionClubName)
+	return q.readModels.Clubs(ctx, ds)
 }
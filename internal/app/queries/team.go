@@ -7,10 +7,7 @@ import (
 	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/projector"
-	"github.com/rsmidt/soccerbuddy/internal/redis"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
-	"golang.org/x/exp/maps"
-	"strings"
 	"time"
 )
 
@@ -39,27 +36,26 @@ func (q *Queries) ListTeams(ctx context.Context, query ListTeamsQuery) (*ListTea
 	if err != nil {
 		return nil, err
 	}
-	idFilter := strings.Join(maps.Keys(idSet), "|")
-
-	cmd := q.rd.B().FtSearch().Index(projector.ProjectionTeamIDXName).
-		Query(fmt.Sprintf("@id:{%s} @owning_club_id:{%s}", idFilter, query.OwningClubID)).
-		Return("4").Identifier("$.id").Identifier("name").Identifier("owning_club_id").Identifier("slug").
-		Dialect(4).
-		Build()
-	_, docs, err := q.rd.Do(ctx, cmd).AsFtSearch()
+	teamIDs := make([]domain.TeamID, 0, len(idSet))
+	for id := range idSet {
+		teamIDs = append(teamIDs, domain.TeamID(id))
+	}
+
+	q.awaitProjection(ctx, projector.ProjectionTeamName)
+	teams, err := q.readModels.Teams(ctx, query.OwningClubID, teamIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query team projection: %w", err)
 	}
 	view := ListTeamsView{
-		Teams: make([]ListTeamsTeamView, len(docs)),
+		Teams: make([]ListTeamsTeamView, len(teams)),
 	}
-	for i, doc := range docs {
+	for i, team := range teams {
 		view.Teams[i] = ListTeamsTeamView{
-			ID:           domain.TeamID(doc.Doc["$.id"]),
-			Name:         redis.FlattenToString(doc.Doc["name"]),
-			OwningClubID: domain.ClubID(redis.FlattenToString(doc.Doc["owning_club_id"])),
-			CreatedAt:    redis.FlattenToTime(doc.Doc["created_at"]),
-			UpdatedAt:    redis.FlattenToTime(doc.Doc["updated_at"]),
+			ID:           team.ID,
+			Name:         team.Name,
+			OwningClubID: team.OwningClubID,
+			CreatedAt:    team.CreatedAt,
+			UpdatedAt:    team.UpdatedAt,
 		}
 	}
 	return &view, nil
@@ -153,21 +149,17 @@ func (q *Queries) SearchPersonsNotInTeam(ctx context.Context, query SearchPerson
 	if err != nil {
 		return nil, err
 	}
-	cmd := q.rd.B().FtSearch().Index(projector.ProjectionPersonIDXName).
-		Query(fmt.Sprintf("-@team_id:{%s} @owning_club_id:{%s} (@first_name:%%%%%s%%%% | @last_name: %%%%%s%%%%)", query.TeamID, *clubIDRaw, query.Query, query.Query)).
-		Return("3").Identifier("$.id").Identifier("first_name").Identifier("last_name").
-		Dialect(4).
-		Build()
-	_, docs, err := q.rd.Do(ctx, cmd).AsFtSearch()
+	q.awaitProjection(ctx, projector.ProjectionPersonName)
+	persons, err := q.readModels.SearchPersonsNotInTeam(ctx, domain.ClubID(*clubIDRaw), query.TeamID, query.Query)
 	if err != nil {
 		return nil, err
 	}
-	v := make([]PersonsNotInTeamViewPerson, len(docs))
-	for i, doc := range docs {
+	v := make([]PersonsNotInTeamViewPerson, len(persons))
+	for i, p := range persons {
 		v[i] = PersonsNotInTeamViewPerson{
-			ID:        domain.PersonID(doc.Doc["$.id"]),
-			FirstName: redis.FlattenToString(doc.Doc["first_name"]),
-			LastName:  redis.FlattenToString(doc.Doc["last_name"]),
+			ID:        p.ID,
+			FirstName: p.FirstName,
+			LastName:  p.LastName,
 		}
 	}
 	return &PersonsNotInTeamView{
@@ -200,9 +192,9 @@ func (q *Queries) ListTeamMembers(ctx context.Context, query *ListTeamMembersQue
 		return nil, err
 	}
 
-	var a []*projector.TeamMemberProjection
-	cmd := q.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", projector.ProjectionTeamPrefix, query.TeamID)).Path("$.members.*").Build()
-	if err := q.rd.Do(ctx, cmd).DecodeJSON(&a); err != nil {
+	q.awaitProjection(ctx, projector.ProjectionTeamName)
+	a, err := q.readModels.TeamMembers(ctx, query.TeamID)
+	if err != nil {
 		return nil, err
 	}
 	membersByPersonID := make(map[domain.PersonID]ListTeamMembersTeamMemberView, len(a))
@@ -221,6 +213,14 @@ func (q *Queries) ListTeamMembers(ctx context.Context, query *ListTeamMembersQue
 	}, nil
 }
 
+// WatchTeamMembers sends the members of the team and again whenever they changed, until ctx is done.
+func (q *Queries) WatchTeamMembers(ctx context.Context, query *ListTeamMembersQuery, send func(*ListTeamMembersView) error) error {
+	projections := []eventing.ProjectionName{projector.ProjectionTeamName}
+	return watch(ctx, q, projections, func(ctx context.Context) (*ListTeamMembersView, error) {
+		return q.ListTeamMembers(ctx, query)
+	}, send)
+}
+
 type GetMyTeamHomeQuery struct {
 	TeamID domain.TeamID
 }
@@ -437,3 +437,16 @@ outer:
 		OwningClubID: p.OwningClubID,
 	}, nil
 }
+
+// WatchMyTeamHome sends the team home of the principal and again whenever it changed, until ctx is done.
+func (q *Queries) WatchMyTeamHome(ctx context.Context, query *GetMyTeamHomeQuery, send func(*MyTeamHomeView) error) error {
+	projections := []eventing.ProjectionName{
+		projector.ProjectionAccountName,
+		projector.ProjectionPersonName,
+		projector.ProjectionTeamName,
+		projector.ProjectionTrainingName,
+	}
+	return watch(ctx, q, projections, func(ctx context.Context) (*MyTeamHomeView, error) {
+		return q.GetMyTeamHome(ctx, query)
+	}, send)
+}
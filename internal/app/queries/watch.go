@@ -0,0 +1,57 @@
+package queries
+
+import (
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/projector"
+	"reflect"
+	"time"
+)
+
+// DefaultWatchRefreshInterval is how often watches re-run their views without being notified.
+// This picks up changes that another instance projected only after the notification arrived here,
+// as well as views that change with the time, e.g. trainings that have passed.
+const DefaultWatchRefreshInterval = 30 * time.Second
+
+// watch sends the result of view and runs it again whenever one of the projections might have changed.
+// Results equal to the last sent one are skipped. The view has to authorize the principal on every run,
+// so that revoked permissions end the watch.
+// watch returns when ctx is done or view or send fail.
+func watch[T any](
+	ctx context.Context,
+	q *Queries,
+	projections []eventing.ProjectionName,
+	view func(ctx context.Context) (T, error),
+	send func(T) error,
+) error {
+	// Subscribe before the first run, so that no change between the run and the subscription is missed.
+	var notified <-chan struct{}
+	if q.broadcaster != nil {
+		ch, unsubscribe := q.broadcaster.Subscribe(projector.Interests(projections...)...)
+		defer unsubscribe()
+		notified = ch
+	}
+	ticker := time.NewTicker(q.watchRefreshInterval)
+	defer ticker.Stop()
+
+	var last T
+	for sent := false; ; {
+		current, err := view(ctx)
+		if err != nil {
+			return err
+		}
+		if !sent || !reflect.DeepEqual(current, last) {
+			if err := send(current); err != nil {
+				return err
+			}
+			last, sent = current, true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-notified:
+		case <-ticker.C:
+		}
+	}
+}
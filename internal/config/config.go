@@ -1,8 +1,12 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"github.com/spf13/viper"
+	"maps"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -24,16 +28,102 @@ type EventJournalRedisConfig struct {
 	Host string
 }
 
+// EventJournalSnapshotConfig configures the aggregate snapshots.
+type EventJournalSnapshotConfig struct {
+	// Disabled turns off snapshots entirely.
+	Disabled bool
+	// Threshold is the number of replayed events after which a new snapshot is taken.
+	Threshold int
+}
+
+// EventJournalEncryptionConfig configures the key-encryption keys that wrap the crypto-shredding keys.
+type EventJournalEncryptionConfig struct {
+	// ActiveKeyID is the ID of the key that wraps new keys.
+	ActiveKeyID string
+	// Keys maps key IDs to base64 encoded 32 byte keys.
+	// The config loader lowercases map keys, so IDs should be lowercase.
+	Keys map[string]string
+	// KeyFile is the path to a file with additional keys, one "<id>=<base64 key>" per line.
+	KeyFile string
+	// LookupKey is the base64 encoded key that hashes lookups of personal data, e.g. emails.
+	// Unlike the key-encryption keys it can't be rotated, as the hashes can't be recomputed without the values.
+	LookupKey string
+}
+
+// LoadLookupKey returns the decoded lookup key.
+func (c *EventJournalEncryptionConfig) LoadLookupKey() ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(c.LookupKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode lookup key: %w", err)
+	}
+	if len(key) < 32 {
+		return nil, fmt.Errorf("lookup key must be at least 32 bytes long")
+	}
+	return key, nil
+}
+
+// LoadKeys returns the decoded keys of the config and the key file by their ID.
+func (c *EventJournalEncryptionConfig) LoadKeys() (map[string][]byte, error) {
+	encoded := maps.Clone(c.Keys)
+	if encoded == nil {
+		encoded = make(map[string]string)
+	}
+	if c.KeyFile != "" {
+		content, err := os.ReadFile(c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		for i, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			id, key, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid key in line %d of key file", i+1)
+			}
+			encoded[strings.TrimSpace(id)] = strings.TrimSpace(key)
+		}
+	}
+	keys := make(map[string][]byte, len(encoded))
+	for id, key := range encoded {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %s: %w", id, err)
+		}
+		keys[id] = decoded
+	}
+	return keys, nil
+}
+
 // EventJournalConfig configures the event journal.
 type EventJournalConfig struct {
-	PG    EventJournalPGConfig
-	Redis EventJournalRedisConfig
+	PG         EventJournalPGConfig
+	Redis      EventJournalRedisConfig
+	Snapshot   EventJournalSnapshotConfig
+	Encryption EventJournalEncryptionConfig
 }
 
 type PermifyConfig struct {
 	Host string
 }
 
+// The engines that can evaluate the permissions.
+const (
+	AuthzEnginePermify  = "permify"
+	AuthzEngineEmbedded = "embedded"
+)
+
+// AuthzConfig configures the authorization.
+type AuthzConfig struct {
+	// Engine evaluates the permissions, either AuthzEnginePermify or AuthzEngineEmbedded.
+	// The embedded engine keeps the relations in postgres, so no permify server is needed.
+	// After switching the engine, the reconcile-permissions command fills in the relations of the new one.
+	Engine string
+	// SchemaFile is the permify schema the embedded engine evaluates.
+	SchemaFile string
+}
+
 // SetupConfig configures the server setup.
 type SetupConfig struct {
 	Root SetupRootConfig
@@ -47,17 +137,50 @@ type SetupRootConfig struct {
 	LastName  string
 }
 
+// The stores the read models can be projected to.
+const (
+	ReadModelsRedis    = "redis"
+	ReadModelsPostgres = "postgres"
+)
+
 // ProjectionConfig configures the projection.
 type ProjectionConfig struct {
 	PollingInterval time.Duration
+	// MaxLag is the age of the oldest pending event after which a projection is reported as degraded.
+	MaxLag time.Duration
+	// ReadModels is the store the read models are projected to, either ReadModelsRedis or ReadModelsPostgres.
+	// Redis is not needed at all with ReadModelsPostgres.
+	ReadModels string
+}
+
+// CommandsConfig configures the command handling.
+// Fields left at their zero value keep the defaults of the commands.
+type CommandsConfig struct {
+	// RetryAttempts is the maximum number of attempts of a command that conflicts with a concurrent modification.
+	RetryAttempts int
+	// RetryJitter is the upper bound of the random delay before a retry.
+	RetryJitter time.Duration
+}
+
+// QueriesConfig configures the query handling.
+// Fields left at their zero value keep the defaults of the queries.
+type QueriesConfig struct {
+	// ConsistencyTimeout bounds how long a query waits for projections to reach the consistency token of the client.
+	ConsistencyTimeout time.Duration
+
+	// WatchRefreshInterval is how often watching clients get their views re-run without a change being notified.
+	WatchRefreshInterval time.Duration
 }
 
 // Config configures the server.
 type Config struct {
 	EventJournal EventJournalConfig
 	Permify      PermifyConfig
+	Authz        AuthzConfig
 	Setup        SetupConfig
 	Projection   ProjectionConfig
+	Commands     CommandsConfig
+	Queries      QueriesConfig
 
 	Host string
 }
@@ -76,13 +199,45 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("EventJournal.PG.Name is required")
 	}
 
-	if c.EventJournal.Redis.Host == "" {
+	switch c.Projection.ReadModels {
+	case "":
+		// Set default read model store if none specified.
+		c.Projection.ReadModels = ReadModelsRedis
+	case ReadModelsRedis, ReadModelsPostgres:
+	default:
+		return fmt.Errorf("Projection.ReadModels must be either %q or %q", ReadModelsRedis, ReadModelsPostgres)
+	}
+	if c.Projection.ReadModels == ReadModelsRedis && c.EventJournal.Redis.Host == "" {
 		return fmt.Errorf("EventJournal.Redis.Host is required")
 	}
 
-	if c.Permify.Host == "" {
+	if c.EventJournal.Snapshot.Threshold == 0 {
+		// Set default threshold if none specified.
+		c.EventJournal.Snapshot.Threshold = 100
+	}
+
+	if c.EventJournal.Encryption.ActiveKeyID == "" {
+		return fmt.Errorf("EventJournal.Encryption.ActiveKeyID is required")
+	}
+	if c.EventJournal.Encryption.LookupKey == "" {
+		return fmt.Errorf("EventJournal.Encryption.LookupKey is required")
+	}
+
+	switch c.Authz.Engine {
+	case "":
+		// Set default authorization engine if none specified.
+		c.Authz.Engine = AuthzEnginePermify
+	case AuthzEnginePermify, AuthzEngineEmbedded:
+	default:
+		return fmt.Errorf("Authz.Engine must be either %q or %q", AuthzEnginePermify, AuthzEngineEmbedded)
+	}
+	if c.Authz.Engine == AuthzEnginePermify && c.Permify.Host == "" {
 		return fmt.Errorf("Permify.Host is required")
 	}
+	if c.Authz.SchemaFile == "" {
+		// Set default schema file if none specified.
+		c.Authz.SchemaFile = "permify/schema.perm"
+	}
 
 	if c.Setup.Root.Email == "" {
 		return fmt.Errorf("Setup.Root.Email is required")
@@ -92,6 +247,11 @@ func (c *Config) Validate() error {
 		// Set default interval if none specified.
 		c.Projection.PollingInterval = 10 * time.Second
 	}
+	if c.Projection.MaxLag == 0 {
+		// Set default lag if none specified.
+		c.Projection.MaxLag = time.Minute
+	}
+
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 )
@@ -11,6 +12,15 @@ const (
 	AccountEmailUniqueConstraint         = "account_email"
 	AccountUsedLinkTokenUniqueConstraint = "account_used_link_token"
 	AccountLookupEmail                   = "account_email"
+
+	AccountSnapshotVersion = eventing.SnapshotVersion(1)
+)
+
+var (
+	// SensitiveLookupFields are the lookups that only store a hash of their value.
+	SensitiveLookupFields = []eventing.LookupFieldName{AccountLookupEmail}
+	// SensitiveUniqueConstraintFields are the unique constraints that only store a hash of their value.
+	SensitiveUniqueConstraintFields = []string{AccountEmailUniqueConstraint}
 )
 
 var (
@@ -21,6 +31,8 @@ var (
 	ErrAccountAlreadyHasSelfLink     = errors.New("already has self link")
 )
 
+var _ eventing.EncryptedSnapshotter = (*Account)(nil)
+
 type (
 	AccountID               string
 	HashedPassword          string
@@ -91,7 +103,7 @@ func (a *Account) Reduce(events []*eventing.JournalEvent) {
 		switch e := event.Event.(type) {
 		case *RootAccountCreatedEvent:
 			a.State = AccountStateActive
-			a.Password = e.HashedPassword
+			a.Password = HashedPassword(e.HashedPassword.Value)
 			a.IsRoot = true
 			a.AppInstallations = make(map[InstallationID]*AppInstallation)
 		case *AccountCreatedEvent:
@@ -128,6 +140,52 @@ func (a *Account) Reduce(events []*eventing.JournalEvent) {
 	a.BaseWriter.Reduce(events)
 }
 
+// accountSnapshot is the serialized state of an Account.
+type accountSnapshot struct {
+	State            AccountState                        `json:"state"`
+	FirstName        string                              `json:"first_name"`
+	LastName         string                              `json:"last_name"`
+	LinkedPersons    []*AccountLinkedPerson              `json:"linked_persons"`
+	Password         HashedPassword                      `json:"password"`
+	AppInstallations map[InstallationID]*AppInstallation `json:"app_installations"`
+	IsRoot           bool                                `json:"is_root"`
+}
+
+func (a *Account) SnapshotVersion() eventing.SnapshotVersion {
+	return AccountSnapshotVersion
+}
+
+func (a *Account) Snapshot() ([]byte, error) {
+	return json.Marshal(&accountSnapshot{
+		State:            a.State,
+		FirstName:        a.FirstName,
+		LastName:         a.LastName,
+		LinkedPersons:    a.LinkedPersons,
+		Password:         a.Password,
+		AppInstallations: a.AppInstallations,
+		IsRoot:           a.IsRoot,
+	})
+}
+
+func (a *Account) RestoreSnapshot(version eventing.AggregateVersion, payload []byte) error {
+	var snapshot accountSnapshot
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		return err
+	}
+	a.State = snapshot.State
+	a.FirstName = snapshot.FirstName
+	a.LastName = snapshot.LastName
+	a.LinkedPersons = snapshot.LinkedPersons
+	a.Password = snapshot.Password
+	a.AppInstallations = snapshot.AppInstallations
+	a.IsRoot = snapshot.IsRoot
+	a.RestoreVersion(version)
+	return nil
+}
+
+// EncryptSnapshot marks the snapshot of the account to be encrypted, as it contains personal data.
+func (a *Account) EncryptSnapshot() {}
+
 func (a *Account) InitAsRoot(email string, password HashedPassword, firstName, lastName string) error {
 	if a.State != AccountStateUnspecified {
 		return NewInvalidAggregateStateError(a.Aggregate(), int(AccountStateUnspecified), int(a.State))
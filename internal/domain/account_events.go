@@ -43,11 +43,11 @@ func (r *AccountCreatedEvent) IsShredded() bool {
 
 func (r *AccountCreatedEvent) UniqueConstraintsToAdd() []eventing.UniqueConstraint {
 	return []eventing.UniqueConstraint{
-		eventing.NewUniqueConstraint(r.AggregateID(), AccountEmailUniqueConstraint, r.Email.Value),
+		eventing.NewSensitiveUniqueConstraint(r.AggregateID(), AccountEmailUniqueConstraint, r.Email.Value),
 	}
 }
 
-func (r *AccountCreatedEvent) LookupValues() eventing.LookupMap {
+func (r *AccountCreatedEvent) SensitiveLookupValues() eventing.LookupMap {
 	return eventing.LookupMap{
 		AccountLookupEmail: eventing.LookupFieldValue(r.Email.Value),
 	}
@@ -58,16 +58,16 @@ func (r *AccountCreatedEvent) DeclareOwners() []eventing.AggregateID {
 }
 
 func (r *AccountCreatedEvent) AcceptCrypto(transformer eventing.CryptoTransformer) error {
-	if err := transformer.Transform(r.AggregateID(), &r.HashedPassword); err != nil {
+	if err := transformer.Transform(r.AggregateID(), "hashed_password", &r.HashedPassword); err != nil {
 		return err
 	}
-	if err := transformer.TransformWithDefault(r.AggregateID(), &r.FirstName, RedactedString); err != nil {
+	if err := transformer.TransformWithDefault(r.AggregateID(), "first_name", &r.FirstName, RedactedString); err != nil {
 		return err
 	}
-	if err := transformer.TransformWithDefault(r.AggregateID(), &r.LastName, RedactedString); err != nil {
+	if err := transformer.TransformWithDefault(r.AggregateID(), "last_name", &r.LastName, RedactedString); err != nil {
 		return err
 	}
-	if err := transformer.TransformWithDefault(r.AggregateID(), &r.Email, RedactedString); err != nil {
+	if err := transformer.TransformWithDefault(r.AggregateID(), "email", &r.Email, RedactedString); err != nil {
 		return err
 	}
 	return nil
@@ -118,22 +118,23 @@ func (l *AccountLinkedToPersonEvent) IsShredded() bool {
 
 const (
 	RootAccountCreatedEventType    = eventing.EventType("root_account_created")
-	RootAccountCreatedEventVersion = eventing.EventVersion("v1")
+	RootAccountCreatedEventVersion = eventing.EventVersion("v2")
 )
 
 var (
-	_ eventing.Event                 = (*RootAccountCreatedEvent)(nil)
-	_ eventing.UniqueConstraintAdder = (*RootAccountCreatedEvent)(nil)
-	_ eventing.LookupProvider        = (*RootAccountCreatedEvent)(nil)
+	_ eventing.Event                   = (*RootAccountCreatedEvent)(nil)
+	_ eventing.UniqueConstraintAdder   = (*RootAccountCreatedEvent)(nil)
+	_ eventing.SensitiveLookupProvider = (*RootAccountCreatedEvent)(nil)
+	_ eventing.EncryptedEvent          = (*RootAccountCreatedEvent)(nil)
 )
 
 type RootAccountCreatedEvent struct {
 	*eventing.EventBase
 
-	Email          string         `json:"email"`
-	HashedPassword HashedPassword `json:"hashed_password"`
-	FirstName      string         `json:"first_name"`
-	LastName       string         `json:"last_name"`
+	Email          eventing.EncryptedString `json:"email"`
+	HashedPassword eventing.EncryptedString `json:"hashed_password"`
+	FirstName      eventing.EncryptedString `json:"first_name"`
+	LastName       eventing.EncryptedString `json:"last_name"`
 }
 
 func NewRootAccountCreatedEvent(id AccountID, email string, hashedPassword HashedPassword, firstName, lastName string) *RootAccountCreatedEvent {
@@ -141,29 +142,54 @@ func NewRootAccountCreatedEvent(id AccountID, email string, hashedPassword Hashe
 
 	return &RootAccountCreatedEvent{
 		EventBase:      base,
-		Email:          email,
-		HashedPassword: hashedPassword,
-		FirstName:      firstName,
-		LastName:       lastName,
+		Email:          eventing.NewEncryptedString(email),
+		HashedPassword: eventing.NewEncryptedString(string(hashedPassword)),
+		FirstName:      eventing.NewEncryptedString(firstName),
+		LastName:       eventing.NewEncryptedString(lastName),
 	}
 }
 
+// RootAccountCreatedEventV1ToV2 wraps the plaintext values of v1 into encrypted strings.
+func RootAccountCreatedEventV1ToV2(payload []byte) ([]byte, error) {
+	return upcastPlaintextFields(payload, "email", "hashed_password", "first_name", "last_name")
+}
+
 func (r *RootAccountCreatedEvent) IsShredded() bool {
-	return false
+	return r.FirstName.IsShredded || r.LastName.IsShredded || r.Email.IsShredded || r.HashedPassword.IsShredded
 }
 
 func (r *RootAccountCreatedEvent) UniqueConstraintsToAdd() []eventing.UniqueConstraint {
 	return []eventing.UniqueConstraint{
-		eventing.NewUniqueConstraint(r.AggregateID(), AccountEmailUniqueConstraint, r.Email),
+		eventing.NewSensitiveUniqueConstraint(r.AggregateID(), AccountEmailUniqueConstraint, r.Email.Value),
 	}
 }
 
-func (r *RootAccountCreatedEvent) LookupValues() eventing.LookupMap {
+func (r *RootAccountCreatedEvent) SensitiveLookupValues() eventing.LookupMap {
 	return eventing.LookupMap{
-		AccountLookupEmail: eventing.LookupFieldValue(r.Email),
+		AccountLookupEmail: eventing.LookupFieldValue(r.Email.Value),
 	}
 }
 
+func (r *RootAccountCreatedEvent) DeclareOwners() []eventing.AggregateID {
+	return []eventing.AggregateID{r.AggregateID()}
+}
+
+func (r *RootAccountCreatedEvent) AcceptCrypto(transformer eventing.CryptoTransformer) error {
+	if err := transformer.Transform(r.AggregateID(), "hashed_password", &r.HashedPassword); err != nil {
+		return err
+	}
+	if err := transformer.TransformWithDefault(r.AggregateID(), "first_name", &r.FirstName, RedactedString); err != nil {
+		return err
+	}
+	if err := transformer.TransformWithDefault(r.AggregateID(), "last_name", &r.LastName, RedactedString); err != nil {
+		return err
+	}
+	if err := transformer.TransformWithDefault(r.AggregateID(), "email", &r.Email, RedactedString); err != nil {
+		return err
+	}
+	return nil
+}
+
 // ========================================================
 // MobileDeviceAttachedToAccountEvent
 // ========================================================
@@ -273,12 +299,12 @@ func (r *AccountRegisteredEvent) IsShredded() bool {
 
 func (r *AccountRegisteredEvent) UniqueConstraintsToAdd() []eventing.UniqueConstraint {
 	return []eventing.UniqueConstraint{
-		eventing.NewUniqueConstraint(r.AggregateID(), AccountEmailUniqueConstraint, r.Email.Value),
+		eventing.NewSensitiveUniqueConstraint(r.AggregateID(), AccountEmailUniqueConstraint, r.Email.Value),
 		eventing.NewUniqueConstraint(r.AggregateID(), AccountUsedLinkTokenUniqueConstraint, string(r.UsedLinkToken)),
 	}
 }
 
-func (r *AccountRegisteredEvent) LookupValues() eventing.LookupMap {
+func (r *AccountRegisteredEvent) SensitiveLookupValues() eventing.LookupMap {
 	return eventing.LookupMap{
 		AccountLookupEmail: eventing.LookupFieldValue(r.Email.Value),
 	}
@@ -289,16 +315,16 @@ func (r *AccountRegisteredEvent) DeclareOwners() []eventing.AggregateID {
 }
 
 func (r *AccountRegisteredEvent) AcceptCrypto(transformer eventing.CryptoTransformer) error {
-	if err := transformer.Transform(r.AggregateID(), &r.HashedPassword); err != nil {
+	if err := transformer.Transform(r.AggregateID(), "hashed_password", &r.HashedPassword); err != nil {
 		return err
 	}
-	if err := transformer.TransformWithDefault(r.AggregateID(), &r.FirstName, RedactedString); err != nil {
+	if err := transformer.TransformWithDefault(r.AggregateID(), "first_name", &r.FirstName, RedactedString); err != nil {
 		return err
 	}
-	if err := transformer.TransformWithDefault(r.AggregateID(), &r.LastName, RedactedString); err != nil {
+	if err := transformer.TransformWithDefault(r.AggregateID(), "last_name", &r.LastName, RedactedString); err != nil {
 		return err
 	}
-	if err := transformer.TransformWithDefault(r.AggregateID(), &r.Email, RedactedString); err != nil {
+	if err := transformer.TransformWithDefault(r.AggregateID(), "email", &r.Email, RedactedString); err != nil {
 		return err
 	}
 	return nil
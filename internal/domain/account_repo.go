@@ -43,6 +43,7 @@ func (e *EventSourcedAccountRepository) FindByEmail(ctx context.Context, email s
 		AggregateType: AccountAggregateType,
 		FieldName:     AccountLookupEmail,
 		FieldValue:    eventing.LookupFieldValue(email),
+		Sensitive:     true,
 	})
 	if errors.Is(err, eventing.ErrOwnerNotFound) {
 		return nil, ErrAccountNotFound
@@ -71,6 +72,7 @@ func (e *EventSourcedAccountRepository) ExistsByEmail(ctx context.Context, email
 		AggregateType: AccountAggregateType,
 		FieldName:     AccountLookupEmail,
 		FieldValue:    eventing.LookupFieldValue(email),
+		Sensitive:     true,
 	})
 	if errors.Is(err, eventing.ErrOwnerNotFound) {
 		return false, nil
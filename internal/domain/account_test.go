@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/stretchr/testify/assert"
@@ -306,3 +307,42 @@ func TestAccount_AttachMobileDevice(t *testing.T) {
 		})
 	}
 }
+
+func TestAccount_Snapshot(t *testing.T) {
+	accID := idgen.New[AccountID]()
+	personID := idgen.New[PersonID]()
+
+	account := NewAccount(accID)
+	account.Reduce(createInitialEvents(
+		NewAccountCreatedEvent(accID, "Jane", "Doe", "jane@example.com", "hashedpassword"),
+		NewMobileDeviceAttachedToAccountEvent(accID, "installation", "token"),
+		NewAccountLinkedToPersonEvent(accID, personID, AccountLinkSelf, nil, "club", nil),
+	))
+
+	payload, err := account.Snapshot()
+	assert.NoError(t, err)
+
+	restored := NewAccount(accID)
+	assert.NoError(t, restored.RestoreSnapshot(account.Aggregate().Version, payload))
+	assert.Equal(t, account.State, restored.State)
+	assert.Equal(t, account.FirstName, restored.FirstName)
+	assert.Equal(t, account.LastName, restored.LastName)
+	assert.Equal(t, account.Password, restored.Password)
+	assert.Equal(t, account.LinkedPersons, restored.LinkedPersons)
+	assert.Equal(t, account.AppInstallations, restored.AppInstallations)
+	assert.Equal(t, account.Aggregate(), restored.Aggregate())
+}
+
+func TestRootAccountCreatedEventV1ToV2(t *testing.T) {
+	payload := []byte(`{"email":"root@example.com","hashed_password":"hashedpassword","first_name":"Jane","last_name":null}`)
+
+	upcasted, err := RootAccountCreatedEventV1ToV2(payload)
+	assert.NoError(t, err)
+
+	var event RootAccountCreatedEvent
+	assert.NoError(t, json.Unmarshal(upcasted, &event))
+	assert.Equal(t, eventing.NewPlaintextEncryptedString("root@example.com"), event.Email)
+	assert.Equal(t, eventing.NewPlaintextEncryptedString("hashedpassword"), event.HashedPassword)
+	assert.Equal(t, eventing.NewPlaintextEncryptedString("Jane"), event.FirstName)
+	assert.Equal(t, eventing.NewPlaintextEncryptedString(""), event.LastName)
+}
@@ -9,6 +9,8 @@ import (
 var (
 	// ErrUnauthorized is returned when the action is not allowed on the resource.
 	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRelationsNotTransactional is returned when the relations can't be cleared within the transaction of a reset.
+	ErrRelationsNotTransactional = errors.New("relations not transactional")
 )
 
 type Resource struct {
@@ -60,7 +62,9 @@ const (
 	ActionCreatePerson       = "create_person"
 	ActionCreateTeam         = "create_team"
 	ActionListPersons        = "list_persons"
+	ActionManageProjections  = "manage_projections"
 	ActionPersonInitiateLink = "initiate_link"
+	ActionPersonShred        = "shred"
 	ActionScheduleTraining   = "schedule_training"
 )
 
@@ -5,6 +5,17 @@ import "context"
 type RelationStore interface {
 	AddRelations(ctx context.Context, relations []Relation) error
 	RemoveRelations(ctx context.Context, relations []Relation) error
+	// RemoveAllRelations removes every relation the object takes part in, either as entity or as subject.
+	RemoveAllRelations(ctx context.Context, typ, id string) error
+	// ClearRelations removes all stored relations, e.g. before the relations are projected anew.
+	// Returns ErrRelationsNotTransactional if the relations can't be removed within the transaction in the context.
+	ClearRelations(ctx context.Context) error
+}
+
+// RelationReader reads the stored relations, e.g. to compare them with the ones the journal implies.
+type RelationReader interface {
+	// ReadRelations returns all stored relations of entities of the type.
+	ReadRelations(ctx context.Context, entityType string) ([]Relation, error)
 }
 
 type Relation struct {
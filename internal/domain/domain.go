@@ -1,4 +1,44 @@
 package domain
 
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+)
+
 // RedactedString is used when an event was crypto shredded, and we need a placeholder value.
 const RedactedString = "**********"
+
+// PlaintextEventVersions are the event versions that stored personal data in plaintext before it was encrypted.
+// Their values stay readable in the journal, even once the owner is shredded, until the encrypt-plaintext-events
+// command encrypted them.
+var PlaintextEventVersions = []eventing.PlaintextEventVersion{
+	{AggregateType: AccountAggregateType, EventType: RootAccountCreatedEventType, EventVersion: "v1"},
+	{AggregateType: SessionAggregateType, EventType: SessionCreatedEventType, EventVersion: "v1"},
+}
+
+// upcastPlaintextFields wraps plaintext string fields of an event payload into [eventing.EncryptedString]s.
+// Missing and null fields are wrapped as empty strings.
+func upcastPlaintextFields(payload []byte, fields ...string) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		var value *string
+		if rawValue, ok := raw[field]; ok {
+			if err := json.Unmarshal(rawValue, &value); err != nil {
+				return nil, fmt.Errorf("failed to read plaintext field %s: %w", field, err)
+			}
+		}
+		if value == nil {
+			value = new(string)
+		}
+		wrapped, err := json.Marshal(eventing.NewPlaintextEncryptedString(*value))
+		if err != nil {
+			return nil, err
+		}
+		raw[field] = wrapped
+	}
+	return json.Marshal(raw)
+}
@@ -28,6 +28,7 @@ var (
 	ErrPersonHasTooManyPendingLinks = errors.New("too many pending links for person")
 	ErrPersonInvalidLinkToken       = errors.New("invalid link token")
 	ErrPersonLinkTokenExpired       = errors.New("link token has expired")
+	ErrPersonShredded               = errors.New("person has been shredded")
 )
 
 type PersonState int
@@ -35,6 +36,7 @@ type PersonState int
 const (
 	PersonStateUnspecified PersonState = iota
 	PersonStateActive
+	PersonStateShredded
 )
 
 type PersonLinkedAccount struct {
@@ -105,6 +107,13 @@ func (p *Person) Reduce(events []*eventing.JournalEvent) {
 				LinkedAs:  e.LinkedAs,
 				LinkedAt:  event.InsertedAt(),
 			})
+		case *PersonShreddedEvent:
+			p.State = PersonStateShredded
+			p.Firstname = RedactedString
+			p.Lastname = RedactedString
+			p.Birthdate = time.Time{}
+			p.PendingLinks = map[PersonLinkToken]PendingLink{}
+			p.UpdatedAt = event.InsertedAt()
 		}
 	}
 	p.BaseWriter.Reduce(events)
@@ -180,3 +189,20 @@ func (p *Person) FindPendingLink(token PersonLinkToken) (PendingLink, error) {
 	}
 	return PendingLink{}, ErrPersonInvalidLinkToken
 }
+
+// Shred marks the person as forgotten. The personal data itself is shredded by deleting the key of the person.
+func (p *Person) Shred(operator Operator) error {
+	if p.State == PersonStateShredded {
+		return ErrPersonShredded
+	} else if p.State != PersonStateActive {
+		return NewInvalidAggregateStateError(p.Aggregate(), int(PersonStateActive), int(p.State))
+	}
+	p.State = PersonStateShredded
+	p.Firstname = RedactedString
+	p.Lastname = RedactedString
+	p.Birthdate = time.Time{}
+	p.PendingLinks = map[PersonLinkToken]PendingLink{}
+	event := NewPersonShreddedEvent(p.ID, operator, p.OwningClubID)
+	p.Append(event)
+	return nil
+}
@@ -52,13 +52,13 @@ func (p *PersonCreatedEvent) DeclareOwners() []eventing.AggregateID {
 }
 
 func (p *PersonCreatedEvent) AcceptCrypto(transformer eventing.CryptoTransformer) error {
-	if err := transformer.TransformWithDefault(p.AggregateID(), &p.FirstName, RedactedString); err != nil {
+	if err := transformer.TransformWithDefault(p.AggregateID(), "firstname", &p.FirstName, RedactedString); err != nil {
 		return err
 	}
-	if err := transformer.TransformWithDefault(p.AggregateID(), &p.LastName, RedactedString); err != nil {
+	if err := transformer.TransformWithDefault(p.AggregateID(), "lastname", &p.LastName, RedactedString); err != nil {
 		return err
 	}
-	if err := transformer.TransformWithDefault(p.AggregateID(), &p.Birthdate, time.Time{}.Format(time.RFC3339)); err != nil {
+	if err := transformer.TransformWithDefault(p.AggregateID(), "birthdate", &p.Birthdate, time.Time{}.Format(time.RFC3339)); err != nil {
 		return err
 	}
 	return nil
@@ -133,3 +133,35 @@ func NewPersonLinkInitiatedEvent(id PersonID, invitedBy Operator, linkAs Account
 func (l *PersonLinkInitiatedEvent) IsShredded() bool {
 	return false
 }
+
+// ========================================================
+// PersonShreddedEvent
+// ========================================================
+
+const (
+	PersonShreddedEventType    = eventing.EventType("person_shredded")
+	PersonShreddedEventVersion = eventing.EventVersion("v1")
+)
+
+var _ eventing.Event = (*PersonShreddedEvent)(nil)
+
+type PersonShreddedEvent struct {
+	*eventing.EventBase
+
+	ShreddedBy   Operator `json:"shredded_by"`
+	OwningClubID ClubID   `json:"owning_club_id"`
+}
+
+func NewPersonShreddedEvent(id PersonID, shreddedBy Operator, owningClubID ClubID) *PersonShreddedEvent {
+	base := eventing.NewEventBase(eventing.AggregateID(id), PersonAggregateType, PersonShreddedEventVersion, PersonShreddedEventType)
+
+	return &PersonShreddedEvent{
+		EventBase:    base,
+		ShreddedBy:   shreddedBy,
+		OwningClubID: owningClubID,
+	}
+}
+
+func (p *PersonShreddedEvent) IsShredded() bool {
+	return false
+}
@@ -48,5 +48,5 @@ func (e *EventSourcedPersonRepository) ExistsByID(ctx context.Context, id Person
 	if err != nil {
 		return false, err
 	}
-	return person.State != PersonStateUnspecified, nil
+	return person.State == PersonStateActive, nil
 }
@@ -226,3 +226,51 @@ func TestPerson_Claim(t *testing.T) {
 		})
 	}
 }
+
+func TestPerson_Shred(t *testing.T) {
+	personID := idgen.New[PersonID]()
+	clubID := idgen.New[ClubID]()
+	creator := NewOperator(idgen.New[AccountID](), nil)
+	birthdate := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		initialEvents []*eventing.JournalEvent
+		emittedEvents []eventing.Event
+		expectedError error
+	}{
+		{
+			name: "Succeeds if person is active",
+			initialEvents: createInitialEvents(
+				NewPersonCreatedEvent(personID, "John", "Doe", birthdate, creator, clubID),
+				NewPersonLinkInitiatedEvent(personID, creator, AccountLinkParent, "token", time.Now().Add(time.Hour)),
+			),
+			emittedEvents: []eventing.Event{
+				NewPersonShreddedEvent(personID, creator, clubID),
+			},
+		},
+		{
+			name: "Fails if person is already shredded",
+			initialEvents: createInitialEvents(
+				NewPersonCreatedEvent(personID, "John", "Doe", birthdate, creator, clubID),
+				NewPersonShreddedEvent(personID, creator, clubID),
+			),
+			expectedError: ErrPersonShredded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			person := NewPerson(personID)
+			person.Reduce(tt.initialEvents)
+			err := person.Shred(creator)
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.emittedEvents, person.Changes().Events())
+			assert.Equal(t, PersonStateShredded, person.State)
+			assert.Equal(t, RedactedString, person.Firstname)
+			assert.Equal(t, RedactedString, person.Lastname)
+			assert.Empty(t, person.PendingLinks)
+		})
+	}
+}
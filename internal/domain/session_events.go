@@ -12,24 +12,25 @@ import (
 
 const (
 	SessionCreatedEventType    = eventing.EventType("session_created")
-	SessionCreatedEventVersion = eventing.EventVersion("v1")
+	SessionCreatedEventVersion = eventing.EventVersion("v2")
 )
 
 var (
 	_ eventing.Event                 = (*SessionCreatedEvent)(nil)
 	_ eventing.LookupProvider        = (*SessionCreatedEvent)(nil)
 	_ eventing.UniqueConstraintAdder = (*SessionCreatedEvent)(nil)
+	_ eventing.EncryptedEvent        = (*SessionCreatedEvent)(nil)
 )
 
 type SessionCreatedEvent struct {
 	*eventing.EventBase
 
-	Token      SessionToken  `json:"token"`
-	AccountID  AccountID     `json:"account_id"`
-	UserAgent  string        `json:"user_agent"`
-	IPAddress  net.IP        `json:"ip_address"`
-	ValidUntil time.Time     `json:"valid_until"`
-	Role       PrincipalRole `json:"role"`
+	Token      SessionToken             `json:"token"`
+	AccountID  AccountID                `json:"account_id"`
+	UserAgent  eventing.EncryptedString `json:"user_agent"`
+	IPAddress  eventing.EncryptedString `json:"ip_address"`
+	ValidUntil time.Time                `json:"valid_until"`
+	Role       PrincipalRole            `json:"role"`
 }
 
 func NewSessionCreatedEvent(
@@ -47,15 +48,35 @@ func NewSessionCreatedEvent(
 		EventBase:  base,
 		Token:      token,
 		AccountID:  accountID,
-		UserAgent:  userAgent,
-		IPAddress:  ipAddress,
+		UserAgent:  eventing.NewEncryptedString(userAgent),
+		IPAddress:  eventing.NewEncryptedString(ipAddress.String()),
 		ValidUntil: validUntil,
 		Role:       role,
 	}
 }
 
+// SessionCreatedEventV1ToV2 wraps the plaintext user agent and IP address of v1 into encrypted strings.
+func SessionCreatedEventV1ToV2(payload []byte) ([]byte, error) {
+	return upcastPlaintextFields(payload, "user_agent", "ip_address")
+}
+
 func (c *SessionCreatedEvent) IsShredded() bool {
-	return false
+	return c.UserAgent.IsShredded || c.IPAddress.IsShredded
+}
+
+// DeclareOwners returns the account, so that shredding the account also shreds its sessions.
+func (c *SessionCreatedEvent) DeclareOwners() []eventing.AggregateID {
+	return []eventing.AggregateID{eventing.AggregateID(c.AccountID)}
+}
+
+func (c *SessionCreatedEvent) AcceptCrypto(transformer eventing.CryptoTransformer) error {
+	if err := transformer.TransformWithDefault(eventing.AggregateID(c.AccountID), "user_agent", &c.UserAgent, RedactedString); err != nil {
+		return err
+	}
+	if err := transformer.TransformWithDefault(eventing.AggregateID(c.AccountID), "ip_address", &c.IPAddress, ""); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (c *SessionCreatedEvent) LookupValues() eventing.LookupMap {
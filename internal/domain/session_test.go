@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/stretchr/testify/assert"
@@ -115,3 +116,16 @@ func TestSession_Reduce(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionCreatedEventV1ToV2(t *testing.T) {
+	payload := []byte(`{"token":"token","account_id":"account","user_agent":"Mozilla/5.0","ip_address":"192.168.1.1","role":1}`)
+
+	upcasted, err := SessionCreatedEventV1ToV2(payload)
+	assert.NoError(t, err)
+
+	var event SessionCreatedEvent
+	assert.NoError(t, json.Unmarshal(upcasted, &event))
+	assert.Equal(t, SessionToken("token"), event.Token)
+	assert.Equal(t, eventing.NewPlaintextEncryptedString("Mozilla/5.0"), event.UserAgent)
+	assert.Equal(t, eventing.NewPlaintextEncryptedString("192.168.1.1"), event.IPAddress)
+}
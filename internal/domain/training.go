@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"time"
@@ -12,6 +13,8 @@ type (
 
 const (
 	TrainingAggregateType = eventing.AggregateType("training")
+
+	TrainingSnapshotVersion = eventing.SnapshotVersion(1)
 )
 
 var (
@@ -19,6 +22,8 @@ var (
 	ErrTrainingNotFound           = errors.New("team not found")
 )
 
+var _ eventing.Snapshotter = (*Training)(nil)
+
 type TrainingState int
 
 const (
@@ -153,6 +158,37 @@ func (t *Training) Reduce(events []*eventing.JournalEvent) {
 	t.BaseWriter.Reduce(events)
 }
 
+// trainingSnapshot is the serialized state of a Training.
+type trainingSnapshot struct {
+	TeamID       TeamID        `json:"team_id"`
+	OwningClubID ClubID        `json:"owning_club_id"`
+	State        TrainingState `json:"state"`
+}
+
+func (t *Training) SnapshotVersion() eventing.SnapshotVersion {
+	return TrainingSnapshotVersion
+}
+
+func (t *Training) Snapshot() ([]byte, error) {
+	return json.Marshal(&trainingSnapshot{
+		TeamID:       t.TeamID,
+		OwningClubID: t.OwningClubID,
+		State:        t.State,
+	})
+}
+
+func (t *Training) RestoreSnapshot(version eventing.AggregateVersion, payload []byte) error {
+	var snapshot trainingSnapshot
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		return err
+	}
+	t.TeamID = snapshot.TeamID
+	t.OwningClubID = snapshot.OwningClubID
+	t.State = snapshot.State
+	t.RestoreVersion(version)
+	return nil
+}
+
 func (t *Training) Schedule(
 	scheduledAt time.Time,
 	scheduledAtIANA string,
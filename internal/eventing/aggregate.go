@@ -64,3 +64,17 @@ func NewAggregateChangeIntent(aggregateID AggregateID, aggregateType AggregateTy
 		versionMatcher:            matcher,
 	}, nil
 }
+
+// CheckDistinctIntents makes sure that no aggregate is targeted by more than one intent.
+// Returns [ErrDuplicateIntents] otherwise.
+func CheckDistinctIntents(intents []AggregateChangeIntent) error {
+	seen := make(map[Aggregate]struct{}, len(intents))
+	for _, intent := range intents {
+		key := Aggregate{AggregateID: intent.AggregateID(), AggregateType: intent.AggregateType()}
+		if _, ok := seen[key]; ok {
+			return ErrDuplicateIntents
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
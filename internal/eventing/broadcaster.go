@@ -0,0 +1,73 @@
+package eventing
+
+import (
+	"context"
+	"sync"
+)
+
+var _ EventListener = (*EventBroadcaster)(nil)
+
+// EventBroadcaster fans the notifications of an [EventNotifier] out to subscribers that come and go,
+// e.g. clients that watch a view for the duration of a request.
+// The interests have to be known upfront, as the notifier only listens to them once started.
+type EventBroadcaster struct {
+	interests EventInterestSet
+
+	mu   sync.Mutex
+	subs map[*broadcastSubscription]struct{}
+}
+
+type broadcastSubscription struct {
+	interests EventInterestSet
+	ch        chan struct{}
+}
+
+func NewEventBroadcaster(interests ...EventInterest) *EventBroadcaster {
+	return &EventBroadcaster{
+		interests: NewInterestSet(interests...),
+		subs:      make(map[*broadcastSubscription]struct{}),
+	}
+}
+
+func (b *EventBroadcaster) Interests() EventInterestSet {
+	return b.interests
+}
+
+func (b *EventBroadcaster) Notify(ctx context.Context, interests ...EventInterest) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs {
+		for _, interest := range interests {
+			if !sub.interests.IsInterestedIn(interest) {
+				continue
+			}
+			select {
+			case sub.ch <- struct{}{}:
+			default:
+				// The subscriber has not picked up the previous notification yet.
+			}
+			break
+		}
+	}
+	return true
+}
+
+// Subscribe returns a channel that receives a value whenever events of the interests have been appended.
+// Notifications that arrive while the previous one has not been received yet are coalesced.
+// The returned function ends the subscription.
+func (b *EventBroadcaster) Subscribe(interests ...EventInterest) (<-chan struct{}, func()) {
+	sub := &broadcastSubscription{
+		interests: NewInterestSet(interests...),
+		ch:        make(chan struct{}, 1),
+	}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, sub)
+	}
+}
@@ -0,0 +1,39 @@
+package eventing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEventBroadcaster(t *testing.T) {
+	scheduled := EventInterest{AggType: "training", EventType: "TrainingScheduled"}
+	created := EventInterest{AggType: "team", EventType: "TeamCreated"}
+	b := NewEventBroadcaster(scheduled, created)
+
+	trainings, unsubscribe := b.Subscribe(scheduled)
+	teams, _ := b.Subscribe(created)
+
+	// Notifications are coalesced until they are received.
+	b.Notify(context.Background(), scheduled)
+	b.Notify(context.Background(), scheduled)
+	select {
+	case <-trainings:
+	default:
+		t.Fatal("expected a notification")
+	}
+	select {
+	case <-trainings:
+		t.Fatal("expected notifications to be coalesced")
+	case <-teams:
+		t.Fatal("expected no notification of uninterested subscriber")
+	default:
+	}
+
+	unsubscribe()
+	b.Notify(context.Background(), scheduled)
+	select {
+	case <-trainings:
+		t.Fatal("expected no notification after unsubscribing")
+	default:
+	}
+}
@@ -0,0 +1,178 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// awaitPollInterval is the interval in which AwaitProjection checks the progress of a projection.
+const awaitPollInterval = 25 * time.Millisecond
+
+var ErrInvalidConsistencyToken = errors.New("invalid consistency token")
+
+// ParseJournalPosition parses a position in the format of [JournalPosition.String].
+// Clients pass it back as consistency token to read their own writes.
+func ParseJournalPosition(s string) (JournalPosition, error) {
+	txID, seq, ok := strings.Cut(s, ":")
+	if !ok {
+		return JournalPosition{}, ErrInvalidConsistencyToken
+	}
+	transactionID, err := strconv.ParseUint(txID, 10, 64)
+	if err != nil {
+		return JournalPosition{}, fmt.Errorf("%w: %w", ErrInvalidConsistencyToken, err)
+	}
+	sequence, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return JournalPosition{}, fmt.Errorf("%w: %w", ErrInvalidConsistencyToken, err)
+	}
+	return JournalPosition{TransactionID: transactionID, Sequence: sequence}, nil
+}
+
+// positionRecorder collects the latest position of the events appended within a context.
+type positionRecorder struct {
+	mu       sync.Mutex
+	position JournalPosition
+	recorded bool
+}
+
+type positionRecorderCtxKey struct{}
+
+// NewContextWithPositionRecorder returns a context in which event stores record the position of appended events.
+// The latest position is returned by RecordedPosition.
+func NewContextWithPositionRecorder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, positionRecorderCtxKey{}, &positionRecorder{})
+}
+
+// RecordAppended records the latest position of the persisted events, if the context has a recorder.
+// Event stores must only call it once the events are committed.
+func RecordAppended(ctx context.Context, events [][]*JournalEvent) {
+	recorder, ok := ctx.Value(positionRecorderCtxKey{}).(*positionRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for _, intentEvents := range events {
+		for _, event := range intentEvents {
+			if !recorder.recorded || event.JournalPosition().After(recorder.position) {
+				recorder.position = event.JournalPosition()
+				recorder.recorded = true
+			}
+		}
+	}
+}
+
+// RecordedPosition returns the latest position of the events appended within the context.
+func RecordedPosition(ctx context.Context) (JournalPosition, bool) {
+	recorder, ok := ctx.Value(positionRecorderCtxKey{}).(*positionRecorder)
+	if !ok {
+		return JournalPosition{}, false
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.position, recorder.recorded
+}
+
+type minPositionCtxKey struct{}
+
+// MinPositionFromContext extracts the position that reads in the context have to observe.
+func MinPositionFromContext(ctx context.Context) (JournalPosition, bool) {
+	position, ok := ctx.Value(minPositionCtxKey{}).(JournalPosition)
+	return position, ok
+}
+
+// NewContextWithMinPosition adds the position that reads in the context have to observe.
+func NewContextWithMinPosition(ctx context.Context, position JournalPosition) context.Context {
+	return context.WithValue(ctx, minPositionCtxKey{}, position)
+}
+
+type notifiedPositionCtxKey struct{}
+
+// NotifiedPositionFromContext extracts the position of the event an [EventListener] is notified about.
+// It is only known if the notifier received it along with the notification.
+func NotifiedPositionFromContext(ctx context.Context) (JournalPosition, bool) {
+	position, ok := ctx.Value(notifiedPositionCtxKey{}).(JournalPosition)
+	return position, ok
+}
+
+// NewContextWithNotifiedPosition adds the position of the event listeners are notified about.
+func NewContextWithNotifiedPosition(ctx context.Context, position JournalPosition) context.Context {
+	return context.WithValue(ctx, notifiedPositionCtxKey{}, position)
+}
+
+// ProjectedPositions remembers how far this instance advanced the projections of a supervisor.
+// Supervisors use it to skip notifications about events an earlier run already projected.
+// The zero value is ready to use.
+type ProjectedPositions struct {
+	mu        sync.Mutex
+	positions map[ProjectionName]JournalPosition
+}
+
+// Advance records that the projection processed all of its events up to position.
+func (p *ProjectedPositions) Advance(projection ProjectionName, position JournalPosition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.positions == nil {
+		p.positions = make(map[ProjectionName]JournalPosition)
+	}
+	if position.After(p.positions[projection]) {
+		p.positions[projection] = position
+	}
+}
+
+// Covers reports whether the projection is known to have processed the event at position.
+func (p *ProjectedPositions) Covers(projection ProjectionName, position JournalPosition) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	projected, ok := p.positions[projection]
+	return ok && !position.After(projected)
+}
+
+// Forget removes the position of the projection, e.g. because it is replayed.
+func (p *ProjectedPositions) Forget(projection ProjectionName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.positions, projection)
+}
+
+// AwaitProjection blocks until the projection of projector reflects all of its events up to position.
+// current returns the position the projection has processed so far.
+//
+// A projection only advances on the events it is interested in, so its position might never reach the
+// requested one. It is therefore also considered consistent if its next event is after the position.
+func AwaitProjection(ctx context.Context, es EventStore, projector Projector, position JournalPosition, current func(ctx context.Context) (JournalPosition, error)) error {
+	ticker := time.NewTicker(awaitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		processed, err := current(ctx)
+		if err != nil {
+			return err
+		}
+		if !position.After(processed) {
+			return nil
+		}
+		query := NewJournalQueryBuilderFrom(projector.Query()).
+			WithJournalPositionAfter(processed).
+			WithLimit(1).
+			MustBuild()
+		pending, err := es.Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 || pending[0].JournalPosition().After(position) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
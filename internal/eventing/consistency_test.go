@@ -0,0 +1,47 @@
+package eventing
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseJournalPosition(t *testing.T) {
+	position := JournalPosition{TransactionID: 4711, Sequence: 42}
+	parsed, err := ParseJournalPosition(position.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed != position {
+		t.Errorf("expected %s, got %s", position, parsed)
+	}
+
+	for _, token := range []string{"", "4711", "4711:", ":42", "a:42", "4711:-1"} {
+		if _, err := ParseJournalPosition(token); !errors.Is(err, ErrInvalidConsistencyToken) {
+			t.Errorf("token %q: expected %v, got %v", token, ErrInvalidConsistencyToken, err)
+		}
+	}
+}
+
+func TestProjectedPositions(t *testing.T) {
+	var positions ProjectedPositions
+	if positions.Covers("trainings", JournalPosition{TransactionID: 1, Sequence: 1}) {
+		t.Errorf("expected an unknown projection to cover nothing")
+	}
+
+	positions.Advance("trainings", JournalPosition{TransactionID: 2, Sequence: 5})
+	positions.Advance("trainings", JournalPosition{TransactionID: 1, Sequence: 9})
+	if !positions.Covers("trainings", JournalPosition{TransactionID: 2, Sequence: 5}) {
+		t.Errorf("expected the projected position to be covered")
+	}
+	if !positions.Covers("trainings", JournalPosition{TransactionID: 1, Sequence: 9}) {
+		t.Errorf("expected an earlier position to be covered")
+	}
+	if positions.Covers("trainings", JournalPosition{TransactionID: 2, Sequence: 6}) {
+		t.Errorf("expected a later position not to be covered")
+	}
+
+	positions.Forget("trainings")
+	if positions.Covers("trainings", JournalPosition{TransactionID: 1, Sequence: 1}) {
+		t.Errorf("expected a forgotten projection to cover nothing")
+	}
+}
@@ -2,7 +2,11 @@ package eventing
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
+	"strings"
 )
 
 var (
@@ -22,9 +26,38 @@ func NewEncryptedString(value string) EncryptedString {
 	}
 }
 
+// PlaintextPrefix marks the value of an [EncryptedString] that has never been encrypted.
+// Such values come from events that stored the value in plaintext before it was encrypted
+// and are returned as is on decryption.
+const PlaintextPrefix = "plain:"
+
+// NewPlaintextEncryptedString wraps a value that was stored in plaintext by an earlier version of an event.
+// It is meant to be used by upcasters, which can't encrypt. Such values can't be shredded until the
+// events of the version are encrypted in the journal, see [PlaintextEventVersion].
+func NewPlaintextEncryptedString(value string) EncryptedString {
+	return NewEncryptedString(PlaintextPrefix + value)
+}
+
+// PlaintextEventVersion identifies a version of an event that stored values in plaintext, which its upcaster
+// wraps with [NewPlaintextEncryptedString].
+type PlaintextEventVersion struct {
+	AggregateType AggregateType
+	EventType     EventType
+	EventVersion  EventVersion
+}
+
+// CutPlaintext returns the value without the [PlaintextPrefix] and reports whether it was a plaintext value.
+func (e *EncryptedString) CutPlaintext() (string, bool) {
+	return strings.CutPrefix(e.Value, PlaintextPrefix)
+}
+
+// CryptoTransformer encrypts or decrypts the values of an event in place.
+// The field names a value within its event and is bound to the ciphertext together with the
+// aggregate ID and event type, so a value can't be copied to another field or event.
+// It therefore has to stay stable, even if the field is renamed by an upcaster.
 type CryptoTransformer interface {
-	Transform(owner AggregateID, value *EncryptedString) error
-	TransformWithDefault(owner AggregateID, value *EncryptedString, defaultValue string) error
+	Transform(owner AggregateID, field string, value *EncryptedString) error
+	TransformWithDefault(owner AggregateID, field string, value *EncryptedString, defaultValue string) error
 }
 
 type EncryptedEvent interface {
@@ -42,7 +75,41 @@ type EventDecrypter interface {
 	DecryptEvents(ctx context.Context, events []Event) error
 }
 
+type KeyShredder interface {
+	// ShredKeys deletes the keys of the owners, which irrecoverably shreds all of their encrypted values.
+	ShredKeys(ctx context.Context, owners ...AggregateID) error
+}
+
+// LookupHasher derives keyed hashes for lookup values and unique constraints that contain personal data.
+// Only the hash is stored, so the data can still be found by its exact value but not be read back.
+type LookupHasher interface {
+	HashLookupValue(field, value string) string
+}
+
+// HashedLookupPrefix marks values that have been hashed by a [LookupHasher].
+const HashedLookupPrefix = "hmac:"
+
+type hmacLookupHasher struct {
+	key []byte
+}
+
+// NewHMACLookupHasher creates a [LookupHasher] that uses HMAC-SHA256 with the given key.
+// The field is part of the hash, so equal values of different fields don't share a hash.
+func NewHMACLookupHasher(key []byte) LookupHasher {
+	return &hmacLookupHasher{key: key}
+}
+
+func (h *hmacLookupHasher) HashLookupValue(field, value string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return HashedLookupPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
 type EventCrypto interface {
 	EventEncryptor
 	EventDecrypter
+	KeyShredder
+	LookupHasher
 }
@@ -14,6 +14,12 @@ type Event interface {
 	IsShredded() bool
 }
 
+// Upcaster migrates the raw payload of an event from one version to the next.
+// Upcasters are declared next to their event as functions named <Event>V<n>ToV<n+1>,
+// e.g. PersonCreatedEventV1ToV2, and are chained by the generated registry.
+// Encrypted values are still encrypted when the payload is upcasted.
+type Upcaster func(payload []byte) ([]byte, error)
+
 type EventBase struct {
 	aggregateID   AggregateID
 	aggregateType AggregateType
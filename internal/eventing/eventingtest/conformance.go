@@ -0,0 +1,443 @@
+// Package eventingtest provides a conformance suite that every [eventing.EventStore] implementation must pass.
+package eventingtest
+
+import (
+	"context"
+	"errors"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"testing"
+)
+
+// Factory creates a new, empty event store for a single test.
+type Factory func(t *testing.T, mapper eventing.JournalEventMapper, crypto eventing.EventCrypto) eventing.EventStore
+
+// RunConformance runs the conformance suite against the event stores created by newStore.
+func RunConformance(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, es eventing.EventStore, crypto *crypto)
+	}{
+		{name: "append assigns sequential versions", run: testAppendVersions},
+		{name: "append enforces version matcher", run: testVersionMatcher},
+		{name: "append is atomic across intents", run: testAtomicIntents},
+		{name: "append rejects duplicate intents", run: testDuplicateIntents},
+		{name: "unique constraints", run: testUniqueConstraints},
+		{name: "lookups", run: testLookups},
+		{name: "sensitive lookups", run: testSensitiveLookups},
+		{name: "post persist hooks", run: testPostPersistHooks},
+		{name: "records appended position", run: testRecordAppended},
+		{name: "crypto", run: testCrypto},
+		{name: "metadata", run: testMetadata},
+		{name: "query filters", run: testQueryFilters},
+		{name: "query iter", run: testQueryIter},
+		{name: "view", run: testView},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			crypto := newCrypto()
+			tt.run(t, newStore(t, &registry{}, crypto), crypto)
+		})
+	}
+}
+
+func mustIntent(t *testing.T, id eventing.AggregateID, version eventing.AggregateVersion, matcher eventing.VersionMatcher, events ...eventing.Event) eventing.AggregateChangeIntent {
+	t.Helper()
+	intent, err := eventing.NewAggregateChangeIntent(id, aggregateType, version, events, matcher)
+	if err != nil {
+		t.Fatalf("failed to create intent: %v", err)
+	}
+	return intent
+}
+
+func mustAppend(t *testing.T, es eventing.EventStore, intents ...eventing.AggregateChangeIntent) [][]*eventing.JournalEvent {
+	t.Helper()
+	events, err := es.Append(context.Background(), intents...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return events
+}
+
+func mustQuery(t *testing.T, es eventing.EventStore, query eventing.JournalQuery) []*eventing.JournalEvent {
+	t.Helper()
+	events, err := es.Query(context.Background(), query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return events
+}
+
+func aggregateQuery(id eventing.AggregateID) eventing.JournalQuery {
+	var builder eventing.JournalQueryBuilder
+	return builder.WithAggregate(aggregateType).AggregateID(id).Finish().MustBuild()
+}
+
+func testAppendVersions(t *testing.T, es eventing.EventStore, _ *crypto) {
+	id := idgen.New[eventing.AggregateID]()
+	appended := mustAppend(t, es, mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "a"), newPlainEvent(id, "b")))
+	appended = append(appended, mustAppend(t, es, mustIntent(t, id, 2, eventing.VersionMatcherExact, newPlainEvent(id, "c")))...)
+
+	events := mustQuery(t, es, aggregateQuery(id))
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	for i, event := range events {
+		if event.AggregateVersion() != eventing.AggregateVersion(i+1) {
+			t.Errorf("event %d: expected version %d, got %d", i, i+1, event.AggregateVersion())
+		}
+		if i > 0 && !event.JournalPosition().After(events[i-1].JournalPosition()) {
+			t.Errorf("event %d: journal position should be strictly increasing", i)
+		}
+	}
+	if appended[1][0].EventID() != events[2].EventID() {
+		t.Errorf("expected appended event ID %s, got %s", appended[1][0].EventID(), events[2].EventID())
+	}
+	if value := events[2].Event.(*plainEvent).Value; value != "c" {
+		t.Errorf("expected value c, got %s", value)
+	}
+}
+
+func testVersionMatcher(t *testing.T, es eventing.EventStore, _ *crypto) {
+	id := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es, mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "a")))
+
+	_, err := es.Append(context.Background(), mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "b")))
+	if !errors.Is(err, eventing.ErrVersionMismatch) {
+		t.Errorf("expected %v, got %v", eventing.ErrVersionMismatch, err)
+	}
+
+	// The always matcher appends regardless of the known version.
+	mustAppend(t, es, mustIntent(t, id, 1, eventing.VersionMatcherAlways, newPlainEvent(id, "b")))
+	if events := mustQuery(t, es, aggregateQuery(id)); len(events) != 2 {
+		t.Errorf("expected 2 events, got %d", len(events))
+	}
+}
+
+func testAtomicIntents(t *testing.T, es eventing.EventStore, _ *crypto) {
+	idA := idgen.New[eventing.AggregateID]()
+	idB := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es, mustIntent(t, idB, 0, eventing.VersionMatcherExact, newPlainEvent(idB, "b")))
+
+	_, err := es.Append(context.Background(),
+		mustIntent(t, idA, 0, eventing.VersionMatcherExact, newPlainEvent(idA, "a"), newConstraintEvent(idA, "atomic")),
+		mustIntent(t, idB, 0, eventing.VersionMatcherExact, newPlainEvent(idB, "b")),
+	)
+	if !errors.Is(err, eventing.ErrVersionMismatch) {
+		t.Fatalf("expected %v, got %v", eventing.ErrVersionMismatch, err)
+	}
+	if events := mustQuery(t, es, aggregateQuery(idA)); len(events) != 0 {
+		t.Errorf("expected no events of the first intent, got %d", len(events))
+	}
+	// The unique constraint of the failed append must have been rolled back as well.
+	mustAppend(t, es, mustIntent(t, idB, 1, eventing.VersionMatcherExact, newConstraintEvent(idB, "atomic")))
+}
+
+func testDuplicateIntents(t *testing.T, es eventing.EventStore, _ *crypto) {
+	id := idgen.New[eventing.AggregateID]()
+	_, err := es.Append(context.Background(),
+		mustIntent(t, id, 0, eventing.VersionMatcherAlways, newPlainEvent(id, "a")),
+		mustIntent(t, id, 0, eventing.VersionMatcherAlways, newPlainEvent(id, "b")),
+	)
+	if !errors.Is(err, eventing.ErrDuplicateIntents) {
+		t.Errorf("expected %v, got %v", eventing.ErrDuplicateIntents, err)
+	}
+}
+
+func testUniqueConstraints(t *testing.T, es eventing.EventStore, _ *crypto) {
+	idA := idgen.New[eventing.AggregateID]()
+	idB := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es, mustIntent(t, idA, 0, eventing.VersionMatcherExact, newConstraintEvent(idA, "taken")))
+
+	// Constraints are case-insensitive.
+	_, err := es.Append(context.Background(), mustIntent(t, idB, 0, eventing.VersionMatcherExact, newConstraintEvent(idB, "TAKEN")))
+	expectedErr := eventing.NewUniqueConstraintError(eventing.NewUniqueConstraint(idB, "value", "TAKEN"))
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected %v, got %v", expectedErr, err)
+	}
+
+	// Once released, the value can be claimed by another aggregate.
+	mustAppend(t, es, mustIntent(t, idA, 1, eventing.VersionMatcherExact, newUnconstrainEvent(idA, "taken")))
+	mustAppend(t, es, mustIntent(t, idB, 0, eventing.VersionMatcherExact, newConstraintEvent(idB, "taken")))
+}
+
+func testLookups(t *testing.T, es eventing.EventStore, _ *crypto) {
+	ctx := context.Background()
+	id := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es, mustIntent(t, id, 0, eventing.VersionMatcherExact, newLookupEvent(id, "first")))
+	mustAppend(t, es, mustIntent(t, id, 1, eventing.VersionMatcherExact, newLookupEvent(id, "second")))
+
+	value, err := es.Lookup(ctx, eventing.LookupOpts{AggregateID: id, FieldName: "value"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *value != "second" {
+		t.Errorf("expected lookup value second, got %s", *value)
+	}
+	owner, err := es.OwnerLookup(ctx, eventing.LookupOpts{AggregateType: aggregateType, FieldName: "value", FieldValue: "second"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if owner != id {
+		t.Errorf("expected owner %s, got %s", id, owner)
+	}
+
+	mustAppend(t, es, mustIntent(t, id, 2, eventing.VersionMatcherExact, newUnlookupEvent(id)))
+	if _, err := es.Lookup(ctx, eventing.LookupOpts{AggregateID: id, FieldName: "value"}); !errors.Is(err, eventing.ErrValueNotFound) {
+		t.Errorf("expected %v, got %v", eventing.ErrValueNotFound, err)
+	}
+	if _, err := es.OwnerLookup(ctx, eventing.LookupOpts{AggregateType: aggregateType, FieldName: "value", FieldValue: "second"}); !errors.Is(err, eventing.ErrOwnerNotFound) {
+		t.Errorf("expected %v, got %v", eventing.ErrOwnerNotFound, err)
+	}
+}
+
+func testSensitiveLookups(t *testing.T, es eventing.EventStore, crypto *crypto) {
+	ctx := context.Background()
+	idA := idgen.New[eventing.AggregateID]()
+	idB := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es, mustIntent(t, idA, 0, eventing.VersionMatcherExact, newSensitiveEvent(idA, "Secret")))
+
+	// Only the hash is stored.
+	value, err := es.Lookup(ctx, eventing.LookupOpts{AggregateID: idA, FieldName: "sensitive"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := crypto.HashLookupValue("sensitive", "Secret"); string(*value) != expected {
+		t.Errorf("expected lookup value %s, got %s", expected, *value)
+	}
+	if _, err := es.OwnerLookup(ctx, eventing.LookupOpts{AggregateType: aggregateType, FieldName: "sensitive", FieldValue: "Secret"}); !errors.Is(err, eventing.ErrOwnerNotFound) {
+		t.Errorf("expected %v, got %v", eventing.ErrOwnerNotFound, err)
+	}
+	owner, err := es.OwnerLookup(ctx, eventing.LookupOpts{AggregateType: aggregateType, FieldName: "sensitive", FieldValue: "Secret", Sensitive: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if owner != idA {
+		t.Errorf("expected owner %s, got %s", idA, owner)
+	}
+
+	// Sensitive constraints stay case-insensitive.
+	_, err = es.Append(ctx, mustIntent(t, idB, 0, eventing.VersionMatcherExact, newSensitiveEvent(idB, "SECRET")))
+	expectedErr := eventing.NewUniqueConstraintError(eventing.NewUniqueConstraint(idB, "sensitive", crypto.HashLookupValue("sensitive", "secret")))
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected %v, got %v", expectedErr, err)
+	}
+}
+
+func testPostPersistHooks(t *testing.T, es eventing.EventStore, _ *crypto) {
+	var calls int
+	es.AddHook(eventing.NewPostPersistHook(func(ctx context.Context) error {
+		calls++
+		return nil
+	}))
+
+	id := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es, mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "a")))
+	if calls != 1 {
+		t.Errorf("expected 1 hook call, got %d", calls)
+	}
+
+	// Failed appends do not run the hooks.
+	_, _ = es.Append(context.Background(), mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "b")))
+	if calls != 1 {
+		t.Errorf("expected 1 hook call, got %d", calls)
+	}
+}
+
+func testRecordAppended(t *testing.T, es eventing.EventStore, _ *crypto) {
+	ctx := eventing.NewContextWithPositionRecorder(context.Background())
+	if _, ok := eventing.RecordedPosition(ctx); ok {
+		t.Fatal("expected no position before appending")
+	}
+
+	id := idgen.New[eventing.AggregateID]()
+	appended, err := es.Append(ctx, mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "a"), newPlainEvent(id, "b")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	position, ok := eventing.RecordedPosition(ctx)
+	if !ok {
+		t.Fatal("expected a recorded position")
+	}
+	if expected := appended[0][1].JournalPosition(); position != expected {
+		t.Errorf("expected position %s, got %s", expected, position)
+	}
+
+	// Failed appends do not move the position.
+	_, _ = es.Append(ctx, mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "c")))
+	if after, _ := eventing.RecordedPosition(ctx); after != position {
+		t.Errorf("expected position %s, got %s", position, after)
+	}
+}
+
+func testCrypto(t *testing.T, es eventing.EventStore, crypto *crypto) {
+	id := idgen.New[eventing.AggregateID]()
+	appended := mustAppend(t, es, mustIntent(t, id, 0, eventing.VersionMatcherExact, newEncryptedEvent(id, "secret")))
+	if secret := appended[0][0].Event.(*encryptedEvent).Secret.Value; secret != "secret" {
+		t.Errorf("expected appended event to be decrypted, got %s", secret)
+	}
+
+	events := mustQuery(t, es, aggregateQuery(id))
+	if secret := events[0].Event.(*encryptedEvent).Secret.Value; secret != "secret" {
+		t.Errorf("expected queried event to be decrypted, got %s", secret)
+	}
+
+	if err := crypto.ShredKeys(context.Background(), id); err != nil {
+		t.Fatalf("failed to shred keys: %v", err)
+	}
+	events = mustQuery(t, es, aggregateQuery(id))
+	if !events[0].IsShredded() {
+		t.Errorf("expected event to be shredded")
+	}
+}
+
+func testMetadata(t *testing.T, es eventing.EventStore, crypto *crypto) {
+	id := idgen.New[eventing.AggregateID]()
+	principal := idgen.New[eventing.AggregateID]()
+	md := eventing.Metadata{
+		CorrelationID: "correlation",
+		CausationID:   "causation",
+		PrincipalID:   string(principal),
+		ClientIP:      "127.0.0.1",
+		UserAgent:     "test",
+	}
+	ctx := eventing.NewContextWithMetadata(context.Background(), md)
+	appended, err := es.Append(ctx, mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "a"), newPlainEvent(id, "b")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, event := range appended[0] {
+		if got := event.Metadata(); got == nil || *got != md {
+			t.Errorf("expected appended metadata %v, got %v", md, got)
+		}
+	}
+	for _, event := range mustQuery(t, es, aggregateQuery(id)) {
+		if got := event.Metadata(); got == nil || *got != md {
+			t.Errorf("expected queried metadata %v, got %v", md, got)
+		}
+	}
+
+	// The personal data of the metadata belongs to the principal.
+	if err := crypto.ShredKeys(context.Background(), principal); err != nil {
+		t.Fatalf("failed to shred keys: %v", err)
+	}
+	shredded := md
+	shredded.ClientIP = ""
+	shredded.UserAgent = ""
+	for _, event := range mustQuery(t, es, aggregateQuery(id)) {
+		if got := event.Metadata(); got == nil || *got != shredded {
+			t.Errorf("expected shredded metadata %v, got %v", shredded, got)
+		}
+	}
+}
+
+func testQueryFilters(t *testing.T, es eventing.EventStore, _ *crypto) {
+	idA := idgen.New[eventing.AggregateID]()
+	idB := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es,
+		mustIntent(t, idA, 0, eventing.VersionMatcherExact, newPlainEvent(idA, "a1"), newLookupEvent(idA, "a2"), newPlainEvent(idA, "a3")),
+		mustIntent(t, idB, 0, eventing.VersionMatcherExact, newPlainEvent(idB, "b1")),
+	)
+
+	var builder eventing.JournalQueryBuilder
+	byEvent := builder.WithAggregate(aggregateType).AggregateID(idA).Events(plainEventType).Finish().MustBuild()
+	if events := mustQuery(t, es, byEvent); len(events) != 2 {
+		t.Errorf("expected 2 events by type, got %d", len(events))
+	}
+
+	byVersion := eventing.NewJournalQueryBuilderFrom(aggregateQuery(idA)).
+		WithAggregate(aggregateType).AggregateID(idA).AggregateVersionAtLeast(2).Finish().MustBuild()
+	if events := mustQuery(t, es, byVersion); len(events) != 2 {
+		t.Errorf("expected 2 events by version, got %d", len(events))
+	}
+
+	all := mustQuery(t, es, eventing.NewJournalQueryBuilderFrom(aggregateQuery("")).MustBuild())
+	if len(all) != 4 {
+		t.Fatalf("expected 4 events of the aggregate type, got %d", len(all))
+	}
+	afterPos := eventing.NewJournalQueryBuilderFrom(aggregateQuery("")).WithJournalPositionAfter(all[1].JournalPosition()).MustBuild()
+	if events := mustQuery(t, es, afterPos); len(events) != 2 || events[0].EventID() != all[2].EventID() {
+		t.Errorf("expected the 2 events after the position, got %d", len(events))
+	}
+	limited := eventing.NewJournalQueryBuilderFrom(aggregateQuery("")).WithLimit(3).MustBuild()
+	if events := mustQuery(t, es, limited); len(events) != 3 {
+		t.Errorf("expected 3 limited events, got %d", len(events))
+	}
+
+	// Counting applies the same filters but ignores the limit.
+	for _, tt := range []struct {
+		query    eventing.JournalQuery
+		expected int
+	}{
+		{byEvent, 2},
+		{afterPos, 2},
+		{limited, 4},
+	} {
+		count, err := es.Count(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != tt.expected {
+			t.Errorf("expected count %d, got %d", tt.expected, count)
+		}
+	}
+}
+
+func testQueryIter(t *testing.T, es eventing.EventStore, _ *crypto) {
+	id := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es, mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "a"), newPlainEvent(id, "b"), newPlainEvent(id, "c")))
+
+	var count int
+	for event, err := range es.QueryIter(context.Background(), aggregateQuery(id)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+		if event.AggregateVersion() != eventing.AggregateVersion(count) {
+			t.Errorf("expected version %d, got %d", count, event.AggregateVersion())
+		}
+		// Stopping early must be supported.
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("expected to stop after 2 events, got %d", count)
+	}
+}
+
+type countingView struct {
+	eventing.BaseWriter
+
+	id    eventing.AggregateID
+	count int
+}
+
+func (v *countingView) Query() eventing.JournalQuery {
+	return aggregateQuery(v.id)
+}
+
+func (v *countingView) Reduce(events []*eventing.JournalEvent) {
+	v.count += len(events)
+	v.BaseWriter.Reduce(events)
+}
+
+func testView(t *testing.T, es eventing.EventStore, _ *crypto) {
+	id := idgen.New[eventing.AggregateID]()
+	mustAppend(t, es, mustIntent(t, id, 0, eventing.VersionMatcherExact, newPlainEvent(id, "a"), newPlainEvent(id, "b")))
+
+	view := &countingView{
+		BaseWriter: *eventing.NewBaseWriter(id, aggregateType, eventing.VersionMatcherExact),
+		id:         id,
+	}
+	if err := es.View(context.Background(), view); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if view.count != 2 {
+		t.Errorf("expected 2 events, got %d", view.count)
+	}
+	if view.Aggregate().Version != 2 {
+		t.Errorf("expected version 2, got %d", view.Aggregate().Version)
+	}
+}
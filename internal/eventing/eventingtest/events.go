@@ -0,0 +1,308 @@
+package eventingtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	aggregateType = eventing.AggregateType("conformance")
+
+	plainEventType       = eventing.EventType("plain")
+	encryptedEventType   = eventing.EventType("encrypted")
+	constraintEventType  = eventing.EventType("constraint")
+	unconstrainEventType = eventing.EventType("unconstrain")
+	lookupEventType      = eventing.EventType("lookup")
+	unlookupEventType    = eventing.EventType("unlookup")
+	sensitiveEventType   = eventing.EventType("sensitive")
+
+	eventVersion = eventing.EventVersion("v1")
+)
+
+var (
+	_ eventing.JournalEventMapper      = (*registry)(nil)
+	_ eventing.EncryptedEvent          = (*encryptedEvent)(nil)
+	_ eventing.UniqueConstraintAdder   = (*constraintEvent)(nil)
+	_ eventing.UniqueConstraintRemover = (*unconstrainEvent)(nil)
+	_ eventing.LookupProvider          = (*lookupEvent)(nil)
+	_ eventing.LookupRemover           = (*unlookupEvent)(nil)
+	_ eventing.SensitiveLookupProvider = (*sensitiveEvent)(nil)
+)
+
+type plainEvent struct {
+	*eventing.EventBase
+
+	Value string `json:"value"`
+}
+
+func newPlainEvent(id eventing.AggregateID, value string) *plainEvent {
+	return &plainEvent{
+		EventBase: eventing.NewEventBase(id, aggregateType, eventVersion, plainEventType),
+		Value:     value,
+	}
+}
+
+func (e *plainEvent) IsShredded() bool {
+	return false
+}
+
+type encryptedEvent struct {
+	*eventing.EventBase
+
+	Secret eventing.EncryptedString `json:"secret"`
+}
+
+func newEncryptedEvent(id eventing.AggregateID, secret string) *encryptedEvent {
+	return &encryptedEvent{
+		EventBase: eventing.NewEventBase(id, aggregateType, eventVersion, encryptedEventType),
+		Secret:    eventing.NewEncryptedString(secret),
+	}
+}
+
+func (e *encryptedEvent) IsShredded() bool {
+	return e.Secret.IsShredded
+}
+
+func (e *encryptedEvent) DeclareOwners() []eventing.AggregateID {
+	return []eventing.AggregateID{e.AggregateID()}
+}
+
+func (e *encryptedEvent) AcceptCrypto(transformer eventing.CryptoTransformer) error {
+	return transformer.Transform(e.AggregateID(), "secret", &e.Secret)
+}
+
+type constraintEvent struct {
+	*eventing.EventBase
+
+	Value string `json:"value"`
+}
+
+func newConstraintEvent(id eventing.AggregateID, value string) *constraintEvent {
+	return &constraintEvent{
+		EventBase: eventing.NewEventBase(id, aggregateType, eventVersion, constraintEventType),
+		Value:     value,
+	}
+}
+
+func (e *constraintEvent) IsShredded() bool {
+	return false
+}
+
+func (e *constraintEvent) UniqueConstraintsToAdd() []eventing.UniqueConstraint {
+	return []eventing.UniqueConstraint{eventing.NewUniqueConstraint(e.AggregateID(), "value", e.Value)}
+}
+
+type unconstrainEvent struct {
+	*eventing.EventBase
+
+	Value string `json:"value"`
+}
+
+func newUnconstrainEvent(id eventing.AggregateID, value string) *unconstrainEvent {
+	return &unconstrainEvent{
+		EventBase: eventing.NewEventBase(id, aggregateType, eventVersion, unconstrainEventType),
+		Value:     value,
+	}
+}
+
+func (e *unconstrainEvent) IsShredded() bool {
+	return false
+}
+
+func (e *unconstrainEvent) UniqueConstraintsToRemove() []eventing.UniqueConstraint {
+	return []eventing.UniqueConstraint{eventing.NewUniqueConstraint(e.AggregateID(), "value", e.Value)}
+}
+
+type lookupEvent struct {
+	*eventing.EventBase
+
+	Value string `json:"value"`
+}
+
+func newLookupEvent(id eventing.AggregateID, value string) *lookupEvent {
+	return &lookupEvent{
+		EventBase: eventing.NewEventBase(id, aggregateType, eventVersion, lookupEventType),
+		Value:     value,
+	}
+}
+
+func (e *lookupEvent) IsShredded() bool {
+	return false
+}
+
+func (e *lookupEvent) LookupValues() eventing.LookupMap {
+	return eventing.LookupMap{"value": eventing.LookupFieldValue(e.Value)}
+}
+
+type unlookupEvent struct {
+	*eventing.EventBase
+}
+
+func newUnlookupEvent(id eventing.AggregateID) *unlookupEvent {
+	return &unlookupEvent{
+		EventBase: eventing.NewEventBase(id, aggregateType, eventVersion, unlookupEventType),
+	}
+}
+
+func (e *unlookupEvent) IsShredded() bool {
+	return false
+}
+
+func (e *unlookupEvent) LookupRemoves() []eventing.LookupFieldName {
+	return []eventing.LookupFieldName{"value"}
+}
+
+type sensitiveEvent struct {
+	*eventing.EventBase
+
+	Value string `json:"value"`
+}
+
+func newSensitiveEvent(id eventing.AggregateID, value string) *sensitiveEvent {
+	return &sensitiveEvent{
+		EventBase: eventing.NewEventBase(id, aggregateType, eventVersion, sensitiveEventType),
+		Value:     value,
+	}
+}
+
+func (e *sensitiveEvent) IsShredded() bool {
+	return false
+}
+
+func (e *sensitiveEvent) SensitiveLookupValues() eventing.LookupMap {
+	return eventing.LookupMap{"sensitive": eventing.LookupFieldValue(e.Value)}
+}
+
+func (e *sensitiveEvent) UniqueConstraintsToAdd() []eventing.UniqueConstraint {
+	return []eventing.UniqueConstraint{eventing.NewSensitiveUniqueConstraint(e.AggregateID(), "sensitive", e.Value)}
+}
+
+// registry maps the conformance events.
+type registry struct{}
+
+func (*registry) MapFrom(
+	aggregateID eventing.AggregateID,
+	aggregateType eventing.AggregateType,
+	eventVersion eventing.EventVersion,
+	eventType eventing.EventType,
+	eventID eventing.EventID,
+	aggregateVersion eventing.AggregateVersion,
+	journalPosition eventing.JournalPosition,
+	insertedAt time.Time,
+	payload []byte,
+) (*eventing.JournalEvent, error) {
+	base := eventing.NewEventBase(aggregateID, aggregateType, eventVersion, eventType)
+
+	var event eventing.Event
+	switch eventType {
+	case plainEventType:
+		event = &plainEvent{EventBase: base}
+	case encryptedEventType:
+		event = &encryptedEvent{EventBase: base}
+	case constraintEventType:
+		event = &constraintEvent{EventBase: base}
+	case unconstrainEventType:
+		event = &unconstrainEvent{EventBase: base}
+	case lookupEventType:
+		event = &lookupEvent{EventBase: base}
+	case unlookupEventType:
+		event = &unlookupEvent{EventBase: base}
+	case sensitiveEventType:
+		event = &sensitiveEvent{EventBase: base}
+	default:
+		return nil, errors.New("event not registered")
+	}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return eventing.NewJournalEvent(event, eventID, aggregateVersion, journalPosition, insertedAt), nil
+}
+
+const cipherPrefix = "enc:"
+
+// crypto is a reversible fake [eventing.EventCrypto] that supports shredding of owners.
+type crypto struct {
+	eventing.LookupHasher
+
+	mu       sync.Mutex
+	shredded map[eventing.AggregateID]struct{}
+}
+
+func newCrypto() *crypto {
+	return &crypto{
+		LookupHasher: eventing.NewHMACLookupHasher([]byte("lookup")),
+		shredded:     make(map[eventing.AggregateID]struct{}),
+	}
+}
+
+func (c *crypto) ShredKeys(ctx context.Context, owners ...eventing.AggregateID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, owner := range owners {
+		c.shredded[owner] = struct{}{}
+	}
+	return nil
+}
+
+func (c *crypto) EncryptEvents(ctx context.Context, events []eventing.Event) error {
+	return c.transform(events, func(owner eventing.AggregateID, value *eventing.EncryptedString) error {
+		value.Value = cipherPrefix + value.Value
+		return nil
+	})
+}
+
+func (c *crypto) DecryptEvents(ctx context.Context, events []eventing.Event) error {
+	return c.transform(events, func(owner eventing.AggregateID, value *eventing.EncryptedString) error {
+		if plaintext, ok := value.CutPlaintext(); ok {
+			value.Value = plaintext
+			return nil
+		}
+		if _, ok := c.shredded[owner]; ok {
+			value.IsShredded = true
+			value.Value = ""
+			return nil
+		}
+		if !strings.HasPrefix(value.Value, cipherPrefix) {
+			return fmt.Errorf("value is not encrypted: %q", value.Value)
+		}
+		value.Value = strings.TrimPrefix(value.Value, cipherPrefix)
+		return nil
+	})
+}
+
+func (c *crypto) transform(events []eventing.Event, fn transformerFunc) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, event := range events {
+		encrypted, ok := event.(eventing.EncryptedEvent)
+		if !ok {
+			continue
+		}
+		if err := encrypted.AcceptCrypto(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type transformerFunc func(owner eventing.AggregateID, value *eventing.EncryptedString) error
+
+func (f transformerFunc) Transform(owner eventing.AggregateID, _ string, value *eventing.EncryptedString) error {
+	return f(owner, value)
+}
+
+func (f transformerFunc) TransformWithDefault(owner eventing.AggregateID, _ string, value *eventing.EncryptedString, defaultValue string) error {
+	if err := f(owner, value); err != nil {
+		return err
+	}
+	if value.IsShredded {
+		value.Value = defaultValue
+	}
+	return nil
+}
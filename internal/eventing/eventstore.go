@@ -2,14 +2,17 @@
 package eventing
 
 import (
+	"cmp"
 	"context"
 	"errors"
-	"github.com/shopspring/decimal"
+	"iter"
+	"strconv"
 )
 
 var (
-	ErrVersionMismatch = errors.New("version mismatch")
-	ErrIntentOutdated  = errors.New("intent is outdated")
+	ErrVersionMismatch  = errors.New("version mismatch")
+	ErrIntentOutdated   = errors.New("intent is outdated")
+	ErrDuplicateIntents = errors.New("multiple intents target the same aggregate")
 )
 
 type (
@@ -19,27 +22,58 @@ type (
 	EventVersion     string
 	EventType        string
 	EventID          string
-	JournalPosition  decimal.Decimal
 )
 
 func (a AggregateID) Deref() string {
 	return string(a)
 }
 
-func (j JournalPosition) Deref() decimal.Decimal {
-	return decimal.Decimal(j)
+// JournalPosition is the position of an event in the journal.
+// Events are ordered by the ID of the transaction that appended them first and their sequence second.
+// As transaction IDs are only read once all older transactions completed, the position never skips events.
+type JournalPosition struct {
+	TransactionID uint64 `json:"transaction_id"`
+	Sequence      uint64 `json:"sequence"`
+}
+
+// Compare returns -1, 0 or +1 depending on whether j is before, equal to or after other.
+func (j JournalPosition) Compare(other JournalPosition) int {
+	if c := cmp.Compare(j.TransactionID, other.TransactionID); c != 0 {
+		return c
+	}
+	return cmp.Compare(j.Sequence, other.Sequence)
+}
+
+// After reports whether j is after other.
+func (j JournalPosition) After(other JournalPosition) bool {
+	return j.Compare(other) > 0
+}
+
+func (j JournalPosition) String() string {
+	return strconv.FormatUint(j.TransactionID, 10) + ":" + strconv.FormatUint(j.Sequence, 10)
 }
 
 type EventStore interface {
 	// Append is used to append events to the event store.
-	Append(ctx context.Context, intents ...AggregateChangeIntent) ([]*JournalEvent, error)
+	// All intents are committed atomically, either all of them are persisted or none.
+	// The persisted events are returned grouped by intent, in the order the intents were passed.
+	Append(ctx context.Context, intents ...AggregateChangeIntent) ([][]*JournalEvent, error)
 
-	// ProduceAppend is used to produce events to the event store and apply them to the producer.
-	ProduceAppend(ctx context.Context, producer Writer) error
+	// ProduceAppend is used to produce events to the event store and apply them to the producers.
+	// The changes of all producers are appended atomically.
+	ProduceAppend(ctx context.Context, producers ...Writer) error
 
 	// Query is used to query events from the event store.
 	Query(ctx context.Context, query JournalQuery, opts ...QueryOpts) ([]*JournalEvent, error)
 
+	// Count returns the number of events matching the query, ignoring its limit.
+	Count(ctx context.Context, query JournalQuery, opts ...QueryOpts) (int, error)
+
+	// QueryIter is used to stream events from the event store.
+	// The events are fetched in pages, so only a bounded number of events is held in memory.
+	// Iteration stops after the first error.
+	QueryIter(ctx context.Context, query JournalQuery, opts ...QueryOpts) iter.Seq2[*JournalEvent, error]
+
 	// View is used to query and reduce events into a view.
 	View(ctx context.Context, view JournalViewer) error
 
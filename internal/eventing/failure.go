@@ -0,0 +1,218 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrProjectionFailureNotFound = errors.New("projection failure not found")
+
+// FailureAction is what a supervisor does when its projector fails on an event.
+type FailureAction int
+
+const (
+	// FailureActionRetry retries the event with backoff until it succeeds.
+	// Later events are not projected in the meantime.
+	FailureActionRetry FailureAction = iota
+
+	// FailureActionDeadLetter retries the event with backoff and skips it after FailurePolicy.MaxAttempts.
+	// The skipped event is kept as dead letter, so that it can be re-driven once the projector is fixed.
+	FailureActionDeadLetter
+
+	// FailureActionHalt stops the projection on the first failure until the event is re-driven.
+	FailureActionHalt
+)
+
+// FailurePolicy describes how a supervisor handles events that its projector fails on.
+type FailurePolicy struct {
+	Action FailureAction
+
+	// MaxAttempts is the number of attempts before an event is dead-lettered.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry. It is doubled with every further attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultFailurePolicy is used for projectors that don't implement [FailurePolicyProjector].
+var DefaultFailurePolicy = FailurePolicy{
+	Action:     FailureActionRetry,
+	MinBackoff: time.Second,
+	MaxBackoff: 10 * time.Minute,
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts.
+func (p FailurePolicy) Backoff(attempts int) time.Duration {
+	backoff := p.MinBackoff
+	for i := 1; i < attempts && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.MaxBackoff)
+}
+
+// FailurePolicyProjector is implemented by projectors that handle failing events differently than the DefaultFailurePolicy.
+type FailurePolicyProjector interface {
+	Projector
+
+	FailurePolicy() FailurePolicy
+}
+
+// FailurePolicyOf returns the failure policy of the projector.
+func FailurePolicyOf(projector Projector) FailurePolicy {
+	if p, ok := projector.(FailurePolicyProjector); ok {
+		return p.FailurePolicy()
+	}
+	return DefaultFailurePolicy
+}
+
+// FailureState is the state of a ProjectionFailure.
+type FailureState string
+
+const (
+	// FailureStateRetrying holds back the projection until the next attempt.
+	FailureStateRetrying FailureState = "retrying"
+	// FailureStateDeadLettered has been skipped by the projection.
+	FailureStateDeadLettered FailureState = "dead_lettered"
+	// FailureStateHalted holds back the projection until the event is re-driven.
+	FailureStateHalted FailureState = "halted"
+)
+
+// ProjectionFailure is an event that a projector failed to project.
+type ProjectionFailure struct {
+	Projection       ProjectionName
+	EventID          EventID
+	EventType        EventType
+	AggregateID      AggregateID
+	AggregateType    AggregateType
+	AggregateVersion AggregateVersion
+	Position         JournalPosition
+
+	State         FailureState
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// ProjectionFailureStore keeps track of the events that projectors failed on.
+type ProjectionFailureStore interface {
+	// Record records a failed attempt of projecting the event and moves the failure into the state the policy demands.
+	Record(ctx context.Context, projection ProjectionName, event *JournalEvent, cause error, policy FailurePolicy) (*ProjectionFailure, error)
+
+	// Blocked reports whether a failure holds back the projection, because it is halted or waits for the next attempt.
+	Blocked(ctx context.Context, projection ProjectionName) (bool, error)
+
+	// Resolve removes the retried failures of the projection once it advanced past them.
+	Resolve(ctx context.Context, projection ProjectionName) error
+
+	// List returns the failures of the projection, or of all projections if projection is empty.
+	List(ctx context.Context, projection ProjectionName) ([]*ProjectionFailure, error)
+
+	// Get returns the failure of the event or [ErrProjectionFailureNotFound].
+	Get(ctx context.Context, projection ProjectionName, eventID EventID) (*ProjectionFailure, error)
+
+	// Delete removes the failure of the event.
+	Delete(ctx context.Context, projection ProjectionName, eventID EventID) error
+
+	// Clear removes all failures of the projection.
+	Clear(ctx context.Context, projection ProjectionName) error
+}
+
+// NextFailureState returns the state of a failure after the given number of attempts.
+func NextFailureState(policy FailurePolicy, attempts int) FailureState {
+	switch policy.Action {
+	case FailureActionHalt:
+		return FailureStateHalted
+	case FailureActionDeadLetter:
+		if attempts >= policy.MaxAttempts {
+			return FailureStateDeadLettered
+		}
+	}
+	return FailureStateRetrying
+}
+
+// ProjectWithPolicy projects the events and applies the failure policy of the projector to the event that fails.
+// project has to apply either all or none of the passed events, or be idempotent, as events are projected
+// once more one at a time to find the failing event.
+// Returns the number of leading events that have been handled, i.e. either projected or dead-lettered.
+// The state of the projection has to be advanced to the last of them, even if an error is returned.
+func ProjectWithPolicy(
+	ctx context.Context,
+	failures ProjectionFailureStore,
+	projector Projector,
+	events []*JournalEvent,
+	project func(ctx context.Context, events ...*JournalEvent) error,
+) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	projection := projector.Projection()
+	if err := project(ctx, events...); err == nil {
+		return len(events), failures.Resolve(ctx, projection)
+	}
+
+	policy := FailurePolicyOf(projector)
+	for i, event := range events {
+		err := project(ctx, event)
+		if err == nil {
+			continue
+		}
+		failure, recordErr := failures.Record(ctx, projection, event, err, policy)
+		if recordErr != nil {
+			return i, errors.Join(err, recordErr)
+		}
+		if failure.State != FailureStateDeadLettered {
+			return i, fmt.Errorf("failed to project event %s (attempt %d): %w", event.EventID(), failure.Attempts, err)
+		}
+	}
+	return len(events), failures.Resolve(ctx, projection)
+}
+
+// RedriveFailure projects the event of a failure once more.
+// Retrying and halted failures still hold back the projection, so they are only removed and the event is
+// projected with the next run. Dead-lettered events have been skipped already and are projected out of order.
+// The caller must prevent the projection from being advanced concurrently.
+func RedriveFailure(
+	ctx context.Context,
+	es EventStore,
+	failures ProjectionFailureStore,
+	projector Projector,
+	eventID EventID,
+	project func(ctx context.Context, events ...*JournalEvent) error,
+) error {
+	projection := projector.Projection()
+	failure, err := failures.Get(ctx, projection, eventID)
+	if err != nil {
+		return err
+	}
+	if failure.State != FailureStateDeadLettered {
+		return failures.Delete(ctx, projection, eventID)
+	}
+
+	var builder JournalQueryBuilder
+	query := builder.
+		WithAggregate(failure.AggregateType).
+		AggregateID(failure.AggregateID).
+		AggregateVersionAtLeast(failure.AggregateVersion).
+		Events(failure.EventType).
+		Finish().
+		WithLimit(1).
+		MustBuild()
+	events, err := es.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 || events[0].EventID() != eventID {
+		return fmt.Errorf("event %s not found in journal", eventID)
+	}
+	if err := project(ctx, events[0]); err != nil {
+		// Count the attempt, but keep the event dead-lettered.
+		_, recordErr := failures.Record(ctx, projection, events[0], err, FailurePolicy{Action: FailureActionDeadLetter})
+		return errors.Join(fmt.Errorf("failed to redrive event %s: %w", eventID, err), recordErr)
+	}
+	return failures.Delete(ctx, projection, eventID)
+}
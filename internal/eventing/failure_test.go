@@ -0,0 +1,168 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testFailureProjector struct {
+	policy   FailurePolicy
+	poison   EventID
+	projects int
+}
+
+func (p *testFailureProjector) Query() JournalQuery {
+	return JournalQuery{}
+}
+
+func (p *testFailureProjector) Init(ctx context.Context) error {
+	return nil
+}
+
+func (p *testFailureProjector) Projection() ProjectionName {
+	return "test"
+}
+
+func (p *testFailureProjector) Project(ctx context.Context, events ...*JournalEvent) error {
+	p.projects++
+	for _, event := range events {
+		if event.EventID() == p.poison {
+			return errors.New("poison")
+		}
+	}
+	return nil
+}
+
+func (p *testFailureProjector) FailurePolicy() FailurePolicy {
+	return p.policy
+}
+
+// testFailureStore keeps the failures of a single projection.
+type testFailureStore struct {
+	failures map[EventID]*ProjectionFailure
+	resolved int
+}
+
+func (s *testFailureStore) Record(ctx context.Context, projection ProjectionName, event *JournalEvent, cause error, policy FailurePolicy) (*ProjectionFailure, error) {
+	failure, ok := s.failures[event.EventID()]
+	if !ok {
+		failure = &ProjectionFailure{Projection: projection, EventID: event.EventID()}
+		s.failures[event.EventID()] = failure
+	}
+	failure.Attempts++
+	failure.State = NextFailureState(policy, failure.Attempts)
+	failure.LastError = cause.Error()
+	return failure, nil
+}
+
+func (s *testFailureStore) Blocked(ctx context.Context, projection ProjectionName) (bool, error) {
+	return false, nil
+}
+
+func (s *testFailureStore) Resolve(ctx context.Context, projection ProjectionName) error {
+	s.resolved++
+	return nil
+}
+
+func (s *testFailureStore) List(ctx context.Context, projection ProjectionName) ([]*ProjectionFailure, error) {
+	return nil, nil
+}
+
+func (s *testFailureStore) Get(ctx context.Context, projection ProjectionName, eventID EventID) (*ProjectionFailure, error) {
+	return s.failures[eventID], nil
+}
+
+func (s *testFailureStore) Delete(ctx context.Context, projection ProjectionName, eventID EventID) error {
+	delete(s.failures, eventID)
+	return nil
+}
+
+func (s *testFailureStore) Clear(ctx context.Context, projection ProjectionName) error {
+	clear(s.failures)
+	return nil
+}
+
+func TestFailurePolicy_Backoff(t *testing.T) {
+	policy := FailurePolicy{MinBackoff: time.Second, MaxBackoff: time.Minute}
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: time.Second},
+		{attempts: 3, expected: 4 * time.Second},
+		{attempts: 100, expected: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempts); got != tt.expected {
+			t.Errorf("expected backoff %s after %d attempts, got %s", tt.expected, tt.attempts, got)
+		}
+	}
+}
+
+func TestProjectWithPolicy(t *testing.T) {
+	ctx := context.Background()
+	events := []*JournalEvent{
+		NewJournalEvent(nil, "a", 1, JournalPosition{1, 1}, time.Now()),
+		NewJournalEvent(nil, "b", 2, JournalPosition{1, 2}, time.Now()),
+		NewJournalEvent(nil, "c", 3, JournalPosition{1, 3}, time.Now()),
+	}
+
+	t.Run("batch succeeds", func(t *testing.T) {
+		projector := &testFailureProjector{policy: DefaultFailurePolicy}
+		store := &testFailureStore{failures: make(map[EventID]*ProjectionFailure)}
+		handled, err := ProjectWithPolicy(ctx, store, projector, events, projector.Project)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if handled != 3 || projector.projects != 1 || store.resolved != 1 {
+			t.Errorf("expected 3 events handled in 1 run, got %d in %d", handled, projector.projects)
+		}
+	})
+
+	t.Run("retry stops before poison event", func(t *testing.T) {
+		projector := &testFailureProjector{policy: DefaultFailurePolicy, poison: "b"}
+		store := &testFailureStore{failures: make(map[EventID]*ProjectionFailure)}
+		handled, err := ProjectWithPolicy(ctx, store, projector, events, projector.Project)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if handled != 1 {
+			t.Errorf("expected 1 handled event, got %d", handled)
+		}
+		if failure := store.failures["b"]; failure == nil || failure.State != FailureStateRetrying {
+			t.Errorf("expected retrying failure of b, got %+v", failure)
+		}
+	})
+
+	t.Run("dead letter skips poison event after max attempts", func(t *testing.T) {
+		projector := &testFailureProjector{policy: FailurePolicy{Action: FailureActionDeadLetter, MaxAttempts: 2}, poison: "b"}
+		store := &testFailureStore{failures: make(map[EventID]*ProjectionFailure)}
+		if handled, err := ProjectWithPolicy(ctx, store, projector, events, projector.Project); err == nil || handled != 1 {
+			t.Fatalf("expected first attempt to fail after 1 handled event, got %d: %v", handled, err)
+		}
+		handled, err := ProjectWithPolicy(ctx, store, projector, events[1:], projector.Project)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if handled != 2 {
+			t.Errorf("expected 2 handled events, got %d", handled)
+		}
+		if failure := store.failures["b"]; failure == nil || failure.State != FailureStateDeadLettered || failure.Attempts != 2 {
+			t.Errorf("expected dead-lettered failure of b after 2 attempts, got %+v", failure)
+		}
+	})
+
+	t.Run("halt on first failure", func(t *testing.T) {
+		projector := &testFailureProjector{policy: FailurePolicy{Action: FailureActionHalt}, poison: "a"}
+		store := &testFailureStore{failures: make(map[EventID]*ProjectionFailure)}
+		handled, err := ProjectWithPolicy(ctx, store, projector, events, projector.Project)
+		if err == nil || handled != 0 {
+			t.Fatalf("expected failure without handled events, got %d: %v", handled, err)
+		}
+		if failure := store.failures["a"]; failure == nil || failure.State != FailureStateHalted {
+			t.Errorf("expected halted failure of a, got %+v", failure)
+		}
+	})
+}
@@ -24,6 +24,7 @@ type JournalEvent struct {
 	aggregateVersion AggregateVersion
 	journalPosition  JournalPosition
 	insertedAt       time.Time
+	metadata         *Metadata
 }
 
 func NewJournalEvent(event Event, eventID EventID, aggregateVersion AggregateVersion, journalPosition JournalPosition, insertedAt time.Time) *JournalEvent {
@@ -51,3 +52,14 @@ func (e *JournalEvent) JournalPosition() JournalPosition {
 func (e *JournalEvent) InsertedAt() time.Time {
 	return e.insertedAt
 }
+
+// Metadata returns the metadata of the request that caused the event.
+// Returns nil for events that were appended without any metadata.
+func (e *JournalEvent) Metadata() *Metadata {
+	return e.metadata
+}
+
+// SetMetadata attaches the persisted metadata to the event.
+func (e *JournalEvent) SetMetadata(metadata *Metadata) {
+	e.metadata = metadata
+}
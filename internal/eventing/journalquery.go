@@ -1,8 +1,11 @@
 package eventing
 
+import "maps"
+
 type JournalQuery struct {
 	byType               map[AggregateType]AggregateQuery
 	journalPositionAfter *JournalPosition
+	limit                int
 }
 
 func (q *JournalQuery) AggQueriesByType() map[AggregateType]AggregateQuery {
@@ -13,6 +16,11 @@ func (q *JournalQuery) JournalPositionAfter() *JournalPosition {
 	return q.journalPositionAfter
 }
 
+// Limit returns the maximum number of events to return. Zero means no limit.
+func (q *JournalQuery) Limit() int {
+	return q.limit
+}
+
 type AggregateQuery struct {
 	id      AggregateID
 	version AggregateVersion
@@ -34,13 +42,15 @@ func (q *AggregateQuery) Events() []EventType {
 type JournalQueryBuilder struct {
 	byType               map[AggregateType]AggregateQuery
 	journalPositionAfter *JournalPosition
+	limit                int
 }
 
 // NewJournalQueryBuilderFrom creates a new JournalQueryBuilder using an existing JournalQuery.
 func NewJournalQueryBuilderFrom(query JournalQuery) *JournalQueryBuilder {
 	return &JournalQueryBuilder{
-		byType:               query.byType,
+		byType:               maps.Clone(query.byType),
 		journalPositionAfter: query.journalPositionAfter,
+		limit:                query.limit,
 	}
 }
 
@@ -56,10 +66,17 @@ func (d *JournalQueryBuilder) WithJournalPositionAfter(pos JournalPosition) *Jou
 	return d
 }
 
+// WithLimit limits the number of returned events. Zero means no limit.
+func (d *JournalQueryBuilder) WithLimit(limit int) *JournalQueryBuilder {
+	d.limit = limit
+	return d
+}
+
 func (d *JournalQueryBuilder) MustBuild() JournalQuery {
 	return JournalQuery{
 		byType:               d.byType,
 		journalPositionAfter: d.journalPositionAfter,
+		limit:                d.limit,
 	}
 }
 
@@ -47,3 +47,31 @@ func TestJournalQueryBuilder(t *testing.T) {
 		t.Errorf("expected id %s, got %s", idB, aggQuery.id)
 	}
 }
+
+func TestNewJournalQueryBuilderFrom(t *testing.T) {
+	var builder JournalQueryBuilder
+	query := builder.
+		WithAggregate("test").
+		Finish().
+		WithLimit(10).
+		MustBuild()
+
+	derived := NewJournalQueryBuilderFrom(query).
+		WithAggregate("test2").
+		Finish().
+		WithLimit(20).
+		MustBuild()
+
+	if query.Limit() != 10 {
+		t.Errorf("expected limit 10, got %d", query.Limit())
+	}
+	if derived.Limit() != 20 {
+		t.Errorf("expected limit 20, got %d", derived.Limit())
+	}
+	if len(query.AggQueriesByType()) != 1 {
+		t.Errorf("expected original query to be unchanged, got %d types", len(query.AggQueriesByType()))
+	}
+	if len(derived.AggQueriesByType()) != 2 {
+		t.Errorf("expected 2 types, got %d", len(derived.AggQueriesByType()))
+	}
+}
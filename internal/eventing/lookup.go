@@ -18,6 +18,9 @@ type LookupOpts struct {
 	AggregateType AggregateType
 	FieldName     LookupFieldName
 	FieldValue    LookupFieldValue
+	// Sensitive must be set for fields provided by a [SensitiveLookupProvider].
+	// The field value is then hashed before it is looked up.
+	Sensitive bool
 }
 
 // LookupProvider is an interface that can be implemented by an [Event] to provide lookup values.
@@ -25,6 +28,12 @@ type LookupProvider interface {
 	LookupValues() LookupMap
 }
 
+// SensitiveLookupProvider is an interface that can be implemented by an [Event] to provide lookup values
+// that contain personal data. Only their hash is stored, see [LookupHasher].
+type SensitiveLookupProvider interface {
+	SensitiveLookupValues() LookupMap
+}
+
 // LookupRemover is an interface that can be implemented by an [Event] to provide lookup values to remove.
 type LookupRemover interface {
 	LookupRemoves() []LookupFieldName
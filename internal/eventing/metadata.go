@@ -0,0 +1,108 @@
+package eventing
+
+import (
+	"context"
+	"slices"
+)
+
+// Metadata describes the request that caused an event.
+// It is persisted next to the payload of every event appended within the same context.
+type Metadata struct {
+	// CorrelationID is shared by all events that originate from the same request.
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// CausationID is the ID of the request or event that directly caused the event.
+	CausationID string `json:"causation_id,omitempty"`
+
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
+
+	// PrincipalID is the ID of the account acting in the request, if any.
+	PrincipalID string `json:"principal_id,omitempty"`
+	// ClientIP and UserAgent are personal data of the principal. They are only recorded along with a principal
+	// and stored encrypted with its key, so they are shredded together with the account.
+	ClientIP  string `json:"client_ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+type metadataCtxKey struct{}
+
+// MetadataFromContext extracts the Metadata from the context.
+func MetadataFromContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(metadataCtxKey{}).(Metadata)
+	return md, ok
+}
+
+// NewContextWithMetadata adds the Metadata to the context.
+func NewContextWithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataCtxKey{}, md)
+}
+
+// NewContextWithCausation derives a context for work that is caused by the given event.
+// The correlation of the event is kept, so that follow-up events can be traced back to the original request.
+func NewContextWithCausation(ctx context.Context, cause *JournalEvent) context.Context {
+	md, _ := MetadataFromContext(ctx)
+	if causeMd := cause.Metadata(); causeMd != nil && causeMd.CorrelationID != "" {
+		md.CorrelationID = causeMd.CorrelationID
+	}
+	if md.CorrelationID == "" {
+		md.CorrelationID = string(cause.EventID())
+	}
+	md.CausationID = string(cause.EventID())
+	return NewContextWithMetadata(ctx, md)
+}
+
+// WithMetadata returns the events along with carriers of their metadata, so that the personal data of both
+// is encrypted or decrypted by a single call to an [EventEncryptor] or [EventDecrypter].
+// The metadata is transformed in place and bound to its event, so every event needs its own copy.
+func WithMetadata(events []Event, metadata []*Metadata) []Event {
+	withMetadata := slices.Clone(events)
+	for i, md := range metadata {
+		if md == nil || md.PrincipalID == "" {
+			continue
+		}
+		withMetadata = append(withMetadata, &metadataEvent{Event: events[i], metadata: md})
+	}
+	return withMetadata
+}
+
+// JournalEventsWithMetadata is like [WithMetadata] for events that have already been persisted.
+func JournalEventsWithMetadata(journalEvents []*JournalEvent) []Event {
+	events := make([]Event, len(journalEvents))
+	metadata := make([]*Metadata, len(journalEvents))
+	for i, journalEvent := range journalEvents {
+		events[i] = journalEvent.Event
+		metadata[i] = journalEvent.Metadata()
+	}
+	return WithMetadata(events, metadata)
+}
+
+// metadataEvent carries the metadata of an event through the crypto of the event store.
+type metadataEvent struct {
+	Event
+
+	metadata *Metadata
+}
+
+func (m *metadataEvent) DeclareOwners() []AggregateID {
+	return []AggregateID{AggregateID(m.metadata.PrincipalID)}
+}
+
+func (m *metadataEvent) AcceptCrypto(transformer CryptoTransformer) error {
+	owner := AggregateID(m.metadata.PrincipalID)
+	if err := transformMetadataValue(transformer, owner, "metadata.client_ip", &m.metadata.ClientIP); err != nil {
+		return err
+	}
+	return transformMetadataValue(transformer, owner, "metadata.user_agent", &m.metadata.UserAgent)
+}
+
+func transformMetadataValue(transformer CryptoTransformer, owner AggregateID, field string, value *string) error {
+	if *value == "" {
+		return nil
+	}
+	encrypted := NewEncryptedString(*value)
+	if err := transformer.Transform(owner, field, &encrypted); err != nil {
+		return err
+	}
+	*value = encrypted.Value
+	return nil
+}
@@ -0,0 +1,23 @@
+package eventing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewContextWithCausation(t *testing.T) {
+	cause := NewJournalEvent(nil, "cause", 1, JournalPosition{1, 1}, time.Now())
+	md, _ := MetadataFromContext(NewContextWithCausation(context.Background(), cause))
+	if md.CorrelationID != "cause" || md.CausationID != "cause" {
+		t.Errorf("expected an event without metadata to start the correlation, got %+v", md)
+	}
+
+	cause.SetMetadata(&Metadata{CorrelationID: "request", PrincipalID: "account"})
+	ctx := NewContextWithMetadata(context.Background(), Metadata{CorrelationID: "other", PrincipalID: "dispatcher"})
+	md, _ = MetadataFromContext(NewContextWithCausation(ctx, cause))
+	expected := Metadata{CorrelationID: "request", CausationID: "cause", PrincipalID: "dispatcher"}
+	if md != expected {
+		t.Errorf("expected %+v, got %+v", expected, md)
+	}
+}
@@ -0,0 +1,35 @@
+package eventing
+
+import "context"
+
+// OutboxHandler handles events that have been written to the outbox in the same transaction as the events themselves.
+// Delivery is at least once: failed deliveries are retried with backoff until Handle succeeds
+// or the entry runs out of attempts and is dead-lettered.
+// Handlers must therefore be idempotent and must not rely on the order of the events.
+type OutboxHandler interface {
+	// Name identifies the handler. It has to stay stable, as pending entries refer to it.
+	Name() string
+
+	// Interests returns the events that the handler is interested in.
+	Interests() EventInterestSet
+
+	// Handle delivers a single event to the handler.
+	// The metadata of the context names the event as the cause of the events appended by the handler.
+	Handle(ctx context.Context, event *JournalEvent) error
+}
+
+// Outbox delivers persisted events to the registered handlers.
+type Outbox interface {
+	// Register registers a handler. Only events appended after the registration are delivered to it.
+	Register(handler OutboxHandler)
+
+	// Dispatch delivers all due entries of the given handlers, or of all handlers if none are given.
+	// Returns the number of successful deliveries.
+	Dispatch(ctx context.Context, handlers ...string) (int, error)
+
+	// Trigger wakes up Run to deliver pending entries right away.
+	Trigger()
+
+	// Run dispatches due entries periodically until the context is cancelled.
+	Run(ctx context.Context) error
+}
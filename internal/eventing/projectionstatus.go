@@ -0,0 +1,91 @@
+package eventing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ProjectionStatus reports how far a projection is behind the journal.
+type ProjectionStatus struct {
+	// Supervisor is the type of the supervisor the projection is registered at.
+	Supervisor string
+
+	// State is the persisted state of the projection.
+	State ProjectionState
+
+	// LagEvents is the number of events of interest that have not been projected yet.
+	LagEvents int
+
+	// Lag is the age of the oldest event that has not been projected yet.
+	Lag time.Duration
+
+	// ProcessedEvents is the number of events projected by this instance since it started.
+	ProcessedEvents int64
+
+	// Failures is the number of failed runs of this instance since it started.
+	Failures int64
+}
+
+// SinceLastRun returns the time since the projection last ran successfully on any instance.
+func (s ProjectionStatus) SinceLastRun() time.Duration {
+	if s.State.UpdatedAt.IsZero() {
+		return 0
+	}
+	return time.Since(s.State.UpdatedAt)
+}
+
+// NewProjectionStatus determines the lag of the projection of projector, which is at state.
+func NewProjectionStatus(ctx context.Context, es EventStore, projector Projector, state ProjectionState, counters *ProjectionCounters) (ProjectionStatus, error) {
+	status := ProjectionStatus{State: state}
+	status.ProcessedEvents, status.Failures = counters.Get(state.Name)
+
+	pendingQuery := NewJournalQueryBuilderFrom(projector.Query()).
+		WithJournalPositionAfter(state.Position).
+		MustBuild()
+	oldest, err := es.Query(ctx, NewJournalQueryBuilderFrom(pendingQuery).WithLimit(1).MustBuild())
+	if err != nil {
+		return status, err
+	}
+	if len(oldest) == 0 {
+		return status, nil
+	}
+	status.Lag = time.Since(oldest[0].InsertedAt())
+	status.LagEvents, err = es.Count(ctx, pendingQuery)
+	return status, err
+}
+
+// ProjectionCounters counts the processed events and the failures of the projections of a supervisor.
+// The zero value is ready to use.
+type ProjectionCounters struct {
+	mu        sync.Mutex
+	processed map[ProjectionName]int64
+	failures  map[ProjectionName]int64
+}
+
+// AddProcessed adds n processed events of the projection.
+func (c *ProjectionCounters) AddProcessed(projection ProjectionName, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.processed == nil {
+		c.processed = make(map[ProjectionName]int64)
+	}
+	c.processed[projection] += int64(n)
+}
+
+// AddFailure counts a failed run of the projection.
+func (c *ProjectionCounters) AddFailure(projection ProjectionName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures == nil {
+		c.failures = make(map[ProjectionName]int64)
+	}
+	c.failures[projection]++
+}
+
+// Get returns the processed events and the failures of the projection.
+func (c *ProjectionCounters) Get(projection ProjectionName) (processed int64, failures int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.processed[projection], c.failures[projection]
+}
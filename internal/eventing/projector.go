@@ -2,14 +2,23 @@ package eventing
 
 import (
 	"context"
-	"github.com/shopspring/decimal"
+	"errors"
 	"time"
 )
 
+// ErrProjectionNotFound is returned when no projector of a projection is registered at a supervisor.
+var ErrProjectionNotFound = errors.New("projection not found")
+
+// ErrProjectionPaused is returned when a paused projection is asked to advance.
+var ErrProjectionPaused = errors.New("projection paused")
+
 type (
 	ProjectionName string
 )
 
+// ProjectionBatchSize is the maximum number of events a supervisor projects before checkpointing the [ProjectionState].
+const ProjectionBatchSize = 500
+
 // Projector is used to project events into a projection.
 type Projector interface {
 	JournalInquirer
@@ -34,8 +43,38 @@ type ProjectorSupervisor interface {
 	// Trigger is used to manually advance a specific projection.
 	Trigger(ctx context.Context, projection ...ProjectionName)
 
+	// Advance advances the projection and waits for it to finish. Unlike Trigger, it reports the failure.
+	// Returns [ErrProjectionPaused] if the projection is paused.
+	// Returns [ErrProjectionNotFound] if the projection is not registered at this supervisor.
+	Advance(ctx context.Context, projection ProjectionName) error
+
 	// Register is used to register a projector.
 	Register(projector Projector)
+
+	// Reset drops all projected data of the projection and re-initializes it, so that the next advancement
+	// replays it from the start of the journal. Does not wait for the replay, see [ProjectorSupervisor.Advance].
+	// Returns [ErrProjectionNotFound] if the projection is not registered at this supervisor.
+	Reset(ctx context.Context, projection ProjectionName) error
+
+	// Await blocks until the projection reflects all of its events up to the position or ctx is done.
+	// Returns [ErrProjectionNotFound] if the projection is not registered at this supervisor.
+	Await(ctx context.Context, projection ProjectionName, position JournalPosition) error
+
+	// Redrive projects the event of a failure of the projection once more, see [RedriveFailure].
+	// Returns [ErrProjectionNotFound] if the projection is not registered at this supervisor.
+	Redrive(ctx context.Context, projection ProjectionName, eventID EventID) error
+
+	// Pause stops advancing the projection on all instances until it is resumed.
+	// Returns once a running advancement finished.
+	// Returns [ErrProjectionNotFound] if the projection is not registered at this supervisor.
+	Pause(ctx context.Context, projection ProjectionName) error
+
+	// Resume continues advancing a paused projection and catches it up.
+	// Returns [ErrProjectionNotFound] if the projection is not registered at this supervisor.
+	Resume(ctx context.Context, projection ProjectionName) error
+
+	// Status reports the progress of all registered projections.
+	Status(ctx context.Context) ([]ProjectionStatus, error)
 }
 
 // EventInterest is used to specify the events that a listener is interested in.
@@ -46,6 +85,15 @@ type EventInterest struct {
 
 type EventInterestSet map[EventInterest]struct{}
 
+// NewInterestSet creates a set of the given interests.
+func NewInterestSet(interests ...EventInterest) EventInterestSet {
+	set := make(EventInterestSet, len(interests))
+	for _, interest := range interests {
+		set.Add(interest)
+	}
+	return set
+}
+
 // IsInterestedIn returns true if interest is present in the set.
 func (i EventInterestSet) IsInterestedIn(interest EventInterest) bool {
 	_, ok := i[interest]
@@ -59,8 +107,12 @@ func (i EventInterestSet) Add(interest EventInterest) {
 
 // ProjectorToInterests returns the interests of a projector.
 func ProjectorToInterests(projector Projector) []EventInterest {
+	return QueryToInterests(projector.Query())
+}
+
+// QueryToInterests returns the events of a query as interests.
+func QueryToInterests(query JournalQuery) []EventInterest {
 	var interests []EventInterest
-	query := projector.Query()
 	for agg, aggQuery := range query.AggQueriesByType() {
 		for _, event := range aggQuery.Events() {
 			interest := EventInterest{
@@ -88,6 +140,34 @@ type EventNotifier interface {
 
 	// Start starts the notifier.
 	Start(ctx context.Context) error
+
+	// Status returns the state of the connection of the notifier.
+	Status() NotifierStatus
+}
+
+type NotifierState string
+
+const (
+	NotifierStateConnecting   NotifierState = "connecting"
+	NotifierStateListening    NotifierState = "listening"
+	NotifierStateReconnecting NotifierState = "reconnecting"
+	NotifierStateStopped      NotifierState = "stopped"
+)
+
+// NotifierStatus reports whether an [EventNotifier] currently receives notifications.
+// While it does not, listeners only advance by polling.
+type NotifierStatus struct {
+	// State is the current state of the connection.
+	State NotifierState
+
+	// Since is the time the notifier entered the state.
+	Since time.Time
+
+	// Reconnects is the number of times the notifier lost its connection.
+	Reconnects int
+
+	// LastError is the error the connection was last lost with.
+	LastError error
 }
 
 type ProjectionState struct {
@@ -103,9 +183,13 @@ type ProjectionState struct {
 	// AggregateVersion is the version of the aggregate at the time of the last event processed by the projection.
 	AggregateVersion AggregateVersion `json:"aggregate_version"`
 
-	// GlobalPosition is the global position of the last event processed by the projection.
-	GlobalPosition decimal.Decimal `json:"global_position"`
+	// Position is the journal position of the last event processed by the projection.
+	Position JournalPosition `json:"position"`
 
 	// UpdatedAt is the timestamp of the last update to the projection state.
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Paused reports whether the projection is paused.
+	// It is not part of the checkpoint, so that advancing the projection never overwrites it.
+	Paused bool `json:"-"`
 }
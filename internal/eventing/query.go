@@ -22,8 +22,13 @@ func WithErrorOnCryptoShredded() QueryOpts {
 	})
 }
 
-// WithLimitToOldestRunningTransaction instructs the query to only return rows that were inserted
-// before the oldest running transaction. This ensures that, e.g., projectors do not skip events.
+// WithLimitToOldestRunningTransaction instructs the query to only return rows that were appended
+// by transactions older than the oldest running transaction. As positions are ordered by transaction,
+// this ensures that, e.g., projectors do not skip events of transactions that commit late.
+//
+// The oldest running transaction is determined across the whole database cluster, not just the
+// transactions appending events. Any long-running transaction in the cluster, e.g. a report or a
+// forgotten psql session of another database, therefore stalls all projections until it ends.
 func WithLimitToOldestRunningTransaction() QueryOpts {
 	return queryOptsFunc(func(config QueryConfig) QueryConfig {
 		config.LimitToOldestRunningTransaction = true
@@ -56,6 +56,11 @@ func (b *BaseWriter) Reduce(events []*JournalEvent) {
 	}
 }
 
+// RestoreVersion sets the version of the aggregate when its state is restored from a [Snapshot].
+func (b *BaseWriter) RestoreVersion(version AggregateVersion) {
+	b.version = version
+}
+
 func (b *BaseWriter) Append(events ...Event) {
 	b.events = append(b.events, events...)
 }
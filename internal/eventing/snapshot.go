@@ -0,0 +1,89 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+)
+
+// SnapshotVersion is the schema version of a snapshot payload.
+// It has to be bumped whenever the serialized layout of an aggregate changes.
+type SnapshotVersion uint
+
+// Snapshot is the serialized state of an aggregate at a specific aggregate version.
+type Snapshot struct {
+	AggregateID      AggregateID
+	AggregateType    AggregateType
+	AggregateVersion AggregateVersion
+	SnapshotVersion  SnapshotVersion
+	Payload          []byte
+}
+
+// Snapshotter can be implemented by a [JournalViewer] to allow the event store to restore its state
+// from a snapshot instead of replaying the whole stream.
+type Snapshotter interface {
+	JournalViewer
+
+	// Aggregate returns the aggregate the snapshot is taken of.
+	Aggregate() *Aggregate
+
+	// SnapshotVersion returns the current schema version of the snapshot.
+	// Snapshots with a different version are discarded.
+	SnapshotVersion() SnapshotVersion
+
+	// Snapshot serializes the current state.
+	Snapshot() ([]byte, error)
+
+	// RestoreSnapshot restores the state from a snapshot taken at the given aggregate version.
+	// The state must be left untouched if an error is returned, as the events are replayed instead.
+	RestoreSnapshot(version AggregateVersion, payload []byte) error
+}
+
+// EncryptedSnapshotter is a marker interface for snapshotters whose state contains encrypted values.
+// Their snapshots are encrypted with the key of the aggregate, so that crypto shredding also covers them.
+type EncryptedSnapshotter interface {
+	Snapshotter
+
+	EncryptSnapshot()
+}
+
+// SnapshotStore persists the latest snapshot of an aggregate.
+type SnapshotStore interface {
+	// LoadSnapshot returns the latest snapshot of the aggregate.
+	// Returns [ErrSnapshotNotFound] if there is none.
+	LoadSnapshot(ctx context.Context, aggregateID AggregateID, aggregateType AggregateType, encrypted bool) (*Snapshot, error)
+
+	// SaveSnapshot replaces the snapshot of the aggregate.
+	SaveSnapshot(ctx context.Context, snapshot *Snapshot, encrypted bool) error
+}
+
+// TakeSnapshot creates a snapshot of the current state of the snapshotter.
+func TakeSnapshot(snapshotter Snapshotter) (*Snapshot, error) {
+	payload, err := snapshotter.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	aggregate := snapshotter.Aggregate()
+	return &Snapshot{
+		AggregateID:      aggregate.AggregateID,
+		AggregateType:    aggregate.AggregateType,
+		AggregateVersion: aggregate.Version,
+		SnapshotVersion:  snapshotter.SnapshotVersion(),
+		Payload:          payload,
+	}, nil
+}
+
+// QueryAfterSnapshot narrows the query of the snapshotted aggregate to the events after the snapshot.
+func QueryAfterSnapshot(query JournalQuery, snapshot *Snapshot) JournalQuery {
+	aggQuery := query.AggQueriesByType()[snapshot.AggregateType]
+	return NewJournalQueryBuilderFrom(query).
+		WithAggregate(snapshot.AggregateType).
+		AggregateID(snapshot.AggregateID).
+		AggregateVersionAtLeast(snapshot.AggregateVersion + 1).
+		Events(aggQuery.Events()...).
+		Finish().
+		MustBuild()
+}
@@ -2,6 +2,7 @@ package eventing
 
 import (
 	"fmt"
+	"strings"
 )
 
 // UniqueConstraint represents a unique constraint on a field of an aggregate.
@@ -10,6 +11,8 @@ type UniqueConstraint struct {
 	ownerAggregateID AggregateID
 	constrainedField string
 	constrainedValue string
+	// sensitive constraints contain personal data and are only stored as a hash.
+	sensitive bool
 }
 
 // NewUniqueConstraint creates a unique constraint for the given aggregate and field.
@@ -21,6 +24,14 @@ func NewUniqueConstraint(ownerAggregateID AggregateID, constrainedField, constra
 	}
 }
 
+// NewSensitiveUniqueConstraint creates a unique constraint on a field that contains personal data.
+// Only the hash of the value is stored, see [LookupHasher].
+func NewSensitiveUniqueConstraint(ownerAggregateID AggregateID, constrainedField, constraintValue string) UniqueConstraint {
+	constraint := NewUniqueConstraint(ownerAggregateID, constrainedField, constraintValue)
+	constraint.sensitive = true
+	return constraint
+}
+
 // NewDeleteAllConstraint creates a unique constraint that removes all constraints for the given aggregate.
 func NewDeleteAllConstraint(id AggregateID) UniqueConstraint {
 	return UniqueConstraint{
@@ -42,6 +53,19 @@ func (u *UniqueConstraint) ConstrainedValue() string {
 	return u.constrainedValue
 }
 
+func (u *UniqueConstraint) IsSensitive() bool {
+	return u.sensitive
+}
+
+// Protect returns the constraint as it is stored, which replaces the value of sensitive constraints with its hash.
+// As constraints are case-insensitive, the value is lowercased before it is hashed.
+func (u *UniqueConstraint) Protect(hasher LookupHasher) UniqueConstraint {
+	if !u.sensitive {
+		return *u
+	}
+	return NewUniqueConstraint(u.ownerAggregateID, u.constrainedField, hasher.HashLookupValue(u.constrainedField, strings.ToLower(u.constrainedValue)))
+}
+
 type UniqueConstraintAdder interface {
 	UniqueConstraintsToAdd() []UniqueConstraint
 }
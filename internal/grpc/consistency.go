@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+)
+
+// consistencyTokenHeader carries the journal position of the last write in responses.
+// Clients send it back in requests to read their own writes from the projections.
+const consistencyTokenHeader = "X-Consistency-Token"
+
+// newConsistencyInterceptor exchanges consistency tokens with the client.
+// The token of the request makes queries wait for the projections, the token of the response
+// is the position of the events appended while handling the request.
+func newConsistencyInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			// Client is not supported.
+			if req.Spec().IsClient {
+				return next(ctx, req)
+			}
+
+			if token := req.Header().Get(consistencyTokenHeader); token != "" {
+				position, err := eventing.ParseJournalPosition(token)
+				if err != nil {
+					return nil, connect.NewError(connect.CodeInvalidArgument, err)
+				}
+				ctx = eventing.NewContextWithMinPosition(ctx, position)
+			}
+			ctx = eventing.NewContextWithPositionRecorder(ctx)
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+			if position, ok := eventing.RecordedPosition(ctx); ok {
+				resp.Header().Set(consistencyTokenHeader, position.String())
+			}
+			return resp, nil
+		}
+	}
+}
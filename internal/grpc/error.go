@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"connectrpc.com/connect"
+	"context"
 	"errors"
 	"github.com/rsmidt/soccerbuddy/internal/app/validation"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
@@ -58,6 +59,22 @@ func (b *baseHandler) handleCommonErrors(err error) error {
 	if errors.Is(err, eventing.ErrValueNotFound) {
 		return connect.NewError(connect.CodeNotFound, nil)
 	}
+	if errors.Is(err, eventing.ErrProjectionNotFound) {
+		return connect.NewError(connect.CodeNotFound, nil)
+	}
+	if errors.Is(err, eventing.ErrProjectionFailureNotFound) {
+		return connect.NewError(connect.CodeNotFound, nil)
+	}
+	if errors.Is(err, authz.ErrRelationsNotTransactional) {
+		return connect.NewError(connect.CodeFailedPrecondition, errors.New("permissions can only be reset with the embedded authz engine"))
+	}
+	if errors.Is(err, eventing.ErrProjectionPaused) {
+		return connect.NewError(connect.CodeFailedPrecondition, errors.New("projection paused"))
+	}
+	if errors.Is(err, eventing.ErrVersionMismatch) || errors.Is(err, eventing.ErrIntentOutdated) {
+		// The aggregate has been modified concurrently and retrying the command didn't resolve it.
+		return connect.NewError(connect.CodeAborted, errors.New("concurrent modification"))
+	}
 	var eErr domain.InvalidAggregateStateError
 	if errors.As(err, &eErr) {
 		return connect.NewError(connect.CodeFailedPrecondition, errors.New("invalid aggregate state"))
@@ -66,3 +83,17 @@ func (b *baseHandler) handleCommonErrors(err error) error {
 
 	return internalErr
 }
+
+// handleWatchErrors maps the error that ended a watch.
+// A watch ends regularly once the client goes away, which is not worth a warning.
+func (b *baseHandler) handleWatchErrors(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	var cErr *connect.Error
+	if errors.As(err, &cErr) {
+		// Sending to the stream failed.
+		return cErr
+	}
+	return b.handleCommonErrors(err)
+}
@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"net/http"
+)
+
+const correlationIDHeader = "X-Correlation-ID"
+
+// newMetadataInterceptor attaches the event metadata of the request to the context of unary and streaming handlers.
+// It has to run after the authentication, so that the principal is known.
+func newMetadataInterceptor() connect.Interceptor {
+	return &metadataInterceptor{}
+}
+
+type metadataInterceptor struct{}
+
+func (m *metadataInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		// Client is not supported.
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		return next(newContextWithRequestMetadata(ctx, req), req)
+	}
+}
+
+func (m *metadataInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	// Client is not supported.
+	return next
+}
+
+func (m *metadataInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return next(newContextWithRequestMetadata(ctx, streamingRequest{conn}), conn)
+	}
+}
+
+// streamingRequest exposes the peer and headers of a stream as an [AnyRequest].
+type streamingRequest struct {
+	conn connect.StreamingHandlerConn
+}
+
+func (s streamingRequest) Peer() connect.Peer {
+	return s.conn.Peer()
+}
+
+func (s streamingRequest) Header() http.Header {
+	return s.conn.RequestHeader()
+}
+
+func newContextWithRequestMetadata(ctx context.Context, req AnyRequest) context.Context {
+	// Reuse the correlation ID of the caller, if any.
+	correlationID := req.Header().Get(correlationIDHeader)
+	if correlationID == "" {
+		correlationID = idgen.NewString()
+	}
+	md := eventing.Metadata{
+		CorrelationID: correlationID,
+		// The request itself is the cause of all events.
+		CausationID: correlationID,
+	}
+	// The client IP and user agent are encrypted with the key of the principal, so they can only be recorded along with it.
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		md.PrincipalID = string(principal.AccountID)
+		md.UserAgent = req.Header().Get("User-Agent")
+		if ip := GetClientIP(req, nil); ip != nil {
+			md.ClientIP = ip.String()
+		}
+	}
+	return eventing.NewContextWithMetadata(ctx, md)
+}
@@ -11,47 +11,73 @@ import (
 	"strings"
 )
 
-func NewAuthenticationMiddleware(qs *queries.Queries) connect.UnaryInterceptorFunc {
-	return func(next connect.UnaryFunc) connect.UnaryFunc {
-		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
-			// Client is not supported.
-			if req.Spec().IsClient {
-				return next(ctx, req)
-			}
+// NewAuthenticationMiddleware attaches the principal of the session to the context of unary and streaming handlers.
+func NewAuthenticationMiddleware(qs *queries.Queries) connect.Interceptor {
+	return &authenticationMiddleware{qs: qs}
+}
 
-			// Get session ID cookie or authorization header.
-			var rawSessionToken string
-			tempReq := http.Request{Header: req.Header()}
-			cookie, err := tempReq.Cookie("ID")
-			if errors.Is(err, http.ErrNoCookie) {
-				// If there's no cookie, try the authorization header.
-				rawSessionToken = extractFromBearer(req.Header().Get("Authorization"))
-				if rawSessionToken == "" {
-					return next(ctx, req)
-				}
-			} else if err != nil {
-				return nil, connect.NewError(connect.CodeInvalidArgument, nil)
-			} else {
-				rawSessionToken = cookie.Value
-			}
-			if rawSessionToken == "" {
-				return next(ctx, req)
-			}
+type authenticationMiddleware struct {
+	qs *queries.Queries
+}
 
-			// Get principal by session ID.
-			query := queries.PrincipalBySessionTokenQuery{Token: domain.SessionToken(rawSessionToken)}
-			principal, err := qs.PrincipalBySessionToken(ctx, query)
-			if errors.Is(err, domain.ErrPrincipalNotFound) {
-				// Principal not found. Let's ignore this cookie.
-				return next(ctx, req)
-			} else if err != nil {
-				tracing.RecordError(ctx, err)
-				return nil, connect.NewError(connect.CodeInternal, nil)
-			}
-			ctx = domain.NewContextWithPrincipal(ctx, principal)
+func (a *authenticationMiddleware) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		// Client is not supported.
+		if req.Spec().IsClient {
 			return next(ctx, req)
 		}
+		ctx, err := a.authenticate(ctx, req.Header())
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (a *authenticationMiddleware) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	// Client is not supported.
+	return next
+}
+
+func (a *authenticationMiddleware) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := a.authenticate(ctx, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+// authenticate adds the principal to the context, if the request carries a valid session token.
+func (a *authenticationMiddleware) authenticate(ctx context.Context, header http.Header) (context.Context, error) {
+	// Get session ID cookie or authorization header.
+	var rawSessionToken string
+	tempReq := http.Request{Header: header}
+	cookie, err := tempReq.Cookie("ID")
+	if errors.Is(err, http.ErrNoCookie) {
+		// If there's no cookie, try the authorization header.
+		rawSessionToken = extractFromBearer(header.Get("Authorization"))
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, nil)
+	} else {
+		rawSessionToken = cookie.Value
+	}
+	if rawSessionToken == "" {
+		return ctx, nil
+	}
+
+	// Get principal by session ID.
+	query := queries.PrincipalBySessionTokenQuery{Token: domain.SessionToken(rawSessionToken)}
+	principal, err := a.qs.PrincipalBySessionToken(ctx, query)
+	if errors.Is(err, domain.ErrPrincipalNotFound) {
+		// Principal not found. Let's ignore this cookie.
+		return ctx, nil
+	} else if err != nil {
+		tracing.RecordError(ctx, err)
+		return nil, connect.NewError(connect.CodeInternal, nil)
 	}
+	return domain.NewContextWithPrincipal(ctx, principal), nil
 }
 
 func extractFromBearer(header string) string {
@@ -197,3 +197,18 @@ func (p *personServer) ClaimPersonLink(ctx context.Context, c *connect.Request[v
 	}
 	return connect.NewResponse(&v1.ClaimPersonLinkResponse{}), nil
 }
+
+func (p *personServer) ShredPerson(ctx context.Context, c *connect.Request[v1.ShredPersonRequest]) (*connect.Response[v1.ShredPersonResponse], error) {
+	cmd := commands.ShredPersonCommand{
+		PersonID: domain.PersonID(c.Msg.PersonId),
+	}
+	err := p.cmds.ShredPerson(ctx, cmd)
+	if errors.Is(err, domain.ErrPersonNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	} else if errors.Is(err, domain.ErrPersonShredded) {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	} else if err != nil {
+		return nil, p.handleCommonErrors(err)
+	}
+	return connect.NewResponse(&v1.ShredPersonResponse{}), nil
+}
@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	v1 "github.com/rsmidt/soccerbuddy/gen/go/soccerbuddy/admin/v1"
+	"github.com/rsmidt/soccerbuddy/gen/go/soccerbuddy/admin/v1/adminv1connect"
+	"github.com/rsmidt/soccerbuddy/internal/app/commands"
+	"github.com/rsmidt/soccerbuddy/internal/app/queries"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type projectionServer struct {
+	*baseHandler
+}
+
+func newProjectionServiceHandler(base *baseHandler) adminv1connect.ProjectionServiceHandler {
+	return &projectionServer{baseHandler: base}
+}
+
+func (ps *projectionServer) ListProjections(ctx context.Context, c *connect.Request[v1.ListProjectionsRequest]) (*connect.Response[v1.ListProjectionsResponse], error) {
+	statuses, err := ps.qs.ListProjections(ctx)
+	if err != nil {
+		return nil, ps.handleCommonErrors(err)
+	}
+	projections := make([]*v1.ListProjectionsResponse_Projection, len(statuses))
+	for i, status := range statuses {
+		projection := &v1.ListProjectionsResponse_Projection{
+			Name:       string(status.State.Name),
+			Supervisor: status.Supervisor,
+			Position:   status.State.Position.String(),
+			Lag:        durationpb.New(status.Lag),
+			LagEvents:  int32(status.LagEvents),
+			Paused:     status.State.Paused,
+		}
+		if status.State.LastProcessedEventID != nil {
+			projection.LastEventId = (*string)(status.State.LastProcessedEventID)
+		}
+		if !status.State.UpdatedAt.IsZero() {
+			projection.UpdatedAt = timestamppb.New(status.State.UpdatedAt)
+		}
+		projections[i] = projection
+	}
+	return connect.NewResponse(&v1.ListProjectionsResponse{Projections: projections}), nil
+}
+
+func (ps *projectionServer) TriggerProjection(ctx context.Context, c *connect.Request[v1.TriggerProjectionRequest]) (*connect.Response[v1.TriggerProjectionResponse], error) {
+	cmd := commands.TriggerProjectionCommand{
+		Projection: eventing.ProjectionName(c.Msg.Name),
+	}
+	if err := ps.cmds.TriggerProjection(ctx, cmd); err != nil {
+		return nil, ps.handleCommonErrors(err)
+	}
+	return connect.NewResponse(&v1.TriggerProjectionResponse{}), nil
+}
+
+func (ps *projectionServer) PauseProjection(ctx context.Context, c *connect.Request[v1.PauseProjectionRequest]) (*connect.Response[v1.PauseProjectionResponse], error) {
+	cmd := commands.PauseProjectionCommand{
+		Projection: eventing.ProjectionName(c.Msg.Name),
+	}
+	if err := ps.cmds.PauseProjection(ctx, cmd); err != nil {
+		return nil, ps.handleCommonErrors(err)
+	}
+	return connect.NewResponse(&v1.PauseProjectionResponse{}), nil
+}
+
+func (ps *projectionServer) ResumeProjection(ctx context.Context, c *connect.Request[v1.ResumeProjectionRequest]) (*connect.Response[v1.ResumeProjectionResponse], error) {
+	cmd := commands.ResumeProjectionCommand{
+		Projection: eventing.ProjectionName(c.Msg.Name),
+	}
+	if err := ps.cmds.ResumeProjection(ctx, cmd); err != nil {
+		return nil, ps.handleCommonErrors(err)
+	}
+	return connect.NewResponse(&v1.ResumeProjectionResponse{}), nil
+}
+
+func (ps *projectionServer) ResetProjection(ctx context.Context, c *connect.Request[v1.ResetProjectionRequest]) (*connect.Response[v1.ResetProjectionResponse], error) {
+	cmd := commands.ResetProjectionCommand{
+		Projection: eventing.ProjectionName(c.Msg.Name),
+	}
+	if err := ps.cmds.ResetProjection(ctx, cmd); err != nil {
+		return nil, ps.handleCommonErrors(err)
+	}
+	return connect.NewResponse(&v1.ResetProjectionResponse{}), nil
+}
+
+func (ps *projectionServer) ListDeadLetters(ctx context.Context, c *connect.Request[v1.ListDeadLettersRequest]) (*connect.Response[v1.ListDeadLettersResponse], error) {
+	failures, err := ps.qs.ListDeadLetters(ctx, queries.ListDeadLettersQuery{
+		Projection: eventing.ProjectionName(c.Msg.Projection),
+	})
+	if err != nil {
+		return nil, ps.handleCommonErrors(err)
+	}
+	deadLetters := make([]*v1.ListDeadLettersResponse_DeadLetter, len(failures))
+	for i, f := range failures {
+		deadLetters[i] = &v1.ListDeadLettersResponse_DeadLetter{
+			Projection:    string(f.Projection),
+			EventId:       string(f.EventID),
+			EventType:     string(f.EventType),
+			AggregateType: string(f.AggregateType),
+			AggregateId:   string(f.AggregateID),
+			State:         string(f.State),
+			Attempts:      int32(f.Attempts),
+			LastError:     f.LastError,
+			FailedAt:      timestamppb.New(f.UpdatedAt),
+			NextAttemptAt: timestamppb.New(f.NextAttemptAt),
+		}
+	}
+	return connect.NewResponse(&v1.ListDeadLettersResponse{DeadLetters: deadLetters}), nil
+}
+
+func (ps *projectionServer) RedriveDeadLetter(ctx context.Context, c *connect.Request[v1.RedriveDeadLetterRequest]) (*connect.Response[v1.RedriveDeadLetterResponse], error) {
+	cmd := commands.RedriveDeadLetterCommand{
+		Projection: eventing.ProjectionName(c.Msg.Projection),
+		EventID:    eventing.EventID(c.Msg.EventId),
+	}
+	if err := ps.cmds.RedriveDeadLetter(ctx, cmd); err != nil {
+		return nil, ps.handleCommonErrors(err)
+	}
+	return connect.NewResponse(&v1.RedriveDeadLetterResponse{}), nil
+}
@@ -9,6 +9,7 @@ import (
 	_type "github.com/rsmidt/soccerbuddy/gen/go/google/type"
 	"github.com/rsmidt/soccerbuddy/gen/go/soccerbuddy"
 	"github.com/rsmidt/soccerbuddy/gen/go/soccerbuddy/account/v1/accountv1connect"
+	"github.com/rsmidt/soccerbuddy/gen/go/soccerbuddy/admin/v1/adminv1connect"
 	"github.com/rsmidt/soccerbuddy/gen/go/soccerbuddy/club/v1/clubv1connect"
 	"github.com/rsmidt/soccerbuddy/gen/go/soccerbuddy/person/v1/personv1connect"
 	teamv1 "github.com/rsmidt/soccerbuddy/gen/go/soccerbuddy/team/v1"
@@ -57,6 +58,7 @@ func (s *Server) Register(mux *http.ServeMux) error {
 	accountService := newAccountServiceHandler(base)
 	clubService := newClubServiceHandler(base)
 	personService := newPersonServiceHandler(base)
+	projectionService := newProjectionServiceHandler(base)
 
 	otelInterceptor, err := otelconnect.NewInterceptor()
 	if err != nil {
@@ -65,6 +67,8 @@ func (s *Server) Register(mux *http.ServeMux) error {
 	commonOpts := connect.WithInterceptors(
 		otelInterceptor,
 		authInterceptor,
+		newMetadataInterceptor(),
+		newConsistencyInterceptor(),
 	)
 
 	tspath, tshandler := teamv1connect.NewTeamServiceHandler(
@@ -83,12 +87,17 @@ func (s *Server) Register(mux *http.ServeMux) error {
 		personService,
 		commonOpts,
 	)
+	prpath, prhandler := adminv1connect.NewProjectionServiceHandler(
+		projectionService,
+		commonOpts,
+	)
 
 	reflector := grpcreflect.NewStaticReflector(
 		teamv1connect.TeamServiceName,
 		accountv1connect.AccountServiceName,
 		clubv1connect.ClubServiceName,
 		personv1connect.PersonServiceName,
+		adminv1connect.ProjectionServiceName,
 	)
 	mux.Handle(grpcreflect.NewHandlerV1Alpha(reflector))
 	mux.Handle(grpcreflect.NewHandlerV1(reflector))
@@ -96,6 +105,7 @@ func (s *Server) Register(mux *http.ServeMux) error {
 	mux.Handle(aspath, ashandler)
 	mux.Handle(cpath, chandler)
 	mux.Handle(ppath, phandler)
+	mux.Handle(prpath, prhandler)
 	return nil
 }
 
@@ -135,6 +135,20 @@ func (t *teamServer) ListTeamMembers(ctx context.Context, c *connect.Request[tea
 	if err != nil {
 		return nil, t.handleCommonErrors(err)
 	}
+	return connect.NewResponse(teamMembersToPb(members)), nil
+}
+
+func (t *teamServer) WatchTeamMembers(ctx context.Context, c *connect.Request[teamv1.WatchTeamMembersRequest], stream *connect.ServerStream[teamv1.WatchTeamMembersResponse]) error {
+	query := queries.ListTeamMembersQuery{
+		TeamID: domain.TeamID(c.Msg.TeamId),
+	}
+	err := t.qs.WatchTeamMembers(ctx, &query, func(members *queries.ListTeamMembersView) error {
+		return stream.Send(&teamv1.WatchTeamMembersResponse{TeamMembers: teamMembersToPb(members)})
+	})
+	return t.handleWatchErrors(ctx, err)
+}
+
+func teamMembersToPb(members *queries.ListTeamMembersView) *teamv1.ListTeamMembersResponse {
 	var respMembers []*teamv1.ListTeamMembersResponse_Member
 	for _, member := range members.MembersByPersonID {
 		var inviterID *string
@@ -155,9 +169,9 @@ func (t *teamServer) ListTeamMembers(ctx context.Context, c *connect.Request[tea
 			Role:      string(member.Role),
 		})
 	}
-	return connect.NewResponse(&teamv1.ListTeamMembersResponse{
+	return &teamv1.ListTeamMembersResponse{
 		Members: respMembers,
-	}), nil
+	}
 }
 
 func (t *teamServer) ScheduleTraining(ctx context.Context, c *connect.Request[teamv1.ScheduleTrainingRequest]) (*connect.Response[teamv1.ScheduleTrainingResponse], error) {
@@ -190,6 +204,20 @@ func (t *teamServer) GetMyTeamHome(ctx context.Context, c *connect.Request[teamv
 	if err != nil {
 		return nil, t.handleCommonErrors(err)
 	}
+	return connect.NewResponse(myTeamHomeToPb(th)), nil
+}
+
+func (t *teamServer) WatchMyTeamHome(ctx context.Context, c *connect.Request[teamv1.WatchMyTeamHomeRequest], stream *connect.ServerStream[teamv1.WatchMyTeamHomeResponse]) error {
+	query := queries.GetMyTeamHomeQuery{
+		TeamID: domain.TeamID(c.Msg.TeamId),
+	}
+	err := t.qs.WatchMyTeamHome(ctx, &query, func(th *queries.MyTeamHomeView) error {
+		return stream.Send(&teamv1.WatchMyTeamHomeResponse{Home: myTeamHomeToPb(th)})
+	})
+	return t.handleWatchErrors(ctx, err)
+}
+
+func myTeamHomeToPb(th *queries.MyTeamHomeView) *teamv1.GetMyTeamHomeResponse {
 	ts := make([]*teamv1.GetMyTeamHomeResponse_Training, len(th.Trainings))
 	for i, training := range th.Trainings {
 		ts[i] = &teamv1.GetMyTeamHomeResponse_Training{
@@ -205,11 +233,11 @@ func (t *teamServer) GetMyTeamHome(ctx context.Context, c *connect.Request[teamv
 			Nominations:            nominationResponsesToPb(training.Nominations),
 		}
 	}
-	return connect.NewResponse(&teamv1.GetMyTeamHomeResponse{
+	return &teamv1.GetMyTeamHomeResponse{
 		TeamId:    string(th.ID),
 		TeamName:  th.Name,
 		Trainings: ts,
-	}), nil
+	}
 }
 
 func (t *teamServer) NominatePersonsForTraining(ctx context.Context, c *connect.Request[teamv1.NominatePersonsForTrainingRequest]) (*connect.Response[teamv1.NominatePersonsForTrainingResponse], error) {
@@ -0,0 +1,392 @@
+package eventing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"iter"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+type record struct {
+	id               eventing.EventID
+	aggregateID      eventing.AggregateID
+	aggregateType    eventing.AggregateType
+	aggregateVersion eventing.AggregateVersion
+	journalPosition  eventing.JournalPosition
+	eventType        eventing.EventType
+	eventVersion     eventing.EventVersion
+	payload          []byte
+	metadata         *eventing.Metadata
+	createdAt        time.Time
+}
+
+// constraintKey mirrors the case-insensitive primary key of the unique_constraint table.
+type constraintKey struct {
+	field string
+	value string
+}
+
+type lookupKey struct {
+	ownerID   eventing.AggregateID
+	fieldName eventing.LookupFieldName
+}
+
+type lookupEntry struct {
+	ownerType  eventing.AggregateType
+	fieldValue eventing.LookupFieldValue
+}
+
+// memEventStore is an in-memory [eventing.EventStore] that mimics the behavior of the postgres implementation.
+// Events are stored serialized, so every query hands out fresh (and freshly decrypted) events.
+type memEventStore struct {
+	mu     sync.RWMutex
+	mapper eventing.JournalEventMapper
+	crypto eventing.EventCrypto
+	log    *slog.Logger
+	hooks  []eventing.Hook
+
+	records     []*record
+	versions    map[eventing.Aggregate]eventing.AggregateVersion
+	constraints map[constraintKey]eventing.AggregateID
+	lookups     map[lookupKey]lookupEntry
+	position    eventing.JournalPosition
+}
+
+func NewEventStore(log *slog.Logger, mapper eventing.JournalEventMapper, crypto eventing.EventCrypto) eventing.EventStore {
+	return &memEventStore{
+		mapper:      mapper,
+		crypto:      crypto,
+		log:         log,
+		versions:    make(map[eventing.Aggregate]eventing.AggregateVersion),
+		constraints: make(map[constraintKey]eventing.AggregateID),
+		lookups:     make(map[lookupKey]lookupEntry),
+	}
+}
+
+func (m *memEventStore) Append(ctx context.Context, intents ...eventing.AggregateChangeIntent) ([][]*eventing.JournalEvent, error) {
+	if err := eventing.CheckDistinctIntents(intents); err != nil {
+		return nil, err
+	}
+	var metadata *eventing.Metadata
+	if md, ok := eventing.MetadataFromContext(ctx); ok {
+		metadata = &md
+	}
+
+	persistedEvents, err := m.append(ctx, intents, metadata)
+	if err != nil {
+		return nil, err
+	}
+	eventing.RecordAppended(ctx, persistedEvents)
+	// Run all post persist hooks
+	for _, hook := range m.hooks {
+		if post, ok := hook.(eventing.PostPersist); ok {
+			if err := post.PostPersist(ctx); err != nil {
+				m.log.Error("Failed to run post persist hook", slog.String("err", err.Error()))
+			}
+		}
+	}
+	return persistedEvents, nil
+}
+
+// append stages all changes and only commits them if every intent succeeded.
+func (m *memEventStore) append(ctx context.Context, intents []eventing.AggregateChangeIntent, metadata *eventing.Metadata) ([][]*eventing.JournalEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var (
+		persistedEvents = make([][]*eventing.JournalEvent, len(intents))
+		records         []*record
+		versions        = make(map[eventing.Aggregate]eventing.AggregateVersion)
+		constraints     = maps.Clone(m.constraints)
+		lookups         = maps.Clone(m.lookups)
+		position        = eventing.JournalPosition{TransactionID: m.position.TransactionID + 1, Sequence: m.position.Sequence}
+	)
+	for i, intent := range intents {
+		if len(intent.Events()) == 0 {
+			continue
+		}
+		aggregate := eventing.Aggregate{AggregateID: intent.AggregateID(), AggregateType: intent.AggregateType()}
+		if !intent.VersionMatches(m.versions[aggregate]) {
+			m.log.Debug("Aggregate versions do not match", slog.Uint64("remote", uint64(m.versions[aggregate])), slog.Uint64("local", uint64(intent.LastKnownAggregateVersion())))
+			return nil, eventing.ErrVersionMismatch
+		}
+		if err := applyUniqueConstraints(constraints, intent, m.crypto); err != nil {
+			return nil, err
+		}
+		applyLookups(lookups, intent, m.crypto)
+
+		events := intent.Events()
+		eventMetadata := make([]*eventing.Metadata, len(events))
+		if metadata != nil {
+			for j := range events {
+				md := *metadata
+				eventMetadata[j] = &md
+			}
+		}
+		cryptoEvents := eventing.WithMetadata(events, eventMetadata)
+		if err := m.crypto.EncryptEvents(ctx, cryptoEvents); err != nil {
+			return nil, fmt.Errorf("failed to encrypt events: %w", err)
+		}
+		now := time.Now()
+		for j, event := range events {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return nil, err
+			}
+			position.Sequence++
+			records = append(records, &record{
+				id:               idgen.New[eventing.EventID](),
+				aggregateID:      event.AggregateID(),
+				aggregateType:    event.AggregateType(),
+				aggregateVersion: intent.LastKnownAggregateVersion() + eventing.AggregateVersion(j+1),
+				journalPosition:  position,
+				eventType:        event.EventType(),
+				eventVersion:     event.EventVersion(),
+				payload:          payload,
+				metadata:         cloneMetadata(eventMetadata[j]),
+				createdAt:        now,
+			})
+		}
+		if err := m.crypto.DecryptEvents(ctx, cryptoEvents); err != nil {
+			return nil, fmt.Errorf("failed to decrypt events again: %w", err)
+		}
+
+		persisted := records[len(records)-len(events):]
+		persistedEvents[i] = make([]*eventing.JournalEvent, len(events))
+		for j, event := range events {
+			journalEvent := eventing.NewJournalEvent(event, persisted[j].id, persisted[j].aggregateVersion, persisted[j].journalPosition, persisted[j].createdAt)
+			journalEvent.SetMetadata(eventMetadata[j])
+			persistedEvents[i][j] = journalEvent
+		}
+		versions[aggregate] = persisted[len(persisted)-1].aggregateVersion
+	}
+
+	// Commit.
+	m.records = append(m.records, records...)
+	maps.Copy(m.versions, versions)
+	m.constraints = constraints
+	m.lookups = lookups
+	m.position = position
+	return persistedEvents, nil
+}
+
+func applyUniqueConstraints(constraints map[constraintKey]eventing.AggregateID, intent eventing.AggregateChangeIntent, hasher eventing.LookupHasher) error {
+	for _, event := range intent.Events() {
+		adder, ok := event.(eventing.UniqueConstraintAdder)
+		if !ok {
+			continue
+		}
+		for _, toAdd := range adder.UniqueConstraintsToAdd() {
+			toAdd := toAdd.Protect(hasher)
+			key := newConstraintKey(toAdd.ConstrainedField(), toAdd.ConstrainedValue())
+			if _, ok := constraints[key]; ok {
+				return eventing.NewUniqueConstraintError(eventing.NewUniqueConstraint(intent.AggregateID(), toAdd.ConstrainedField(), toAdd.ConstrainedValue()))
+			}
+			constraints[key] = intent.AggregateID()
+		}
+	}
+	for _, event := range intent.Events() {
+		remover, ok := event.(eventing.UniqueConstraintRemover)
+		if !ok {
+			continue
+		}
+		for _, toRemove := range remover.UniqueConstraintsToRemove() {
+			toRemove := toRemove.Protect(hasher)
+			if toRemove.ConstrainedField() == "" && toRemove.ConstrainedValue() == "" {
+				maps.DeleteFunc(constraints, func(_ constraintKey, owner eventing.AggregateID) bool {
+					return owner == intent.AggregateID()
+				})
+				continue
+			}
+			key := newConstraintKey(toRemove.ConstrainedField(), toRemove.ConstrainedValue())
+			if constraints[key] == intent.AggregateID() {
+				delete(constraints, key)
+			}
+		}
+	}
+	return nil
+}
+
+func newConstraintKey(field, value string) constraintKey {
+	return constraintKey{field: strings.ToLower(field), value: strings.ToLower(value)}
+}
+
+func applyLookups(lookups map[lookupKey]lookupEntry, intent eventing.AggregateChangeIntent, hasher eventing.LookupHasher) {
+	for _, event := range intent.Events() {
+		if lookupProvider, ok := event.(eventing.LookupProvider); ok {
+			for fieldName, fieldValue := range lookupProvider.LookupValues() {
+				lookups[lookupKey{ownerID: event.AggregateID(), fieldName: fieldName}] = lookupEntry{
+					ownerType:  event.AggregateType(),
+					fieldValue: fieldValue,
+				}
+			}
+		}
+		if lookupProvider, ok := event.(eventing.SensitiveLookupProvider); ok {
+			for fieldName, fieldValue := range lookupProvider.SensitiveLookupValues() {
+				lookups[lookupKey{ownerID: event.AggregateID(), fieldName: fieldName}] = lookupEntry{
+					ownerType:  event.AggregateType(),
+					fieldValue: eventing.LookupFieldValue(hasher.HashLookupValue(string(fieldName), string(fieldValue))),
+				}
+			}
+		}
+		if lookupRemover, ok := event.(eventing.LookupRemover); ok {
+			for _, fieldName := range lookupRemover.LookupRemoves() {
+				delete(lookups, lookupKey{ownerID: intent.AggregateID(), fieldName: fieldName})
+			}
+		}
+	}
+}
+
+func (m *memEventStore) ProduceAppend(ctx context.Context, producers ...eventing.Writer) error {
+	intents := make([]eventing.AggregateChangeIntent, len(producers))
+	for i, producer := range producers {
+		intents[i] = *producer.Changes()
+	}
+	events, err := m.Append(ctx, intents...)
+	if err != nil {
+		return err
+	}
+	for i, producer := range producers {
+		producer.Reduce(events[i])
+	}
+	return nil
+}
+
+func (m *memEventStore) Query(ctx context.Context, query eventing.JournalQuery, opts ...eventing.QueryOpts) ([]*eventing.JournalEvent, error) {
+	m.mu.RLock()
+	var matches []*record
+	for _, r := range m.records {
+		if query.Limit() > 0 && len(matches) == query.Limit() {
+			break
+		}
+		if matchesQuery(r, query) {
+			matches = append(matches, r)
+		}
+	}
+	m.mu.RUnlock()
+
+	journalEvents := make([]*eventing.JournalEvent, len(matches))
+	for i, r := range matches {
+		journalEvent, err := m.mapper.MapFrom(r.aggregateID, r.aggregateType, r.eventVersion, r.eventType, r.id, r.aggregateVersion, r.journalPosition, r.createdAt, r.payload)
+		if err != nil {
+			return nil, err
+		}
+		// The metadata is decrypted in place, so the record has to keep its own copy.
+		journalEvent.SetMetadata(cloneMetadata(r.metadata))
+		journalEvents[i] = journalEvent
+	}
+	if err := m.crypto.DecryptEvents(ctx, eventing.JournalEventsWithMetadata(journalEvents)); err != nil {
+		return nil, fmt.Errorf("failed to decrypt events: %w", err)
+	}
+	return journalEvents, nil
+}
+
+func (m *memEventStore) Count(ctx context.Context, query eventing.JournalQuery, opts ...eventing.QueryOpts) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var count int
+	for _, r := range m.records {
+		if matchesQuery(r, query) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// matchesQuery evaluates the query the same way the postgres implementation translates it into SQL.
+func matchesQuery(r *record, query eventing.JournalQuery) bool {
+	if after := query.JournalPositionAfter(); after != nil && !r.journalPosition.After(*after) {
+		return false
+	}
+	aggQuery, ok := query.AggQueriesByType()[r.aggregateType]
+	if !ok {
+		return false
+	}
+	if aggQuery.ID() != "" && aggQuery.ID() != r.aggregateID {
+		return false
+	}
+	if aggQuery.Version() > 0 && r.aggregateVersion < aggQuery.Version() {
+		return false
+	}
+	if len(aggQuery.Events()) > 0 {
+		for _, eventType := range aggQuery.Events() {
+			if eventType == r.eventType {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (m *memEventStore) QueryIter(ctx context.Context, query eventing.JournalQuery, opts ...eventing.QueryOpts) iter.Seq2[*eventing.JournalEvent, error] {
+	return func(yield func(*eventing.JournalEvent, error) bool) {
+		events, err := m.Query(ctx, query, opts...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, event := range events {
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (m *memEventStore) View(ctx context.Context, view eventing.JournalViewer) error {
+	events, err := m.Query(ctx, view.Query())
+	if err != nil {
+		return err
+	}
+	view.Reduce(events)
+	return nil
+}
+
+func (m *memEventStore) Lookup(ctx context.Context, opts eventing.LookupOpts) (*eventing.LookupFieldValue, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.lookups[lookupKey{ownerID: opts.AggregateID, fieldName: opts.FieldName}]
+	if !ok {
+		return nil, eventing.ErrValueNotFound
+	}
+	value := entry.fieldValue
+	return &value, nil
+}
+
+func (m *memEventStore) OwnerLookup(ctx context.Context, opts eventing.LookupOpts) (eventing.AggregateID, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	fieldValue := opts.FieldValue
+	if opts.Sensitive {
+		fieldValue = eventing.LookupFieldValue(m.crypto.HashLookupValue(string(opts.FieldName), string(opts.FieldValue)))
+	}
+	for key, entry := range m.lookups {
+		if entry.ownerType == opts.AggregateType && key.fieldName == opts.FieldName && entry.fieldValue == fieldValue {
+			return key.ownerID, nil
+		}
+	}
+	return "", eventing.ErrOwnerNotFound
+}
+
+func (m *memEventStore) AddHook(hook eventing.Hook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+func cloneMetadata(metadata *eventing.Metadata) *eventing.Metadata {
+	if metadata == nil {
+		return nil
+	}
+	md := *metadata
+	return &md
+}
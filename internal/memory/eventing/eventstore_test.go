@@ -0,0 +1,16 @@
+package eventing
+
+import (
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/eventing/eventingtest"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func Test_memEventStore_Conformance(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	eventingtest.RunConformance(t, func(t *testing.T, mapper eventing.JournalEventMapper, crypto eventing.EventCrypto) eventing.EventStore {
+		return NewEventStore(log, mapper, crypto)
+	})
+}
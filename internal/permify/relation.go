@@ -15,10 +15,29 @@ type relationStore struct {
 	log    *slog.Logger
 }
 
+// relationReadPageSize is the number of tuples read per request.
+const relationReadPageSize = 100
+
+// entityTypes are the entities of the schema, as tuples can only be deleted by the type of their entity.
+var entityTypes = []string{
+	authz.ResourceSystemName,
+	authz.ResourceUserName,
+	authz.ResourceClubName,
+	authz.ResourceAccountName,
+	authz.ResourcePersonName,
+	authz.ResourceTeamName,
+	authz.ResourceTeamRoleName,
+	authz.ResourceTrainingName,
+}
+
 func NewRelationStore(log *slog.Logger, client *permify_grpc.Client) authz.RelationStore {
 	return &relationStore{client: client, log: log}
 }
 
+func NewRelationReader(log *slog.Logger, client *permify_grpc.Client) authz.RelationReader {
+	return &relationStore{client: client, log: log}
+}
+
 func (r *relationStore) AddRelations(ctx context.Context, relations []authz.Relation) error {
 	ctx, span := tracing.Tracer.Start(ctx, "permify.RelationStore.AddRelations")
 	defer span.End()
@@ -83,3 +102,77 @@ func (r *relationStore) RemoveRelations(ctx context.Context, relations []authz.R
 	}
 	return allErr
 }
+
+func (r *relationStore) RemoveAllRelations(ctx context.Context, typ, id string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "permify.RelationStore.RemoveAllRelations")
+	defer span.End()
+
+	r.log.Debug("Removing all permify relations", slog.String("object", typ+":"+id))
+
+	filters := []*permify_payload.TupleFilter{
+		{Entity: &permify_payload.EntityFilter{Type: typ, Ids: []string{id}}},
+		{Subject: &permify_payload.SubjectFilter{Type: typ, Ids: []string{id}}},
+	}
+	var allErr error
+	for _, filter := range filters {
+		_, err := r.client.Data.Delete(ctx, &permify_payload.DataDeleteRequest{
+			TenantId:        "t1",
+			TupleFilter:     filter,
+			AttributeFilter: &permify_payload.AttributeFilter{},
+		})
+		if err != nil {
+			allErr = errors.Join(allErr, err)
+		}
+	}
+	return allErr
+}
+
+// ClearRelations refuses to remove the relations, as permify can't do so within the transaction of a reset.
+// Otherwise, the relations would be missing until the replay in the background caught up, and a failed reset
+// couldn't be rolled back. Use the embedded engine to reset the permissions, or reconcile them instead.
+func (r *relationStore) ClearRelations(ctx context.Context) error {
+	_, span := tracing.Tracer.Start(ctx, "permify.RelationStore.ClearRelations")
+	defer span.End()
+
+	return authz.ErrRelationsNotTransactional
+}
+
+func (r *relationStore) ReadRelations(ctx context.Context, entityType string) ([]authz.Relation, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "permify.RelationStore.ReadRelations")
+	defer span.End()
+
+	var (
+		relations []authz.Relation
+		token     string
+	)
+	for {
+		res, err := r.client.Data.ReadRelationships(ctx, &permify_payload.RelationshipReadRequest{
+			TenantId:        "t1",
+			Metadata:        &permify_payload.RelationshipReadRequestMetadata{},
+			Filter:          &permify_payload.TupleFilter{Entity: &permify_payload.EntityFilter{Type: entityType}},
+			PageSize:        relationReadPageSize,
+			ContinuousToken: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, tuple := range res.Tuples {
+			if tuple.Subject.Relation != "" {
+				// Subject sets are not written by the projector and cannot be expressed as relation.
+				r.log.Warn("Skipping permify subject set relation", slog.String("entity", tuple.Entity.Type+":"+tuple.Entity.Id), slog.String("relation", tuple.Relation))
+				continue
+			}
+			relations = append(relations, authz.Relation{
+				SubjectType: tuple.Subject.Type,
+				SubjectID:   tuple.Subject.Id,
+				EntityType:  tuple.Entity.Type,
+				EntityID:    tuple.Entity.Id,
+				Relation:    tuple.Relation,
+			})
+		}
+		if res.ContinuousToken == "" || len(res.Tuples) == 0 {
+			return relations, nil
+		}
+		token = res.ContinuousToken
+	}
+}
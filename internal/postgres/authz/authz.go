@@ -0,0 +1,163 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+)
+
+// authorizer evaluates the schema in-process over the relations of a RelationStore, instead of asking permify.
+type authorizer struct {
+	engine *engine
+	log    *slog.Logger
+}
+
+func NewAuthorizer(log *slog.Logger, store *RelationStore) authz.Authorizer {
+	return &authorizer{engine: &engine{schema: store.schema, tuples: store}, log: log}
+}
+
+func (a *authorizer) Authorize(ctx context.Context, action string, resource *authz.Resource) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.Authorizer.Authorize")
+	defer span.End()
+
+	// Extract the authentication principal from the context.
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	a.log.
+		With(slog.String("subject", fmt.Sprintf("%s:%s", authz.ResourceUserName, principal.AccountID))).
+		With(slog.String("permission", action)).
+		With(slog.String("entity", fmt.Sprintf("%s:%s", resource.Name, resource.ID))).
+		Debug("Authorizing")
+
+	isAllowed, err := a.engine.check(ctx, object{typ: resource.Name, id: resource.ID}, action, principalSubject(principal))
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return authz.ErrUnauthorized
+	}
+	span.AddEvent("Permissions evaluated", trace.WithAttributes(attribute.Bool("allowed", isAllowed)))
+	if isAllowed {
+		return nil
+	}
+	return authz.ErrUnauthorized
+}
+
+func (a *authorizer) AuthorizedEntities(ctx context.Context, action, resourceName string) (authz.EntityIDSet, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.Authorizer.AuthorizedEntities")
+	defer span.End()
+
+	// Extract the authentication principal from the context.
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	a.log.
+		With(slog.String("subject", fmt.Sprintf("%s:%s", authz.ResourceUserName, principal.AccountID))).
+		With(slog.String("permission", action)).
+		With(slog.String("entity_type", resourceName)).
+		Debug("Listing authorized entities")
+
+	ids, err := a.engine.lookup(ctx, resourceName, action, principalSubject(principal))
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return nil, authz.ErrUnauthorized
+	}
+	return authz.EntityIDSet(ids), nil
+}
+
+func (a *authorizer) RequiredActingOperator(ctx context.Context, personID *domain.PersonID) (domain.Operator, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.Authorizer.RequiredActingOperator")
+	defer span.End()
+
+	// Extract the authentication principal from the context.
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Operator{}, domain.ErrUnauthenticated
+	}
+
+	// Only the root principal can act without a person ID.
+	if personID == nil && principal.Role != domain.PrincipalRoleRoot {
+		return domain.Operator{}, domain.ErrMissingSubject
+	}
+
+	return a.OptionalActingOperator(ctx, personID)
+}
+
+func (a *authorizer) OptionalActingOperator(ctx context.Context, personID *domain.PersonID) (domain.Operator, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.Authorizer.OptionalActingOperator")
+	defer span.End()
+
+	// Extract the authentication principal from the context.
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Operator{}, domain.ErrUnauthenticated
+	}
+
+	// If we're not acting on behalf of someone, we do not need to authorize.
+	if personID == nil {
+		return domain.NewOperator(principal.AccountID, nil), nil
+	}
+
+	a.log.
+		With(slog.String("subject", fmt.Sprintf("%s:%s", authz.ResourceUserName, principal.AccountID))).
+		With(slog.String("permission", authz.RelationUser)).
+		With(slog.String("entity", fmt.Sprintf("%s:%s", authz.ResourcePersonName, *personID))).
+		Debug("Authorizing operator")
+
+	isAllowed, err := a.engine.check(ctx, object{typ: authz.ResourcePersonName, id: string(*personID)}, authz.RelationUser, principalSubject(principal))
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return domain.Operator{}, authz.ErrUnauthorized
+	}
+	if !isAllowed {
+		return domain.Operator{}, authz.ErrUnauthorized
+	}
+
+	return domain.NewOperator(principal.AccountID, personID), nil
+}
+
+func (a *authorizer) Permissions(ctx context.Context, resource *authz.Resource) (authz.PermissionsSet, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.Authorizer.Permissions")
+	defer span.End()
+
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	a.log.
+		With(slog.String("subject", fmt.Sprintf("%s:%s", authz.ResourceUserName, principal.AccountID))).
+		With(slog.String("permission", authz.RelationUser)).
+		Debug("Requesting permissions")
+
+	// Like permify, report the relations of the subject as well as its permissions and actions.
+	names, err := a.engine.names(resource.Name)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return nil, authz.ErrUnauthorized
+	}
+	permissions := make(authz.PermissionsSet)
+	for _, name := range names {
+		isAllowed, err := a.engine.check(ctx, object{typ: resource.Name, id: resource.ID}, name, principalSubject(principal))
+		if err != nil {
+			tracing.RecordError(ctx, err)
+			return nil, authz.ErrUnauthorized
+		}
+		if isAllowed {
+			permissions[name] = struct{}{}
+		}
+	}
+	return permissions, nil
+}
+
+func principalSubject(principal *domain.Principal) object {
+	return object{typ: authz.ResourceUserName, id: string(principal.AccountID)}
+}
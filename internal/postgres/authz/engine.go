@@ -0,0 +1,210 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// maxDepth bounds the nesting of relations and permissions an evaluation may follow, like the depth of permify.
+const maxDepth = 30
+
+var errDepthExceeded = errors.New("maximum evaluation depth exceeded")
+
+// object is an entity or a subject of a relation.
+type object struct {
+	typ string
+	id  string
+}
+
+func (o object) String() string {
+	return o.typ + ":" + o.id
+}
+
+type idSet map[string]struct{}
+
+// tupleReader reads the stored relation tuples during an evaluation.
+type tupleReader interface {
+	// subjects returns the subjects related to the entity.
+	subjects(ctx context.Context, entity object, relation string) ([]object, error)
+	// entities returns the IDs of the entities of the type that are related to one of the subjects.
+	entities(ctx context.Context, entityType, relation, subjectType string, subjectIDs []string) ([]string, error)
+}
+
+// engine evaluates the permissions of a schema over the stored relation tuples.
+type engine struct {
+	schema *Schema
+	tuples tupleReader
+}
+
+// check reports whether the subject is related to the entity, or has the permission on it.
+func (e *engine) check(ctx context.Context, entity object, name string, subject object) (bool, error) {
+	return e.checkDepth(ctx, entity, name, subject, maxDepth)
+}
+
+func (e *engine) checkDepth(ctx context.Context, entity object, name string, subject object, depth int) (bool, error) {
+	if depth == 0 {
+		return false, errDepthExceeded
+	}
+	def, ok := e.schema.entities[entity.typ]
+	if !ok {
+		return false, fmt.Errorf("unknown entity %s", entity.typ)
+	}
+	if permission, ok := def.permissions[name]; ok {
+		return e.checkExpr(ctx, entity, permission, subject, depth-1)
+	}
+	subjectTypes, ok := def.relations[name]
+	if !ok {
+		return false, fmt.Errorf("unknown relation or permission %s of %s", name, entity.typ)
+	}
+	if !slices.Contains(subjectTypes, subject.typ) {
+		return false, nil
+	}
+	subjects, err := e.tuples.subjects(ctx, entity, name)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(subjects, subject), nil
+}
+
+func (e *engine) checkExpr(ctx context.Context, entity object, ex expr, subject object, depth int) (bool, error) {
+	switch ex := ex.(type) {
+	case op:
+		for _, operand := range ex.operands {
+			allowed, err := e.checkExpr(ctx, entity, operand, subject, depth)
+			if err != nil {
+				return false, err
+			}
+			// Short-circuit once the outcome is decided.
+			if allowed == (ex.typ == opOr) {
+				return allowed, nil
+			}
+		}
+		return ex.typ == opAnd, nil
+	case ref:
+		if ex.arrow == "" {
+			return e.checkDepth(ctx, entity, ex.name, subject, depth)
+		}
+		related, err := e.tuples.subjects(ctx, entity, ex.name)
+		if err != nil {
+			return false, err
+		}
+		for _, r := range related {
+			if def, ok := e.schema.entities[r.typ]; !ok || !def.has(ex.arrow) {
+				continue
+			}
+			allowed, err := e.checkDepth(ctx, r, ex.arrow, subject, depth)
+			if err != nil {
+				return false, err
+			}
+			if allowed {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown expression %T", ex)
+}
+
+// lookup returns the IDs of the entities of the type the subject is related to, or has the permission on.
+func (e *engine) lookup(ctx context.Context, entityType, name string, subject object) (idSet, error) {
+	return e.lookupDepth(ctx, entityType, name, subject, maxDepth)
+}
+
+func (e *engine) lookupDepth(ctx context.Context, entityType, name string, subject object, depth int) (idSet, error) {
+	if depth == 0 {
+		return nil, errDepthExceeded
+	}
+	def, ok := e.schema.entities[entityType]
+	if !ok {
+		return nil, fmt.Errorf("unknown entity %s", entityType)
+	}
+	if permission, ok := def.permissions[name]; ok {
+		return e.lookupExpr(ctx, def, permission, subject, depth-1)
+	}
+	subjectTypes, ok := def.relations[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown relation or permission %s of %s", name, entityType)
+	}
+	if !slices.Contains(subjectTypes, subject.typ) {
+		return idSet{}, nil
+	}
+	ids, err := e.tuples.entities(ctx, entityType, name, subject.typ, []string{subject.id})
+	if err != nil {
+		return nil, err
+	}
+	return newIDSet(ids), nil
+}
+
+func (e *engine) lookupExpr(ctx context.Context, def *entityDefinition, ex expr, subject object, depth int) (idSet, error) {
+	switch ex := ex.(type) {
+	case op:
+		var res idSet
+		for i, operand := range ex.operands {
+			ids, err := e.lookupExpr(ctx, def, operand, subject, depth)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case i == 0:
+				res = ids
+			case ex.typ == opOr:
+				for id := range ids {
+					res[id] = struct{}{}
+				}
+			default:
+				for id := range res {
+					if _, ok := ids[id]; !ok {
+						delete(res, id)
+					}
+				}
+			}
+		}
+		return res, nil
+	case ref:
+		if ex.arrow == "" {
+			return e.lookupDepth(ctx, def.name, ex.name, subject, depth)
+		}
+		res := make(idSet)
+		for _, subjectType := range def.relations[ex.name] {
+			if !e.schema.entities[subjectType].has(ex.arrow) {
+				continue
+			}
+			related, err := e.lookupDepth(ctx, subjectType, ex.arrow, subject, depth)
+			if err != nil {
+				return nil, err
+			}
+			if len(related) == 0 {
+				continue
+			}
+			ids, err := e.tuples.entities(ctx, def.name, ex.name, subjectType, slices.Collect(maps.Keys(related)))
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range ids {
+				res[id] = struct{}{}
+			}
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unknown expression %T", ex)
+}
+
+// names returns all relations, permissions and actions of the entity type.
+func (e *engine) names(entityType string) ([]string, error) {
+	def, ok := e.schema.entities[entityType]
+	if !ok {
+		return nil, fmt.Errorf("unknown entity %s", entityType)
+	}
+	return def.names, nil
+}
+
+func newIDSet(ids []string) idSet {
+	set := make(idSet, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,174 @@
+package authz
+
+import (
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"gopkg.in/yaml.v3"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// schemaTests are the scenarios of permify/tests.yaml, which permify validates its schema with.
+type schemaTests struct {
+	Relationships []string `yaml:"relationships"`
+	Scenarios     []struct {
+		Name   string `yaml:"name"`
+		Checks []struct {
+			Entity     string          `yaml:"entity"`
+			Subject    string          `yaml:"subject"`
+			Assertions map[string]bool `yaml:"assertions"`
+		} `yaml:"checks"`
+		EntityFilters []struct {
+			EntityType string              `yaml:"entity_type"`
+			Subject    string              `yaml:"subject"`
+			Assertions map[string][]string `yaml:"assertions"`
+		} `yaml:"entity_filters"`
+	} `yaml:"scenarios"`
+}
+
+func TestEngineSchemaScenarios(t *testing.T) {
+	schema, err := LoadSchema("../../../permify/schema.perm")
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+	content, err := os.ReadFile("../../../permify/tests.yaml")
+	if err != nil {
+		t.Fatalf("failed to read tests: %v", err)
+	}
+	var tests schemaTests
+	if err := yaml.Unmarshal(content, &tests); err != nil {
+		t.Fatalf("failed to parse tests: %v", err)
+	}
+
+	var tuples memTuples
+	for _, tuple := range tests.Relationships {
+		relation := parseTuple(t, tuple)
+		if err := schema.validateRelation(relation); err != nil {
+			t.Fatalf("invalid relationship %s: %v", tuple, err)
+		}
+		tuples = append(tuples, relation)
+	}
+	e := &engine{schema: schema, tuples: tuples}
+	ctx := context.Background()
+
+	for _, scenario := range tests.Scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			for _, check := range scenario.Checks {
+				for permission, expected := range check.Assertions {
+					allowed, err := e.check(ctx, parseObject(t, check.Entity), permission, parseObject(t, check.Subject))
+					if err != nil {
+						t.Fatalf("failed to check %s of %s on %s: %v", permission, check.Subject, check.Entity, err)
+					}
+					if allowed != expected {
+						t.Errorf("expected %s of %s on %s to be %t", permission, check.Subject, check.Entity, expected)
+					}
+				}
+			}
+			for _, filter := range scenario.EntityFilters {
+				for permission, expected := range filter.Assertions {
+					ids, err := e.lookup(ctx, filter.EntityType, permission, parseObject(t, filter.Subject))
+					if err != nil {
+						t.Fatalf("failed to look up %s of %s on %s: %v", permission, filter.Subject, filter.EntityType, err)
+					}
+					if actual := slices.Sorted(maps.Keys(ids)); !slices.Equal(actual, expected) {
+						t.Errorf("expected %s of %s on %s to be %v, got %v", permission, filter.Subject, filter.EntityType, expected, actual)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown subject":    "entity team { relation owner @club }",
+		"unknown relation":   "entity user {} entity team { relation member @user action view = admin }",
+		"unresolved arrow":   "entity user {} entity team { relation member @user action view = member.edit }",
+		"duplicate name":     "entity user {} entity team { relation member @user permission member = member }",
+		"subject relation":   "entity user {} entity team { relation member @user#self }",
+		"unclosed parens":    "entity user {} entity team { relation member @user action view = (member or member }",
+		"unsupported clause": "entity user { attribute public boolean }",
+	}
+	for name, src := range tests {
+		if _, err := ParseSchema(src); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEngineAndPrecedence(t *testing.T) {
+	schema, err := ParseSchema(`
+entity user {}
+
+entity team {
+    // "and" binds tighter than "or".
+    relation admin @user
+    relation member @user
+    relation banned @user
+
+    action view = admin or member and banned
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	e := &engine{schema: schema, tuples: memTuples{
+		{EntityType: "team", EntityID: "1", Relation: "member", SubjectType: "user", SubjectID: "1"},
+		{EntityType: "team", EntityID: "1", Relation: "member", SubjectType: "user", SubjectID: "2"},
+		{EntityType: "team", EntityID: "1", Relation: "banned", SubjectType: "user", SubjectID: "2"},
+	}}
+	for subject, expected := range map[string]bool{"1": false, "2": true} {
+		allowed, err := e.check(context.Background(), object{typ: "team", id: "1"}, "view", object{typ: "user", id: subject})
+		if err != nil {
+			t.Fatalf("failed to check: %v", err)
+		}
+		if allowed != expected {
+			t.Errorf("expected view of user:%s to be %t", subject, expected)
+		}
+	}
+}
+
+// memTuples keeps the relation tuples in memory.
+type memTuples []authz.Relation
+
+func (m memTuples) subjects(_ context.Context, entity object, relation string) ([]object, error) {
+	var subjects []object
+	for _, r := range m {
+		if r.EntityType == entity.typ && r.EntityID == entity.id && r.Relation == relation {
+			subjects = append(subjects, object{typ: r.SubjectType, id: r.SubjectID})
+		}
+	}
+	return subjects, nil
+}
+
+func (m memTuples) entities(_ context.Context, entityType, relation, subjectType string, subjectIDs []string) ([]string, error) {
+	var ids []string
+	for _, r := range m {
+		if r.EntityType == entityType && r.Relation == relation && r.SubjectType == subjectType && slices.Contains(subjectIDs, r.SubjectID) {
+			ids = append(ids, r.EntityID)
+		}
+	}
+	return ids, nil
+}
+
+// parseTuple parses a tuple like "team:1#owner@club:1".
+func parseTuple(t *testing.T, tuple string) authz.Relation {
+	entity, rest, _ := strings.Cut(tuple, "#")
+	relation, subject, ok := strings.Cut(rest, "@")
+	if !ok {
+		t.Fatalf("invalid tuple %s", tuple)
+	}
+	e, s := parseObject(t, entity), parseObject(t, subject)
+	return authz.Relation{EntityType: e.typ, EntityID: e.id, Relation: relation, SubjectType: s.typ, SubjectID: s.id}
+}
+
+func parseObject(t *testing.T, s string) object {
+	typ, id, ok := strings.Cut(s, ":")
+	if !ok {
+		t.Fatalf("invalid object %s", s)
+	}
+	return object{typ: typ, id: id}
+}
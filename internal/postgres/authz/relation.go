@@ -0,0 +1,156 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"log/slog"
+)
+
+var (
+	_ authz.RelationStore  = (*RelationStore)(nil)
+	_ authz.RelationReader = (*RelationStore)(nil)
+	_ tupleReader          = (*RelationStore)(nil)
+)
+
+// RelationStore stores the relations in the relation_tuple table.
+// Within the transaction of a postgres projection, the relations are written along with its checkpoint.
+type RelationStore struct {
+	pool   *pgxpool.Pool
+	schema *Schema
+	log    *slog.Logger
+}
+
+func NewRelationStore(log *slog.Logger, pool *pgxpool.Pool, schema *Schema) *RelationStore {
+	return &RelationStore{pool: pool, schema: schema, log: log}
+}
+
+func (s *RelationStore) AddRelations(ctx context.Context, relations []authz.Relation) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.RelationStore.AddRelations")
+	defer span.End()
+
+	for _, relation := range relations {
+		if err := s.schema.validateRelation(relation); err != nil {
+			return err
+		}
+		s.log.Debug("Adding relation", slog.String("relation", relation.String()))
+	}
+	_, err := postgres.GetDBFromContext(ctx, s.pool).Exec(ctx, `
+INSERT INTO relation_tuple (entity_type, entity_id, relation, subject_type, subject_id)
+SELECT * FROM unnest($1::TEXT[], $2::TEXT[], $3::TEXT[], $4::TEXT[], $5::TEXT[])
+ON CONFLICT DO NOTHING
+`, relationColumns(relations)...)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return fmt.Errorf("failed to add relations: %w", err)
+	}
+	return nil
+}
+
+func (s *RelationStore) RemoveRelations(ctx context.Context, relations []authz.Relation) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.RelationStore.RemoveRelations")
+	defer span.End()
+
+	for _, relation := range relations {
+		s.log.Debug("Removing relation", slog.String("relation", relation.String()))
+	}
+	_, err := postgres.GetDBFromContext(ctx, s.pool).Exec(ctx, `
+DELETE FROM relation_tuple t
+USING unnest($1::TEXT[], $2::TEXT[], $3::TEXT[], $4::TEXT[], $5::TEXT[]) AS r(entity_type, entity_id, relation, subject_type, subject_id)
+WHERE t.entity_type = r.entity_type AND t.entity_id = r.entity_id AND t.relation = r.relation
+AND t.subject_type = r.subject_type AND t.subject_id = r.subject_id
+`, relationColumns(relations)...)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return fmt.Errorf("failed to remove relations: %w", err)
+	}
+	return nil
+}
+
+func (s *RelationStore) RemoveAllRelations(ctx context.Context, typ, id string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.RelationStore.RemoveAllRelations")
+	defer span.End()
+
+	s.log.Debug("Removing all relations", slog.String("object", typ+":"+id))
+
+	_, err := postgres.GetDBFromContext(ctx, s.pool).Exec(ctx, `
+DELETE FROM relation_tuple
+WHERE (entity_type = $1 AND entity_id = $2) OR (subject_type = $1 AND subject_id = $2)
+`, typ, id)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return fmt.Errorf("failed to remove all relations of %s:%s: %w", typ, id, err)
+	}
+	return nil
+}
+
+func (s *RelationStore) ClearRelations(ctx context.Context) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.RelationStore.ClearRelations")
+	defer span.End()
+
+	s.log.Debug("Removing all relations")
+
+	if _, err := postgres.GetDBFromContext(ctx, s.pool).Exec(ctx, "DELETE FROM relation_tuple"); err != nil {
+		tracing.RecordError(ctx, err)
+		return fmt.Errorf("failed to clear relations: %w", err)
+	}
+	return nil
+}
+
+func (s *RelationStore) ReadRelations(ctx context.Context, entityType string) ([]authz.Relation, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.RelationStore.ReadRelations")
+	defer span.End()
+
+	rows, err := s.pool.Query(ctx, `
+SELECT subject_type, subject_id, entity_type, entity_id, relation FROM relation_tuple WHERE entity_type = $1
+`, entityType)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[authz.Relation])
+}
+
+func (s *RelationStore) subjects(ctx context.Context, entity object, relation string) ([]object, error) {
+	rows, err := postgres.GetDBFromContext(ctx, s.pool).Query(ctx, `
+SELECT subject_type, subject_id FROM relation_tuple WHERE entity_type = $1 AND entity_id = $2 AND relation = $3
+`, entity.typ, entity.id, relation)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (object, error) {
+		var subject object
+		return subject, row.Scan(&subject.typ, &subject.id)
+	})
+}
+
+func (s *RelationStore) entities(ctx context.Context, entityType, relation, subjectType string, subjectIDs []string) ([]string, error) {
+	rows, err := postgres.GetDBFromContext(ctx, s.pool).Query(ctx, `
+SELECT DISTINCT entity_id FROM relation_tuple
+WHERE entity_type = $1 AND relation = $2 AND subject_type = $3 AND subject_id = ANY($4)
+`, entityType, relation, subjectType, subjectIDs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// relationColumns returns the columns of the relations as arrays to be unnested.
+func relationColumns(relations []authz.Relation) []any {
+	entityTypes := make([]string, len(relations))
+	entityIDs := make([]string, len(relations))
+	names := make([]string, len(relations))
+	subjectTypes := make([]string, len(relations))
+	subjectIDs := make([]string, len(relations))
+	for i, relation := range relations {
+		entityTypes[i] = relation.EntityType
+		entityIDs[i] = relation.EntityID
+		names[i] = relation.Relation
+		subjectTypes[i] = relation.SubjectType
+		subjectIDs[i] = relation.SubjectID
+	}
+	return []any{entityTypes, entityIDs, names, subjectTypes, subjectIDs}
+}
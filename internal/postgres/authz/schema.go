@@ -0,0 +1,372 @@
+package authz
+
+import (
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Schema is a parsed permify schema.
+// Only the subset used by permify/schema.perm is supported: relations to plain subject types as well as permissions
+// and actions combining relations, permissions and arrow traversals with "or" and "and".
+type Schema struct {
+	entities map[string]*entityDefinition
+}
+
+type entityDefinition struct {
+	name string
+	// relations are the allowed subject types of each relation.
+	relations map[string][]string
+	// permissions are the expressions of the permissions and actions, which only differ by name.
+	permissions map[string]expr
+	// names are all relations, permissions and actions in the order of their declaration.
+	names []string
+}
+
+// expr is a node of a permission expression, either a ref or an op.
+type expr interface {
+	isExpr()
+}
+
+// ref references a relation or permission of the entity itself, or one of the entities of a relation via an arrow,
+// e.g. "owner.edit".
+type ref struct {
+	name  string
+	arrow string
+}
+
+type opType string
+
+const (
+	opOr  opType = "or"
+	opAnd opType = "and"
+)
+
+type op struct {
+	typ      opType
+	operands []expr
+}
+
+func (ref) isExpr() {}
+func (op) isExpr()  {}
+
+// LoadSchema reads and parses the schema file.
+func LoadSchema(path string) (*Schema, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	return ParseSchema(string(src))
+}
+
+// ParseSchema parses the schema and checks that all referenced relations and permissions exist.
+func ParseSchema(src string) (*Schema, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	s := &Schema{entities: make(map[string]*entityDefinition)}
+	for !p.done() {
+		def, err := p.parseEntity()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.entities[def.name]; ok {
+			return nil, fmt.Errorf("entity %s is declared twice", def.name)
+		}
+		s.entities[def.name] = def
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) validate() error {
+	for _, def := range s.entities {
+		for name, subjectTypes := range def.relations {
+			for _, subjectType := range subjectTypes {
+				if _, ok := s.entities[subjectType]; !ok {
+					return fmt.Errorf("relation %s.%s references unknown entity %s", def.name, name, subjectType)
+				}
+			}
+		}
+		for name, e := range def.permissions {
+			if err := s.validateExpr(def, e); err != nil {
+				return fmt.Errorf("invalid permission %s.%s: %w", def.name, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateExpr(def *entityDefinition, e expr) error {
+	switch e := e.(type) {
+	case op:
+		for _, operand := range e.operands {
+			if err := s.validateExpr(def, operand); err != nil {
+				return err
+			}
+		}
+	case ref:
+		if e.arrow == "" {
+			if !def.has(e.name) {
+				return fmt.Errorf("unknown relation or permission %s", e.name)
+			}
+			return nil
+		}
+		subjectTypes, ok := def.relations[e.name]
+		if !ok {
+			return fmt.Errorf("unknown relation %s", e.name)
+		}
+		// The arrow only needs to resolve for one of the subject types, the others are skipped during evaluation.
+		if !slices.ContainsFunc(subjectTypes, func(subjectType string) bool { return s.entities[subjectType].has(e.arrow) }) {
+			return fmt.Errorf("none of the subjects of %s has the relation or permission %s", e.name, e.arrow)
+		}
+	}
+	return nil
+}
+
+// validateRelation checks that the schema allows the relation to be stored.
+func (s *Schema) validateRelation(relation authz.Relation) error {
+	def, ok := s.entities[relation.EntityType]
+	if !ok {
+		return fmt.Errorf("unknown entity %s in relation %s", relation.EntityType, relation)
+	}
+	subjectTypes, ok := def.relations[relation.Relation]
+	if !ok {
+		return fmt.Errorf("unknown relation %s of %s", relation.Relation, relation.EntityType)
+	}
+	if !slices.Contains(subjectTypes, relation.SubjectType) {
+		return fmt.Errorf("relation %s of %s does not allow subjects of %s", relation.Relation, relation.EntityType, relation.SubjectType)
+	}
+	if relation.EntityID == "" || relation.SubjectID == "" {
+		return fmt.Errorf("relation %s is missing an ID", relation)
+	}
+	return nil
+}
+
+func (d *entityDefinition) has(name string) bool {
+	return slices.Contains(d.names, name)
+}
+
+func (d *entityDefinition) declare(name string) error {
+	if d.has(name) {
+		return fmt.Errorf("%s is declared twice in entity %s", name, d.name)
+	}
+	d.names = append(d.names, name)
+	return nil
+}
+
+// tokenize splits the schema into identifiers and the symbols { } = @ . ( ), dropping comments.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.ContainsRune("{}=@.()#", c):
+			tokens = append(tokens, token{text: string(c), line: line})
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{text: src[start:i], line: line, ident: true})
+		default:
+			return nil, fmt.Errorf("unexpected character %q in line %d", c, line)
+		}
+	}
+	return tokens, nil
+}
+
+type token struct {
+	text  string
+	line  int
+	ident bool
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	if p.done() {
+		return fmt.Errorf("%s at the end of the schema", fmt.Sprintf(format, args...))
+	}
+	return fmt.Errorf("%s in line %d", fmt.Sprintf(format, args...), p.peek().line)
+}
+
+func (p *parser) expect(text string) error {
+	if p.peek().text != text {
+		return p.errorf("expected %q, got %q", text, p.peek().text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	if !p.peek().ident {
+		return "", p.errorf("expected an identifier, got %q", p.peek().text)
+	}
+	return p.next().text, nil
+}
+
+func (p *parser) parseEntity() (*entityDefinition, error) {
+	if err := p.expect("entity"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	def := &entityDefinition{name: name, relations: make(map[string][]string), permissions: make(map[string]expr)}
+	for p.peek().text != "}" {
+		keyword := p.peek().text
+		switch keyword {
+		case "relation":
+			err = p.parseRelation(def)
+		case "permission", "action":
+			err = p.parsePermission(def)
+		default:
+			err = p.errorf("unsupported statement %q", keyword)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return def, p.expect("}")
+}
+
+func (p *parser) parseRelation(def *entityDefinition) error {
+	p.next()
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if err := def.declare(name); err != nil {
+		return err
+	}
+	for p.peek().text == "@" {
+		p.next()
+		subjectType, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if p.peek().text == "#" {
+			return p.errorf("subject relations of %s are not supported", subjectType)
+		}
+		def.relations[name] = append(def.relations[name], subjectType)
+	}
+	if len(def.relations[name]) == 0 {
+		return p.errorf("relation %s has no subject type", name)
+	}
+	return nil
+}
+
+func (p *parser) parsePermission(def *entityDefinition) error {
+	p.next()
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if err := def.declare(name); err != nil {
+		return err
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	e, err := p.parseOp(opOr)
+	if err != nil {
+		return err
+	}
+	def.permissions[name] = e
+	return nil
+}
+
+// parseOp parses operands joined by the operator, with "and" binding tighter than "or".
+func (p *parser) parseOp(typ opType) (expr, error) {
+	parseOperand := p.parseTerm
+	if typ == opOr {
+		parseOperand = func() (expr, error) { return p.parseOp(opAnd) }
+	}
+	operand, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []expr{operand}
+	for p.peek().text == string(typ) {
+		p.next()
+		operand, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return op{typ: typ, operands: operands}, nil
+}
+
+func (p *parser) parseTerm() (expr, error) {
+	if p.peek().text == "(" {
+		p.next()
+		e, err := p.parseOp(opOr)
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+	if p.peek().text == "not" {
+		return nil, p.errorf("exclusions are not supported")
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().text != "." {
+		return ref{name: name}, nil
+	}
+	p.next()
+	arrow, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	return ref{name: name, arrow: arrow}, nil
+}
@@ -2,8 +2,6 @@ package eventing
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
@@ -16,19 +14,37 @@ import (
 	"io"
 	"maps"
 	"slices"
+	"strings"
 )
 
 type (
 	keysByOwner map[eventing.AggregateID][]byte
+
+	encryptedEvent interface {
+		eventing.Event
+		eventing.EncryptedEvent
+	}
 )
 
+// ciphertextV1Prefix marks values that are bound to their owner, event and field.
+// Values without a prefix are v0 and were sealed without any additional data.
+// The prefix can't collide with v0 values, as ':' is not part of the base64 alphabet.
+const ciphertextV1Prefix = "v1:"
+
+// pgEventCrypto encrypts values with a data key per owner.
+// The data keys are stored wrapped with a key-encryption key of the keyring.
 type pgEventCrypto struct {
-	pool *pgxpool.Pool
+	eventing.LookupHasher
+
+	pool    *pgxpool.Pool
+	keyring *Keyring
 }
 
-func NewEventCrypto(pool *pgxpool.Pool) eventing.EventCrypto {
+func NewEventCrypto(pool *pgxpool.Pool, keyring *Keyring, hasher eventing.LookupHasher) eventing.EventCrypto {
 	return &pgEventCrypto{
-		pool: pool,
+		LookupHasher: hasher,
+		pool:         pool,
+		keyring:      keyring,
 	}
 }
 
@@ -48,9 +64,8 @@ func (p *pgEventCrypto) EncryptEvents(ctx context.Context, events []eventing.Eve
 		return fmt.Errorf("failed to load key: %w", err)
 	}
 	// Encrypt the values.
-	transformer := &aesEncryptor{keys: keys}
 	for _, event := range encEvents {
-		if err := event.AcceptCrypto(transformer); err != nil {
+		if err := event.AcceptCrypto(&aesEncryptor{keys: keys, event: event}); err != nil {
 			return fmt.Errorf("failed to encrypt value: %w", err)
 		}
 	}
@@ -73,20 +88,30 @@ func (p *pgEventCrypto) DecryptEvents(ctx context.Context, events []eventing.Eve
 		return fmt.Errorf("failed to load key: %w", err)
 	}
 	// Decrypt the values.
-	transformer := &aesDecryptor{keys: key}
 	for _, event := range encEvents {
-		if err := event.AcceptCrypto(transformer); err != nil {
+		if err := event.AcceptCrypto(&aesDecryptor{keys: key, event: event}); err != nil {
 			return fmt.Errorf("failed to decrypt value: %w", err)
 		}
 	}
 	return nil
 }
 
-func (p *pgEventCrypto) filterEncryptedEvents(events []eventing.Event) ([]eventing.AggregateID, []eventing.EncryptedEvent, error) {
-	var encryptedEvents []eventing.EncryptedEvent
+func (p *pgEventCrypto) ShredKeys(ctx context.Context, owners ...eventing.AggregateID) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pgEventCrypto.ShredKeys")
+	defer span.End()
+
+	db := postgres.GetDBFromContext(ctx, p.pool)
+	if _, err := db.Exec(ctx, "DELETE FROM keys WHERE owner_id = ANY ($1)", owners); err != nil {
+		return fmt.Errorf("failed to delete keys: %w", err)
+	}
+	return nil
+}
+
+func (p *pgEventCrypto) filterEncryptedEvents(events []eventing.Event) ([]eventing.AggregateID, []encryptedEvent, error) {
+	var encryptedEvents []encryptedEvent
 	owners := make(map[eventing.AggregateID]struct{})
 	for _, event := range events {
-		event, ok := event.(eventing.EncryptedEvent)
+		event, ok := event.(encryptedEvent)
 		if !ok {
 			continue
 		}
@@ -101,92 +126,197 @@ func (p *pgEventCrypto) filterEncryptedEvents(events []eventing.Event) ([]eventi
 
 func (p *pgEventCrypto) loadOrCreateKeys(ctx context.Context, owners []eventing.AggregateID) (keysByOwner, error) {
 	db := postgres.GetDBFromContext(ctx, p.pool)
-	rows, err := db.Query(ctx, "SELECT owner_id, key FROM keys WHERE owner_id = ANY ($1)", owners)
+	keyByOwner, err := p.loadKeys(ctx, db, owners)
 	if err != nil {
 		return nil, err
 	}
-	var (
-		key        []byte
-		ownerID    eventing.AggregateID
-		keyByOwner = make(keysByOwner)
-	)
-	_, err = pgx.ForEachRow(rows, []any{&ownerID, &key}, func() error {
-		keyByOwner[ownerID] = key
-		return nil
-	})
-	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		return nil, err
-	}
 	if len(keyByOwner) == len(owners) {
 		return keyByOwner, nil
 	}
-	missingKeysToInsert := make(map[eventing.AggregateID][]byte)
+	var (
+		missingOwners []eventing.AggregateID
+		wrappedKeys   [][]byte
+		keyID         KeyID
+	)
 	for _, owner := range owners {
-		if _, ok := keyByOwner[owner]; !ok {
-			key := make([]byte, 32)
-			if _, err := io.ReadFull(rand.Reader, key); err != nil {
-				return nil, err
-			}
-			missingKeysToInsert[owner] = key
+		if _, ok := keyByOwner[owner]; ok {
+			continue
 		}
-	}
-	if len(missingKeysToInsert) > 0 {
-		err = pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
-			_, err = tx.CopyFrom(ctx, pgx.Identifier{"keys"}, []string{"owner_id", "key"}, pgx.CopyFromSlice(len(missingKeysToInsert), func(i int) ([]interface{}, error) {
-				owner := owners[i]
-				key := missingKeysToInsert[owner]
-				return []interface{}{owner, key}, nil
-			}))
-			if err != nil {
-				return fmt.Errorf("failed to insert missing keys: %w", err)
-			}
-			return nil
-		})
-		if err != nil {
+		key := make([]byte, keySize)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
 			return nil, err
 		}
-	}
-	for owner, key := range missingKeysToInsert {
+		var wrapped []byte
+		keyID, wrapped, err = p.keyring.wrap(owner, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap key: %w", err)
+		}
 		keyByOwner[owner] = key
+		missingOwners = append(missingOwners, owner)
+		wrappedKeys = append(wrappedKeys, wrapped)
+	}
+	err = pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"keys"}, []string{"owner_id", "key_id", "key"}, pgx.CopyFromSlice(len(missingOwners), func(i int) ([]interface{}, error) {
+			return []interface{}{missingOwners[i], keyID, wrappedKeys[i]}, nil
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to insert missing keys: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return keyByOwner, nil
 }
 
 func (p *pgEventCrypto) maybeLoadKeys(ctx context.Context, owners []eventing.AggregateID) (keysByOwner, error) {
 	db := postgres.GetDBFromContext(ctx, p.pool)
-	rows, err := db.Query(ctx, "SELECT owner_id, key FROM keys WHERE owner_id = ANY ($1)", owners)
+	return p.loadKeys(ctx, db, owners)
+}
+
+// loadKeys loads and unwraps the data keys of the owners. Owners without a key are omitted.
+func (p *pgEventCrypto) loadKeys(ctx context.Context, db postgres.DB, owners []eventing.AggregateID) (keysByOwner, error) {
+	rows, err := db.Query(ctx, "SELECT owner_id, key_id, key FROM keys WHERE owner_id = ANY ($1)", owners)
 	if err != nil {
 		return nil, err
 	}
 	var (
 		key        []byte
+		keyID      *KeyID
 		ownerID    eventing.AggregateID
 		keyByOwner = make(keysByOwner)
 	)
-	_, err = pgx.ForEachRow(rows, []any{&ownerID, &key}, func() error {
-		keyByOwner[ownerID] = key
+	_, err = pgx.ForEachRow(rows, []any{&ownerID, &keyID, &key}, func() error {
+		// Keys without an ID are stored in plaintext, which must not go unnoticed.
+		if keyID == nil {
+			return fmt.Errorf("key of %s: %w", ownerID, ErrUnwrappedKey)
+		}
+		dataKey, err := p.keyring.unwrap(ownerID, *keyID, key)
+		if err != nil {
+			return err
+		}
+		keyByOwner[ownerID] = dataKey
 		return nil
 	})
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
+		return nil, err
 	}
 	return keyByOwner, nil
 }
 
+// RotateKeys wraps all data keys that are not yet wrapped with the active key-encryption key of the keyring.
+// The data keys themselves stay the same, so the event journal is not touched.
+// Returns the number of rotated keys.
+func RotateKeys(ctx context.Context, pool *pgxpool.Pool, keyring *Keyring) (int, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.RotateKeys")
+	defer span.End()
+
+	return rewrapKeys(ctx, pool, keyring, selectKeysToRotateSQL, keyring.ActiveKeyID())
+}
+
+// WrapKeys wraps the data keys that have been stored in plaintext before data keys were wrapped.
+// Such keys are rejected when they are loaded, so WrapKeys has to run on startup.
+// Returns the number of wrapped keys.
+func WrapKeys(ctx context.Context, pool *pgxpool.Pool, keyring *Keyring) (int, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.WrapKeys")
+	defer span.End()
+
+	return rewrapKeys(ctx, pool, keyring, selectUnwrappedKeysSQL)
+}
+
+// rewrapKeys wraps the keys selected by the statement with the active key-encryption key in batches.
+// The statement is passed the args followed by the size of the batch.
+func rewrapKeys(ctx context.Context, pool *pgxpool.Pool, keyring *Keyring, stmt string, args ...any) (int, error) {
+	var rotated int
+	for ctx.Err() == nil {
+		var processed int
+		err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+			rows, err := tx.Query(ctx, stmt, append(slices.Clone(args), keyRotationBatchSize)...)
+			if err != nil {
+				return err
+			}
+			type wrappedKey struct {
+				ownerID eventing.AggregateID
+				keyID   *KeyID
+				key     []byte
+			}
+			keys, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (wrappedKey, error) {
+				var key wrappedKey
+				err := row.Scan(&key.ownerID, &key.keyID, &key.key)
+				return key, err
+			})
+			if err != nil {
+				return err
+			}
+
+			batch := &pgx.Batch{}
+			for _, key := range keys {
+				dataKey := key.key
+				if key.keyID != nil {
+					dataKey, err = keyring.unwrap(key.ownerID, *key.keyID, key.key)
+					if err != nil {
+						return err
+					}
+				}
+				keyID, wrapped, err := keyring.wrap(key.ownerID, dataKey)
+				if err != nil {
+					return err
+				}
+				batch.Queue(updateRotatedKeySQL, keyID, wrapped, key.ownerID)
+			}
+			if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+				return fmt.Errorf("failed to update rotated keys: %w", err)
+			}
+			processed = len(keys)
+			return nil
+		})
+		if err != nil {
+			return rotated, err
+		}
+		rotated += processed
+		if processed < keyRotationBatchSize {
+			break
+		}
+	}
+	return rotated, ctx.Err()
+}
+
+const keyRotationBatchSize = 500
+
+const selectKeysToRotateSQL = `
+SELECT owner_id, key_id, key
+FROM keys
+WHERE key_id IS DISTINCT FROM $1
+LIMIT $2
+FOR UPDATE SKIP LOCKED
+`
+
+const selectUnwrappedKeysSQL = `
+SELECT owner_id, key_id, key
+FROM keys
+WHERE key_id IS NULL
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+const updateRotatedKeySQL = `
+UPDATE keys
+SET key_id = $1, key = $2, updated_at = NOW()
+WHERE owner_id = $3
+`
+
 type aesEncryptor struct {
-	keys keysByOwner
+	keys  keysByOwner
+	event eventing.Event
 }
 
-func (a *aesEncryptor) Transform(owner eventing.AggregateID, value *eventing.EncryptedString) error {
+func (a *aesEncryptor) Transform(owner eventing.AggregateID, field string, value *eventing.EncryptedString) error {
 	key, ok := a.keys[owner]
 	if !ok {
 		return fmt.Errorf("key for owner should have been generated already: %s", owner)
 	}
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return err
-	}
-	aead, err := cipher.NewGCM(block)
+	aead, err := newAEAD(key)
 	if err != nil {
 		return err
 	}
@@ -194,39 +324,46 @@ func (a *aesEncryptor) Transform(owner eventing.AggregateID, value *eventing.Enc
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return err
 	}
-	ciphertext := aead.Seal(nil, nonce, []byte(value.Value), nil)
-	value.Value = base64.StdEncoding.EncodeToString(append(nonce, ciphertext...))
+	ciphertext := aead.Seal(nonce, nonce, []byte(value.Value), associatedData(owner, a.event, field))
+	value.Value = ciphertextV1Prefix + base64.StdEncoding.EncodeToString(ciphertext)
 	return nil
 }
 
-func (a *aesEncryptor) TransformWithDefault(owner eventing.AggregateID, value *eventing.EncryptedString, defaultValue string) error {
-	return a.Transform(owner, value)
+func (a *aesEncryptor) TransformWithDefault(owner eventing.AggregateID, field string, value *eventing.EncryptedString, defaultValue string) error {
+	return a.Transform(owner, field, value)
 }
 
 type aesDecryptor struct {
-	keys keysByOwner
+	keys  keysByOwner
+	event eventing.Event
 }
 
-func (a *aesDecryptor) Transform(owner eventing.AggregateID, value *eventing.EncryptedString) error {
-	return a.TransformWithDefault(owner, value, "")
+func (a *aesDecryptor) Transform(owner eventing.AggregateID, field string, value *eventing.EncryptedString) error {
+	return a.TransformWithDefault(owner, field, value, "")
 }
 
-func (a *aesDecryptor) TransformWithDefault(owner eventing.AggregateID, value *eventing.EncryptedString, defaultValue string) error {
+func (a *aesDecryptor) TransformWithDefault(owner eventing.AggregateID, field string, value *eventing.EncryptedString, defaultValue string) error {
+	// Values that have never been encrypted can't be shredded either, see EncryptPlaintextEvents.
+	if plaintext, ok := value.CutPlaintext(); ok {
+		value.Value = plaintext
+		return nil
+	}
 	key, ok := a.keys[owner]
 	if !ok {
 		value.IsShredded = true
 		value.Value = defaultValue
 		return nil
 	}
-	ciphertext, err := base64.StdEncoding.DecodeString(value.Value)
-	if err != nil {
-		return err
+	encoded, isV1 := strings.CutPrefix(value.Value, ciphertextV1Prefix)
+	var additionalData []byte
+	if isV1 {
+		additionalData = associatedData(owner, a.event, field)
 	}
-	block, err := aes.NewCipher(key)
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
 		return err
 	}
-	aead, err := cipher.NewGCM(block)
+	aead, err := newAEAD(key)
 	if err != nil {
 		return err
 	}
@@ -235,10 +372,16 @@ func (a *aesDecryptor) TransformWithDefault(owner eventing.AggregateID, value *e
 		return fmt.Errorf("ciphertext too short")
 	}
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to decrypt %s of %s: %w", field, a.event.EventType(), err)
 	}
 	value.Value = string(plaintext)
 	return nil
 }
+
+// associatedData binds a value to its owner, the aggregate and type of its event and its field.
+// The event version is left out, so that upcasted events can still be decrypted.
+func associatedData(owner eventing.AggregateID, event eventing.Event, field string) []byte {
+	return []byte(strings.Join([]string{string(owner), string(event.AggregateID()), string(event.EventType()), field}, "\x00"))
+}
@@ -0,0 +1,282 @@
+package eventing
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmidt/soccerbuddy/internal/core"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+)
+
+type testEncryptedEvent struct {
+	*eventing.EventBase
+
+	Secret eventing.EncryptedString `json:"secret"`
+}
+
+func newTestEncryptedEvent(id eventing.AggregateID, secret string) *testEncryptedEvent {
+	return &testEncryptedEvent{
+		EventBase: eventing.NewEventBase(id, "test", "v1", "TestEncryptedEvent"),
+		Secret:    eventing.NewEncryptedString(secret),
+	}
+}
+
+func (t *testEncryptedEvent) IsShredded() bool {
+	return t.Secret.IsShredded
+}
+
+func (t *testEncryptedEvent) DeclareOwners() []eventing.AggregateID {
+	return []eventing.AggregateID{t.AggregateID()}
+}
+
+func (t *testEncryptedEvent) AcceptCrypto(transformer eventing.CryptoTransformer) error {
+	return transformer.Transform(t.AggregateID(), "secret", &t.Secret)
+}
+
+var testHasher = eventing.NewHMACLookupHasher(newTestKey(9))
+
+func newTestKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func TestNewKeyring(t *testing.T) {
+	if _, err := NewKeyring("k1", KeyEncryptionKey{ID: "k1", Key: newTestKey(1)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewKeyring("k2", KeyEncryptionKey{ID: "k1", Key: newTestKey(1)}); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("expected unknown active key to fail, got %v", err)
+	}
+	if _, err := NewKeyring("k1", KeyEncryptionKey{ID: "k1", Key: []byte("short")}); err == nil {
+		t.Errorf("expected short key to fail")
+	}
+	if _, err := NewKeyring("k1", KeyEncryptionKey{ID: "k1", Key: newTestKey(1)}, KeyEncryptionKey{ID: "k1", Key: newTestKey(2)}); err == nil {
+		t.Errorf("expected duplicate key to fail")
+	}
+}
+
+func TestKeyring_Wrap(t *testing.T) {
+	keyring := core.Must2(NewKeyring("k2", KeyEncryptionKey{ID: "k1", Key: newTestKey(1)}, KeyEncryptionKey{ID: "k2", Key: newTestKey(2)}))
+	dataKey := newTestKey(3)
+
+	keyID, wrapped, err := keyring.wrap("owner", dataKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keyID != "k2" {
+		t.Errorf("expected key to be wrapped with active key k2, got %s", keyID)
+	}
+	if bytes.Contains(wrapped, dataKey) {
+		t.Errorf("expected wrapped key to not contain the data key")
+	}
+	unwrapped, err := keyring.unwrap("owner", keyID, wrapped)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		t.Errorf("expected unwrapped key to equal data key")
+	}
+	if _, err := keyring.unwrap("other", keyID, wrapped); err == nil {
+		t.Errorf("expected unwrapping for another owner to fail")
+	}
+	if _, err := keyring.unwrap("owner", "k3", wrapped); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("expected unknown key ID to fail, got %v", err)
+	}
+}
+
+func TestAESCrypto_AssociatedData(t *testing.T) {
+	owner := idgen.New[eventing.AggregateID]()
+	keys := keysByOwner{owner: newTestKey(1)}
+	event := newTestEncryptedEvent(owner, "secret")
+	if err := event.AcceptCrypto(&aesEncryptor{keys: keys, event: event}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(event.Secret.Value, ciphertextV1Prefix) {
+		t.Fatalf("expected v1 ciphertext, got %q", event.Secret.Value)
+	}
+
+	decrypt := func(event eventing.Event, field string, ciphertext string) (string, error) {
+		value := eventing.NewEncryptedString(ciphertext)
+		err := (&aesDecryptor{keys: keys, event: event}).Transform(owner, field, &value)
+		return value.Value, err
+	}
+	if got, err := decrypt(event, "secret", event.Secret.Value); err != nil || got != "secret" {
+		t.Errorf("expected value to be decrypted, got %q, %v", got, err)
+	}
+	if _, err := decrypt(event, "other", event.Secret.Value); err == nil {
+		t.Errorf("expected decryption as another field to fail")
+	}
+	otherEvent := &testEncryptedEvent{EventBase: eventing.NewEventBase(owner, "test", "v1", "OtherEvent")}
+	if _, err := decrypt(otherEvent, "secret", event.Secret.Value); err == nil {
+		t.Errorf("expected decryption in another event type to fail")
+	}
+	otherAggregate := newTestEncryptedEvent(idgen.New[eventing.AggregateID](), "")
+	if _, err := decrypt(otherAggregate, "secret", event.Secret.Value); err == nil {
+		t.Errorf("expected decryption in another aggregate to fail")
+	}
+
+	// Values sealed without associated data are still readable.
+	aead := core.Must2(newAEAD(keys[owner]))
+	nonce := make([]byte, aead.NonceSize())
+	v0 := base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte("legacy"), nil))
+	if got, err := decrypt(event, "secret", v0); err != nil || got != "legacy" {
+		t.Errorf("expected v0 value to be decrypted, got %q, %v", got, err)
+	}
+
+	// Values that have never been encrypted are returned as is, even without a key.
+	plaintext := eventing.NewPlaintextEncryptedString("plain")
+	if err := (&aesDecryptor{keys: keysByOwner{}, event: event}).Transform(owner, "secret", &plaintext); err != nil || plaintext.Value != "plain" || plaintext.IsShredded {
+		t.Errorf("expected plaintext value to be returned as is, got %q, %v", plaintext.Value, err)
+	}
+}
+
+func Test_pgEventCrypto_RotateKeys(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	oldKey := KeyEncryptionKey{ID: "old", Key: newTestKey(1)}
+	newKey := KeyEncryptionKey{ID: "new", Key: newTestKey(2)}
+	oldKeyring := core.Must2(NewKeyring("old", oldKey))
+
+	// Encrypt a value with a key wrapped by the old key.
+	wrappedOwner := idgen.New[eventing.AggregateID]()
+	event := newTestEncryptedEvent(wrappedOwner, "wrapped")
+	if err := NewEventCrypto(pool, oldKeyring, testHasher).EncryptEvents(ctx, []eventing.Event{event}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ciphertext := event.Secret.Value
+
+	// Encrypt a value with a legacy key that has never been wrapped.
+	legacyOwner := idgen.New[eventing.AggregateID]()
+	legacyCiphertext := insertLegacyKey(t, pool, legacyOwner, "legacy")
+
+	rotated, err := RotateKeys(ctx, pool, core.Must2(NewKeyring("new", oldKey, newKey)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotated != 2 {
+		t.Errorf("expected 2 rotated keys, got %d", rotated)
+	}
+
+	// The old key is no longer needed.
+	newCrypto := NewEventCrypto(pool, core.Must2(NewKeyring("new", newKey)), testHasher)
+	events := []eventing.Event{newTestEncryptedEvent(wrappedOwner, ""), newTestEncryptedEvent(legacyOwner, "")}
+	events[0].(*testEncryptedEvent).Secret.Value = ciphertext
+	events[1].(*testEncryptedEvent).Secret.Value = legacyCiphertext
+	if err := newCrypto.DecryptEvents(ctx, events); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := events[0].(*testEncryptedEvent).Secret.Value; got != "wrapped" {
+		t.Errorf("expected wrapped value to be decrypted, got %q", got)
+	}
+	if got := events[1].(*testEncryptedEvent).Secret.Value; got != "legacy" {
+		t.Errorf("expected legacy value to be decrypted, got %q", got)
+	}
+
+	// Keys can no longer be unwrapped with the old key only.
+	event = newTestEncryptedEvent(wrappedOwner, "")
+	event.Secret.Value = ciphertext
+	if err := NewEventCrypto(pool, oldKeyring, testHasher).DecryptEvents(ctx, []eventing.Event{event}); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("expected decryption with old key to fail, got %v", err)
+	}
+
+	// A second rotation has nothing to do.
+	rotated, err = RotateKeys(ctx, pool, core.Must2(NewKeyring("new", newKey)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotated != 0 {
+		t.Errorf("expected 0 rotated keys, got %d", rotated)
+	}
+}
+
+func Test_pgEventCrypto_WrapKeys(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	keyring := core.Must2(NewKeyring("k1", KeyEncryptionKey{ID: "k1", Key: newTestKey(1)}))
+	crypto := NewEventCrypto(pool, keyring, testHasher)
+	owner := idgen.New[eventing.AggregateID]()
+	ciphertext := insertLegacyKey(t, pool, owner, "legacy")
+
+	// Keys stored in plaintext are rejected until they are wrapped.
+	event := newTestEncryptedEvent(owner, "")
+	event.Secret.Value = ciphertext
+	if err := crypto.DecryptEvents(ctx, []eventing.Event{event}); !errors.Is(err, ErrUnwrappedKey) {
+		t.Fatalf("expected unwrapped key to be rejected, got %v", err)
+	}
+
+	wrapped, err := WrapKeys(ctx, pool, keyring)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped != 1 {
+		t.Errorf("expected 1 wrapped key, got %d", wrapped)
+	}
+	if err := crypto.DecryptEvents(ctx, []eventing.Event{event}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Secret.Value != "legacy" {
+		t.Errorf("expected legacy value to be decrypted, got %q", event.Secret.Value)
+	}
+
+	// Wrapped keys are left alone.
+	wrapped, err = WrapKeys(ctx, pool, keyring)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped != 0 {
+		t.Errorf("expected 0 wrapped keys, got %d", wrapped)
+	}
+}
+
+// insertLegacyKey stores a data key of the owner in plaintext, as it has been done before keys were wrapped,
+// and returns the value encrypted with it.
+func insertLegacyKey(t *testing.T, pool *pgxpool.Pool, owner eventing.AggregateID, value string) string {
+	t.Helper()
+	key := newTestKey(3)
+	if _, err := pool.Exec(context.Background(), "INSERT INTO keys (owner_id, key) VALUES ($1, $2)", owner, key); err != nil {
+		t.Fatalf("failed to insert legacy key: %v", err)
+	}
+	event := newTestEncryptedEvent(owner, value)
+	if err := event.AcceptCrypto(&aesEncryptor{keys: keysByOwner{owner: key}, event: event}); err != nil {
+		t.Fatalf("failed to encrypt with legacy key: %v", err)
+	}
+	return event.Secret.Value
+}
+
+func Test_pgEventCrypto_ShredKeys(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	crypto := NewEventCrypto(pool, core.Must2(NewKeyring("k1", KeyEncryptionKey{ID: "k1", Key: newTestKey(1)})), testHasher)
+	owner := idgen.New[eventing.AggregateID]()
+	event := newTestEncryptedEvent(owner, "secret")
+	if err := crypto.EncryptEvents(ctx, []eventing.Event{event}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := crypto.ShredKeys(ctx, owner); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := crypto.DecryptEvents(ctx, []eventing.Event{event}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !event.IsShredded() || event.Secret.Value != "" {
+		t.Errorf("expected event to be shredded, got %q", event.Secret.Value)
+	}
+}
@@ -2,15 +2,24 @@ package eventing
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
+	"time"
+)
+
+const (
+	notifierMinBackoff = 100 * time.Millisecond
+	notifierMaxBackoff = 30 * time.Second
 )
 
 type eventNotifier struct {
@@ -19,10 +28,18 @@ type eventNotifier struct {
 	allAggSet map[eventing.AggregateType]struct{}
 	pool      *pgxpool.Pool
 	log       *slog.Logger
+
+	statusMu sync.Mutex
+	status   eventing.NotifierStatus
 }
 
 func NewEventNotifier(log *slog.Logger, pool *pgxpool.Pool) eventing.EventNotifier {
-	return &eventNotifier{pool: pool, log: log, allAggSet: make(map[eventing.AggregateType]struct{})}
+	return &eventNotifier{
+		pool:      pool,
+		log:       log,
+		allAggSet: make(map[eventing.AggregateType]struct{}),
+		status:    eventing.NotifierStatus{State: eventing.NotifierStateConnecting, Since: time.Now()},
+	}
 }
 
 func (e *eventNotifier) AddListener(listener eventing.EventListener) {
@@ -35,34 +52,61 @@ func (e *eventNotifier) AddListener(listener eventing.EventListener) {
 	}
 }
 
+// Start listens for notifications until the context is cancelled.
+// A lost connection is re-established with an exponential backoff.
 func (e *eventNotifier) Start(ctx context.Context) error {
-	conn, err := e.pool.Acquire(ctx)
-	if err != nil {
-		return err
+	backoff := notifierMinBackoff
+	for {
+		listened, err := e.listen(ctx)
+		if ctx.Err() != nil {
+			e.setState(eventing.NotifierStateStopped, nil)
+			return nil
+		}
+		if listened {
+			backoff = notifierMinBackoff
+		}
+		e.log.Error("Lost the notification connection, reconnecting", slog.String("err", err.Error()), slog.Duration("backoff", backoff))
+		e.setState(eventing.NotifierStateReconnecting, err)
+
+		select {
+		case <-ctx.Done():
+			e.setState(eventing.NotifierStateStopped, nil)
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, notifierMaxBackoff)
 	}
-	defer conn.Release()
+}
+
+func (e *eventNotifier) Status() eventing.NotifierStatus {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+	return e.status
+}
 
-	err = e.prepareListeners(ctx, conn)
+// listen receives notifications on a dedicated connection until it fails.
+// It reports whether the connection got as far as listening on the channels.
+func (e *eventNotifier) listen(ctx context.Context) (bool, error) {
+	// The connection is not taken from the pool, as it is blocked for as long as the notifier runs.
+	conn, err := pgx.ConnectConfig(ctx, e.pool.Config().ConnConfig)
 	if err != nil {
-		return err
+		return false, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if err := e.prepareListeners(ctx, conn); err != nil {
+		return false, err
+	}
+	if recovered := e.setState(eventing.NotifierStateListening, nil); recovered {
+		// Notifications sent while the connection was down are lost, so let all listeners catch up.
+		e.notifyAll(ctx)
 	}
 
 	// Run the notification loop.
-	var retries int
 	for {
-		notification, err := conn.Conn().WaitForNotification(ctx)
+		notification, err := conn.WaitForNotification(ctx)
 		if err != nil {
-			// For whatever reason, pg wraps the context cancelled error.
-			if errors.Is(err, context.Canceled) {
-				return nil
-			}
-			e.log.Error("Failed to wait for notification", slog.String("err", err.Error()))
-			if retries > 3 {
-				e.log.Error("shutting down because of too many retries")
-				return err
-			}
-			retries++
-			continue
+			return true, err
 		}
 		e.log.Debug("Received notification", slog.String("channel", notification.Channel), slog.String("payload", notification.Payload))
 
@@ -70,7 +114,7 @@ func (e *eventNotifier) Start(ctx context.Context) error {
 	}
 }
 
-func (e *eventNotifier) prepareListeners(ctx context.Context, conn *pgxpool.Conn) error {
+func (e *eventNotifier) prepareListeners(ctx context.Context, conn *pgx.Conn) error {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -80,24 +124,81 @@ func (e *eventNotifier) prepareListeners(ctx context.Context, conn *pgxpool.Conn
 		stmt := fmt.Sprintf("LISTEN event_store_%s", string(agg))
 		_, err := conn.Exec(ctx, stmt)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to listen on channel of %s: %w", agg, err)
 		}
 	}
 	return nil
 }
 
+// setState moves the notifier into the state and reports whether it recovered from a lost connection.
+func (e *eventNotifier) setState(state eventing.NotifierState, err error) bool {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	if e.status.State == state {
+		return false
+	}
+	recovered := state == eventing.NotifierStateListening && e.status.Reconnects > 0
+	if state == eventing.NotifierStateReconnecting {
+		e.status.Reconnects++
+		e.status.LastError = err
+	}
+	e.status.State = state
+	e.status.Since = time.Now()
+	if recovered {
+		e.log.Info("Recovered the notification connection", slog.Int("reconnects", e.status.Reconnects))
+	}
+	return recovered
+}
+
+// notifyAll notifies each listener about all of its interests.
+func (e *eventNotifier) notifyAll(ctx context.Context) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, sub := range e.subs {
+		sub.Notify(ctx, slices.Collect(maps.Keys(sub.Interests()))...)
+	}
+}
+
+// notificationPayload is sent by the notify_event_store trigger.
+type notificationPayload struct {
+	EventType     eventing.EventType `json:"event_type"`
+	TransactionID uint64             `json:"transaction_id,string"`
+	Sequence      uint64             `json:"sequence"`
+}
+
+// parseNotification extracts the interest and the position of the appended event from the notification.
+// The position is missing in the plain event type payloads of triggers created before it was added.
+func parseNotification(notification *pgconn.Notification) (eventing.EventInterest, *eventing.JournalPosition) {
+	interest := eventing.EventInterest{
+		AggType:   eventing.AggregateType(strings.TrimPrefix(notification.Channel, "event_store_")),
+		EventType: eventing.EventType(notification.Payload),
+	}
+	if !strings.HasPrefix(notification.Payload, "{") {
+		return interest, nil
+	}
+	var payload notificationPayload
+	if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+		return interest, nil
+	}
+	interest.EventType = payload.EventType
+	return interest, &eventing.JournalPosition{TransactionID: payload.TransactionID, Sequence: payload.Sequence}
+}
+
 // TODO: Batch notifications.
 func (e *eventNotifier) handleNotification(ctx context.Context, notification *pgconn.Notification) {
 	ctx, span := tracing.Tracer.Start(ctx, "pg.EventNotifier.handleNotification")
 	defer span.End()
 
-	aggregateType := eventing.AggregateType(strings.TrimPrefix(notification.Channel, "event_store_"))
-	eventType := eventing.EventType(notification.Payload)
-	interestFromNotification := eventing.EventInterest{
-		AggType:   aggregateType,
-		EventType: eventType,
+	interestFromNotification, position := parseNotification(notification)
+	if position != nil {
+		ctx = eventing.NewContextWithNotifiedPosition(ctx, *position)
 	}
 
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	for _, sub := range e.subs {
 		if !sub.Interests().IsInterestedIn(interestFromNotification) {
 			continue
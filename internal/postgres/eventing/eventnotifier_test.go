@@ -0,0 +1,29 @@
+package eventing
+
+import (
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"testing"
+)
+
+func TestParseNotification(t *testing.T) {
+	interest, position := parseNotification(&pgconn.Notification{
+		Channel: "event_store_team",
+		Payload: `{"event_type": "team_created", "transaction_id": "4711", "sequence": 42}`,
+	})
+	if interest.AggType != "team" || interest.EventType != "team_created" {
+		t.Errorf("unexpected interest %v", interest)
+	}
+	if position == nil || *position != (eventing.JournalPosition{TransactionID: 4711, Sequence: 42}) {
+		t.Errorf("expected position 4711:42, got %v", position)
+	}
+
+	// Triggers of earlier migrations only send the event type.
+	interest, position = parseNotification(&pgconn.Notification{Channel: "event_store_team", Payload: "team_created"})
+	if interest.AggType != "team" || interest.EventType != "team_created" {
+		t.Errorf("unexpected interest %v", interest)
+	}
+	if position != nil {
+		t.Errorf("expected no position, got %v", position)
+	}
+}
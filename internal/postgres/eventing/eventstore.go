@@ -1,11 +1,11 @@
 package eventing
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	decimal "github.com/jackc/pgx-shopspring-decimal"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -16,8 +16,11 @@ import (
 	"go.opentelemetry.io/otel/metric"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
+	"iter"
 	"log/slog"
+	"maps"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -40,6 +43,9 @@ func init() {
 	}
 }
 
+// queryIterPageSize is the number of events QueryIter fetches at once.
+const queryIterPageSize = 500
+
 var uniqueConstraintRegex = regexp.MustCompile(`Key \(([^)]+)\)=\(([^)]+)\)`)
 
 type pgEventStore struct {
@@ -48,18 +54,48 @@ type pgEventStore struct {
 	crypto eventing.EventCrypto
 	log    *slog.Logger
 	hooks  []eventing.Hook
+
+	snapshots         eventing.SnapshotStore
+	snapshotThreshold int
+
+	outbox *Outbox
+}
+
+type EventStoreOpt func(p *pgEventStore)
+
+// WithSnapshots enables snapshots for all views implementing [eventing.Snapshotter].
+// A new snapshot is taken as soon as at least threshold events had to be replayed on top of the last one.
+func WithSnapshots(store eventing.SnapshotStore, threshold int) EventStoreOpt {
+	return func(p *pgEventStore) {
+		p.snapshots = store
+		p.snapshotThreshold = threshold
+	}
+}
+
+// WithOutbox writes the outbox entries of all appended events in the same transaction as the events.
+func WithOutbox(outbox *Outbox) EventStoreOpt {
+	return func(p *pgEventStore) {
+		p.outbox = outbox
+	}
 }
 
-func NewEventStore(log *slog.Logger, pool *pgxpool.Pool, mapper eventing.JournalEventMapper, crypto eventing.EventCrypto) eventing.EventStore {
-	return &pgEventStore{
+func NewEventStore(log *slog.Logger, pool *pgxpool.Pool, mapper eventing.JournalEventMapper, crypto eventing.EventCrypto, opts ...EventStoreOpt) eventing.EventStore {
+	p := &pgEventStore{
 		pool:   pool,
 		mapper: mapper,
 		log:    log,
 		crypto: crypto,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
-func (p *pgEventStore) Append(ctx context.Context, intents ...eventing.AggregateChangeIntent) ([]*eventing.JournalEvent, error) {
+func (p *pgEventStore) Append(ctx context.Context, intents ...eventing.AggregateChangeIntent) ([][]*eventing.JournalEvent, error) {
+	// Capture the metadata before starting our own span, so the events point to the span of the caller.
+	metadata := metadataFromContext(ctx)
+
 	ctx, span := tracing.Tracer.Start(ctx, "pg.EventStore.Append")
 	defer span.End()
 
@@ -68,42 +104,59 @@ func (p *pgEventStore) Append(ctx context.Context, intents ...eventing.Aggregate
 		appendDurationHistogram.Record(ctx, time.Since(start).Seconds())
 	}()
 
-	if len(intents) != 1 {
-		return nil, errors.New("only one intent is supported")
+	if err := eventing.CheckDistinctIntents(intents); err != nil {
+		return nil, err
 	}
 
-	if len(intents[0].Events()) == 0 {
-		return nil, nil
+	persistedEvents := make([][]*eventing.JournalEvent, len(intents))
+	if !hasAnyEvents(intents) {
+		return persistedEvents, nil
 	}
 
-	var persistedEvents []*eventing.JournalEvent
 	err := pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
 		ctx := postgres.WithTx(ctx, tx)
 
-		intent := intents[0]
-		aggregateVersion, err := lockLatestAggregateVersion(ctx, tx, intent.AggregateID(), intent.AggregateType())
-		if err != nil {
-			return err
+		// Lock all aggregates upfront in a stable order to avoid deadlocks between concurrent appends.
+		aggregateVersions := make([]eventing.AggregateVersion, len(intents))
+		for _, i := range lockOrder(intents) {
+			intent := intents[i]
+			aggregateVersion, err := lockLatestAggregateVersion(ctx, tx, intent.AggregateID(), intent.AggregateType())
+			if err != nil {
+				return err
+			}
+			aggregateVersions[i] = aggregateVersion
 		}
 
-		if !intent.VersionMatches(aggregateVersion) {
-			p.log.Debug("Aggregate versions do not match", slog.Uint64("remote", uint64(aggregateVersion)), slog.Uint64("local", uint64(intent.LastKnownAggregateVersion())))
-			return eventing.ErrVersionMismatch
-		}
+		for i, intent := range intents {
+			if len(intent.Events()) == 0 {
+				continue
+			}
 
-		err = p.handleUniqueConstraints(ctx, tx, intent)
-		if err != nil {
-			return err
-		}
+			if !intent.VersionMatches(aggregateVersions[i]) {
+				p.log.Debug("Aggregate versions do not match", slog.Uint64("remote", uint64(aggregateVersions[i])), slog.Uint64("local", uint64(intent.LastKnownAggregateVersion())))
+				return eventing.ErrVersionMismatch
+			}
 
-		err = p.handleLookups(ctx, tx, intent)
-		if err != nil {
-			return err
-		}
+			err := p.handleUniqueConstraints(ctx, tx, intent)
+			if err != nil {
+				return err
+			}
 
-		persistedEvents, err = p.persistEvents(ctx, tx, intent)
-		if err != nil {
-			return err
+			err = p.handleLookups(ctx, tx, intent)
+			if err != nil {
+				return err
+			}
+
+			persistedEvents[i], err = p.persistEvents(ctx, tx, intent, metadata)
+			if err != nil {
+				return err
+			}
+
+			if p.outbox != nil {
+				if err := p.outbox.write(ctx, tx, persistedEvents[i]); err != nil {
+					return err
+				}
+			}
 		}
 
 		return nil
@@ -111,6 +164,8 @@ func (p *pgEventStore) Append(ctx context.Context, intents ...eventing.Aggregate
 	if err != nil {
 		return nil, err
 	}
+	eventing.RecordAppended(ctx, persistedEvents)
+
 	// Run all post persist hooks
 	for _, hook := range p.hooks {
 		if post, ok := hook.(eventing.PostPersist); ok {
@@ -122,15 +177,21 @@ func (p *pgEventStore) Append(ctx context.Context, intents ...eventing.Aggregate
 	return persistedEvents, nil
 }
 
-func (p *pgEventStore) ProduceAppend(ctx context.Context, producer eventing.Writer) error {
+func (p *pgEventStore) ProduceAppend(ctx context.Context, producers ...eventing.Writer) error {
 	ctx, span := tracing.Tracer.Start(ctx, "pg.EventStore.ProduceAppend")
 	defer span.End()
 
-	events, err := p.Append(ctx, *producer.Changes())
+	intents := make([]eventing.AggregateChangeIntent, len(producers))
+	for i, producer := range producers {
+		intents[i] = *producer.Changes()
+	}
+	events, err := p.Append(ctx, intents...)
 	if err != nil {
 		return err
 	}
-	producer.Reduce(events)
+	for i, producer := range producers {
+		producer.Reduce(events[i])
+	}
 	return nil
 }
 
@@ -146,7 +207,65 @@ func (p *pgEventStore) queryConn(ctx context.Context, db postgres.DB, query even
 	}
 
 	var stmtBuilder strings.Builder
-	stmtBuilder.WriteString("SELECT id, aggregate_id, aggregate_type, aggregate_version, global_position, event_type, event_version, payload, created_at FROM event_journal WHERE (")
+	stmtBuilder.WriteString("SELECT id, aggregate_id, aggregate_type, aggregate_version, transaction_id, sequence, event_type, event_version, payload, metadata, created_at FROM event_journal")
+	filter, args := journalFilter(query, config)
+	stmtBuilder.WriteString(filter)
+	argI := len(args)
+
+	// Order by journal position.
+	stmtBuilder.WriteString(" ORDER BY transaction_id ASC, sequence ASC")
+
+	if query.Limit() > 0 {
+		stmtBuilder.WriteString(fmt.Sprintf(" LIMIT $%d", argI+1))
+		args = append(args, query.Limit())
+		argI++
+	}
+
+	stmt := stmtBuilder.String()
+	span.SetAttributes(semconv.DBQueryText(stmt))
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	var (
+		id               eventing.EventID
+		aggregateID      eventing.AggregateID
+		aggregateType    eventing.AggregateType
+		aggregateVersion eventing.AggregateVersion
+		position         eventing.JournalPosition
+		eventType        eventing.EventType
+		eventVersion     eventing.EventVersion
+		payload          []byte
+		metadata         *eventing.Metadata
+		createdAt        time.Time
+	)
+	journalEvents, err := postgres.CollectRowsNonNil(rows, func(row pgx.CollectableRow) (*eventing.JournalEvent, error) {
+		metadata = nil
+		err := row.Scan(&id, &aggregateID, &aggregateType, &aggregateVersion, &position.TransactionID, &position.Sequence, &eventType, &eventVersion, &payload, &metadata, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		event, err := p.mapper.MapFrom(aggregateID, aggregateType, eventVersion, eventType, id, aggregateVersion, position, createdAt, payload)
+		if err != nil {
+			return nil, err
+		}
+		event.SetMetadata(metadata)
+		return event, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = p.crypto.DecryptEvents(ctx, eventing.JournalEventsWithMetadata(journalEvents))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt events: %w", err)
+	}
+	return journalEvents, nil
+}
+
+// journalFilter translates the query into the WHERE clause of a statement on the event journal.
+func journalFilter(query eventing.JournalQuery, config eventing.QueryConfig) (string, []any) {
+	var stmtBuilder strings.Builder
+	stmtBuilder.WriteString(" WHERE (")
 	var args []any
 	var argI int
 	for aggregateType, aggregateQuery := range query.AggQueriesByType() {
@@ -183,62 +302,21 @@ func (p *pgEventStore) queryConn(ctx context.Context, db postgres.DB, query even
 		if argI > 0 {
 			stmtBuilder.WriteString(" AND ")
 		}
-		stmtBuilder.WriteString(fmt.Sprintf("global_position > $%d", argI+1))
-		args = append(args, query.JournalPositionAfter().Deref())
-		argI++
+		after := query.JournalPositionAfter()
+		stmtBuilder.WriteString(fmt.Sprintf("(transaction_id, sequence) > ($%d::xid8, $%d)", argI+1, argI+2))
+		args = append(args, after.TransactionID, after.Sequence)
+		argI += 2
 	}
 
-	// Only return rows that have been inserted before the oldest running transaction started.
+	// Only return rows that have been appended by transactions older than the oldest running transaction.
+	// Younger transactions might still be overtaken by running ones, which would make readers skip events.
 	// Again, many thanks to Zitadel for pointing that out.
+	// The xmin of the snapshot covers the transactions of all databases of the cluster, so a long-running
+	// transaction anywhere in the cluster holds back all readers.
 	if config.LimitToOldestRunningTransaction {
-		stmtBuilder.WriteString(" AND global_position < (")
-		stmtBuilder.WriteString(oldestRunningTransactionQuery)
-		stmtBuilder.WriteString(")")
-	}
-
-	// Order by global position.
-	stmtBuilder.WriteString(" ORDER BY global_position ASC")
-
-	stmt := stmtBuilder.String()
-	span.SetAttributes(semconv.DBQueryText(stmt))
-	rows, err := db.Query(ctx, stmt, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query events: %w", err)
-	}
-	var (
-		id               eventing.EventID
-		aggregateID      eventing.AggregateID
-		aggregateType    eventing.AggregateType
-		aggregateVersion eventing.AggregateVersion
-		globalPosition   decimal.Decimal
-		eventType        eventing.EventType
-		eventVersion     eventing.EventVersion
-		payload          []byte
-		createdAt        time.Time
-	)
-	journalEvents, err := postgres.CollectRowsNonNil(rows, func(row pgx.CollectableRow) (*eventing.JournalEvent, error) {
-		err := row.Scan(&id, &aggregateID, &aggregateType, &aggregateVersion, &globalPosition, &eventType, &eventVersion, &payload, &createdAt)
-		if err != nil {
-			return nil, err
-		}
-		event, err := p.mapper.MapFrom(aggregateID, aggregateType, eventVersion, eventType, id, aggregateVersion, eventing.JournalPosition(globalPosition), createdAt, payload)
-		if err != nil {
-			return nil, err
-		}
-		return event, err
-	})
-	if err != nil {
-		return nil, err
+		stmtBuilder.WriteString(" AND transaction_id < pg_snapshot_xmin(pg_current_snapshot())")
 	}
-	events := make([]eventing.Event, len(journalEvents))
-	for i, journalEvent := range journalEvents {
-		events[i] = journalEvent.Event
-	}
-	err = p.crypto.DecryptEvents(ctx, events)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt events: %w", err)
-	}
-	return journalEvents, nil
+	return stmtBuilder.String(), args
 }
 
 func (p *pgEventStore) Query(ctx context.Context, query eventing.JournalQuery, opts ...eventing.QueryOpts) ([]*eventing.JournalEvent, error) {
@@ -251,10 +329,71 @@ func (p *pgEventStore) Query(ctx context.Context, query eventing.JournalQuery, o
 	return p.queryConn(ctx, db, query, opts...)
 }
 
+func (p *pgEventStore) Count(ctx context.Context, query eventing.JournalQuery, opts ...eventing.QueryOpts) (int, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.EventStore.Count")
+	defer span.End()
+
+	var config eventing.QueryConfig
+	for _, opt := range opts {
+		config = opt.Apply(config)
+	}
+	filter, args := journalFilter(query, config)
+	stmt := "SELECT count(*) FROM event_journal" + filter
+	span.SetAttributes(semconv.DBQueryText(stmt))
+
+	// Check if there's already a transaction in the context we can use.
+	db := postgres.GetDBFromContext(ctx, p.pool)
+	var count int
+	if err := db.QueryRow(ctx, stmt, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+	return count, nil
+}
+
+func (p *pgEventStore) QueryIter(ctx context.Context, query eventing.JournalQuery, opts ...eventing.QueryOpts) iter.Seq2[*eventing.JournalEvent, error] {
+	return func(yield func(*eventing.JournalEvent, error) bool) {
+		ctx, span := tracing.Tracer.Start(ctx, "pg.EventStore.QueryIter")
+		defer span.End()
+
+		// Check if there's already a transaction in the context we can use.
+		db := postgres.GetDBFromContext(ctx, p.pool)
+
+		// Page through the journal by global position, so that the rows of a page are fully consumed
+		// (and decrypted) before any event is handed to the caller.
+		remaining := query.Limit()
+		builder := eventing.NewJournalQueryBuilderFrom(query)
+		for {
+			pageSize := queryIterPageSize
+			if query.Limit() > 0 {
+				pageSize = min(pageSize, remaining)
+			}
+			events, err := p.queryConn(ctx, db, builder.WithLimit(pageSize).MustBuild(), opts...)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, event := range events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			remaining -= len(events)
+			if len(events) < pageSize || (query.Limit() > 0 && remaining <= 0) {
+				return
+			}
+			builder.WithJournalPositionAfter(events[len(events)-1].JournalPosition())
+		}
+	}
+}
+
 func (p *pgEventStore) View(ctx context.Context, view eventing.JournalViewer) error {
 	ctx, span := tracing.Tracer.Start(ctx, "pg.EventStore.View")
 	defer span.End()
 
+	if snapshotter, ok := view.(eventing.Snapshotter); ok && p.snapshots != nil {
+		return p.viewWithSnapshot(ctx, snapshotter)
+	}
+
 	query := view.Query()
 	events, err := p.Query(ctx, query)
 	if err != nil {
@@ -264,6 +403,57 @@ func (p *pgEventStore) View(ctx context.Context, view eventing.JournalViewer) er
 	return nil
 }
 
+// viewWithSnapshot restores the view from its latest snapshot and only replays the events that came after it.
+// If too many events had to be replayed, a new snapshot is taken.
+func (p *pgEventStore) viewWithSnapshot(ctx context.Context, view eventing.Snapshotter) error {
+	aggregate := view.Aggregate()
+	_, encrypted := view.(eventing.EncryptedSnapshotter)
+	log := p.log.With(slog.String("aggregate", aggregate.String()))
+
+	query := view.Query()
+	forceSnapshot := false
+	snapshot, err := p.snapshots.LoadSnapshot(ctx, aggregate.AggregateID, aggregate.AggregateType, encrypted)
+	switch {
+	case errors.Is(err, eventing.ErrSnapshotNotFound):
+	case err != nil:
+		log.Warn("Failed to load snapshot, replaying all events", slog.Any("error", err))
+	case snapshot.SnapshotVersion != view.SnapshotVersion():
+		log.Debug("Discarding outdated snapshot", slog.Uint64("snapshot_version", uint64(snapshot.SnapshotVersion)))
+		forceSnapshot = true
+	default:
+		if err := view.RestoreSnapshot(snapshot.AggregateVersion, snapshot.Payload); err != nil {
+			log.Warn("Failed to restore snapshot, replaying all events", slog.Any("error", err))
+			forceSnapshot = true
+			break
+		}
+		query = eventing.QueryAfterSnapshot(query, snapshot)
+	}
+
+	events, err := p.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	view.Reduce(events)
+
+	if len(events) == 0 || (!forceSnapshot && len(events) < p.snapshotThreshold) {
+		return nil
+	}
+	// Snapshotting shredded state would preserve it (or even create a new key for it).
+	if slices.ContainsFunc(events, func(event *eventing.JournalEvent) bool { return event.IsShredded() }) {
+		return nil
+	}
+	snapshot, err = eventing.TakeSnapshot(view)
+	if err != nil {
+		log.Error("Failed to take snapshot", slog.Any("error", err))
+		return nil
+	}
+	// The view itself is fine, so failing to save the snapshot is not fatal.
+	if err := p.snapshots.SaveSnapshot(ctx, snapshot, encrypted); err != nil {
+		log.Error("Failed to save snapshot", slog.Any("error", err))
+	}
+	return nil
+}
+
 func (p *pgEventStore) Lookup(ctx context.Context, opts eventing.LookupOpts) (*eventing.LookupFieldValue, error) {
 	ctx, span := tracing.Tracer.Start(ctx, "pg.EventStore.Lookup")
 	defer span.End()
@@ -290,8 +480,12 @@ func (p *pgEventStore) OwnerLookup(ctx context.Context, opts eventing.LookupOpts
 	ctx, span := tracing.Tracer.Start(ctx, "pg.EventStore.OwnerLookup")
 	defer span.End()
 
+	fieldValue := opts.FieldValue
+	if opts.Sensitive {
+		fieldValue = eventing.LookupFieldValue(p.crypto.HashLookupValue(string(opts.FieldName), string(opts.FieldValue)))
+	}
 	stmt := "SELECT owner_aggregate_id FROM event_journal_lookup WHERE owner_aggregate_type = $1 AND field_name = $2 AND field_value = $3"
-	row, err := p.pool.Query(ctx, stmt, opts.AggregateType, opts.FieldName, opts.FieldValue)
+	row, err := p.pool.Query(ctx, stmt, opts.AggregateType, opts.FieldName, fieldValue)
 	if err != nil {
 		return "", fmt.Errorf("failed to lookup owner: %w", err)
 	}
@@ -308,6 +502,30 @@ func (p *pgEventStore) OwnerLookup(ctx context.Context, opts eventing.LookupOpts
 	return ownerID, nil
 }
 
+func hasAnyEvents(intents []eventing.AggregateChangeIntent) bool {
+	for _, intent := range intents {
+		if len(intent.Events()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// lockOrder returns the indices of the intents sorted by aggregate type and ID.
+func lockOrder(intents []eventing.AggregateChangeIntent) []int {
+	order := make([]int, len(intents))
+	for i := range intents {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		if c := cmp.Compare(intents[a].AggregateType(), intents[b].AggregateType()); c != 0 {
+			return c
+		}
+		return cmp.Compare(intents[a].AggregateID(), intents[b].AggregateID())
+	})
+	return order
+}
+
 func lockLatestAggregateVersion(ctx context.Context, tx pgx.Tx, aggregateID eventing.AggregateID, aggregateType eventing.AggregateType) (eventing.AggregateVersion, error) {
 	ctx, span := tracing.Tracer.Start(ctx, "pg.lockLatestAggregateVersion")
 	defer span.End()
@@ -356,6 +574,7 @@ func (p *pgEventStore) handleUniqueConstraints(ctx context.Context, tx pgx.Tx, i
 			continue
 		}
 		for _, toAdd := range adder.UniqueConstraintsToAdd() {
+			toAdd := toAdd.Protect(p.crypto)
 			hasAddStmt = true
 			if addEventI > 0 {
 				addStmtBuilder.WriteString(", ")
@@ -395,19 +614,21 @@ func (p *pgEventStore) handleUniqueConstraints(ctx context.Context, tx pgx.Tx, i
 			continue
 		}
 		for _, toRemove := range remover.UniqueConstraintsToRemove() {
+			toRemove := toRemove.Protect(p.crypto)
 			hasRmStmt = true
 			if rmEventI > 0 {
-				addStmtBuilder.WriteString(" OR ")
+				rmStmtBuilder.WriteString(" OR ")
 			} else {
 				rmStmtBuilder.WriteString("DELETE FROM unique_constraint uc WHERE ")
 			}
 
 			if toRemove.ConstrainedField() == "" && toRemove.ConstrainedValue() == "" {
 				rmStmtBuilder.WriteString(fmt.Sprintf("uc.owner_aggregate_id = $%d", rmEventI+1))
-				rmArgs = append(addArgs, intent.AggregateID())
+				rmArgs = append(rmArgs, intent.AggregateID())
+				rmEventI += 1
 			} else {
 				rmStmtBuilder.WriteString(fmt.Sprintf("(uc.field = $%d AND uc.value = $%d AND uc.owner_aggregate_id = $%d)", rmEventI+1, rmEventI+2, rmEventI+3))
-				rmArgs = append(addArgs, toRemove.ConstrainedField(), toRemove.ConstrainedValue(), intent.AggregateID())
+				rmArgs = append(rmArgs, toRemove.ConstrainedField(), toRemove.ConstrainedValue(), intent.AggregateID())
 				rmEventI += 3
 			}
 
@@ -444,16 +665,22 @@ func (p *pgEventStore) handleLookups(ctx context.Context, tx pgx.Tx, intent even
 	defer span.End()
 
 	for _, event := range intent.Events() {
-		lookupProvider, ok := event.(eventing.LookupProvider)
-		if ok {
-			// TODO: Optimize this to use a single query.
-			for fieldName, fieldValue := range lookupProvider.LookupValues() {
-				id := idgen.NewString()
-				stmt := "INSERT INTO event_journal_lookup (id, owner_aggregate_id, owner_aggregate_type, field_name, field_value) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (owner_aggregate_id, field_name) DO UPDATE SET field_value = $3"
-				_, err := tx.Exec(ctx, stmt, id, event.AggregateID(), event.AggregateType(), fieldName, fieldValue)
-				if err != nil {
-					return fmt.Errorf("failed to insert lookups: %w", err)
-				}
+		lookups := make(eventing.LookupMap)
+		if lookupProvider, ok := event.(eventing.LookupProvider); ok {
+			maps.Copy(lookups, lookupProvider.LookupValues())
+		}
+		if lookupProvider, ok := event.(eventing.SensitiveLookupProvider); ok {
+			for fieldName, fieldValue := range lookupProvider.SensitiveLookupValues() {
+				lookups[fieldName] = eventing.LookupFieldValue(p.crypto.HashLookupValue(string(fieldName), string(fieldValue)))
+			}
+		}
+		// TODO: Optimize this to use a single query.
+		for fieldName, fieldValue := range lookups {
+			id := idgen.NewString()
+			stmt := "INSERT INTO event_journal_lookup (id, owner_aggregate_id, owner_aggregate_type, field_name, field_value) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (owner_aggregate_id, field_name) DO UPDATE SET field_value = excluded.field_value"
+			_, err := tx.Exec(ctx, stmt, id, event.AggregateID(), event.AggregateType(), fieldName, fieldValue)
+			if err != nil {
+				return fmt.Errorf("failed to insert lookups: %w", err)
 			}
 		}
 		lookupRemover, ok := event.(eventing.LookupRemover)
@@ -470,17 +697,26 @@ func (p *pgEventStore) handleLookups(ctx context.Context, tx pgx.Tx, intent even
 	return nil
 }
 
-func (p *pgEventStore) persistEvents(ctx context.Context, tx pgx.Tx, intent eventing.AggregateChangeIntent) ([]*eventing.JournalEvent, error) {
+func (p *pgEventStore) persistEvents(ctx context.Context, tx pgx.Tx, intent eventing.AggregateChangeIntent, metadata *eventing.Metadata) ([]*eventing.JournalEvent, error) {
 	ctx, span := tracing.Tracer.Start(ctx, "pg.persistEvents")
 	defer span.End()
 
 	events := intent.Events()
-	if err := p.crypto.EncryptEvents(ctx, events); err != nil {
+	// The metadata is encrypted per event, as its personal data is bound to the event.
+	eventMetadata := make([]*eventing.Metadata, len(events))
+	if metadata != nil {
+		for i := range events {
+			md := *metadata
+			eventMetadata[i] = &md
+		}
+	}
+	cryptoEvents := eventing.WithMetadata(events, eventMetadata)
+	if err := p.crypto.EncryptEvents(ctx, cryptoEvents); err != nil {
 		return nil, fmt.Errorf("failed to encrypt events: %w", err)
 	}
 
 	var stmt strings.Builder
-	args := make([]any, len(events)*7)
+	args := make([]any, len(events)*8)
 	var argI int
 	for i, event := range events {
 		id := idgen.NewString()
@@ -488,6 +724,13 @@ func (p *pgEventStore) persistEvents(ctx context.Context, tx pgx.Tx, intent even
 		if err != nil {
 			return nil, err
 		}
+		var rawMetadata []byte
+		if eventMetadata[i] != nil {
+			rawMetadata, err = json.Marshal(eventMetadata[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+		}
 		args[argI] = id
 		args[argI+1] = event.AggregateID()
 		args[argI+2] = event.AggregateType()
@@ -495,32 +738,33 @@ func (p *pgEventStore) persistEvents(ctx context.Context, tx pgx.Tx, intent even
 		args[argI+4] = event.EventType()
 		args[argI+5] = event.EventVersion()
 		args[argI+6] = payload
+		args[argI+7] = rawMetadata
 
 		if i > 0 {
 			stmt.WriteString(",(")
 		} else {
 			stmt.WriteString("(")
 		}
-		for i := 1; i <= 7; i++ {
+		for i := 1; i <= 8; i++ {
 			stmt.WriteString("$" + strconv.Itoa(argI+i))
-			if i != 7 {
+			if i != 8 {
 				stmt.WriteRune(',')
 			}
 		}
 		stmt.WriteString(")")
-		argI = argI + 7
+		argI = argI + 8
 	}
 
 	// TODO: This is ugly. Unfortunately, we currently do not have a way to deep clone the list of events.
 	//	The events are encrypted in place and then marshalled.
-	if err := p.crypto.DecryptEvents(ctx, events); err != nil {
+	if err := p.crypto.DecryptEvents(ctx, cryptoEvents); err != nil {
 		return nil, fmt.Errorf("failed to decrypt events again: %w", err)
 	}
 
 	var (
 		eventID          eventing.EventID
 		aggregateVersion eventing.AggregateVersion
-		globalPosition   decimal.Decimal
+		position         eventing.JournalPosition
 		insertedAt       time.Time
 		i                int
 	)
@@ -529,25 +773,32 @@ func (p *pgEventStore) persistEvents(ctx context.Context, tx pgx.Tx, intent even
 		return nil, err
 	}
 	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*eventing.JournalEvent, error) {
-		if err := row.Scan(&eventID, &aggregateVersion, &globalPosition, &insertedAt); err != nil {
+		if err := row.Scan(&eventID, &aggregateVersion, &position.TransactionID, &position.Sequence, &insertedAt); err != nil {
 			return nil, err
 		}
-		event := events[i]
+		journalEvent := eventing.NewJournalEvent(events[i], eventID, aggregateVersion, position, insertedAt)
+		journalEvent.SetMetadata(eventMetadata[i])
 		i++
-		return eventing.NewJournalEvent(event, eventID, aggregateVersion, eventing.JournalPosition(globalPosition), insertedAt), nil
+		return journalEvent, nil
 	})
 }
 
-const oldestRunningTransactionQuery = `
-SELECT COALESCE(EXTRACT(EPOCH FROM min(xact_start)), EXTRACT(EPOCH FROM now()))
-FROM pg_stat_activity 
-WHERE datname = current_database() 
-	AND application_name = 'soccerbuddy' 
-	AND state <> 'idle'
-`
+// metadataFromContext collects the metadata of the current request and enriches it with the active span.
+// Returns nil if there is nothing to record.
+func metadataFromContext(ctx context.Context) *eventing.Metadata {
+	metadata, _ := eventing.MetadataFromContext(ctx)
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		metadata.TraceID = spanCtx.TraceID().String()
+		metadata.SpanID = spanCtx.SpanID().String()
+	}
+	if metadata == (eventing.Metadata{}) {
+		return nil
+	}
+	return &metadata
+}
 
 const insertEventsStmt = `
-INSERT INTO event_journal (id, aggregate_id, aggregate_type, aggregate_version, event_type, event_version, payload)
+INSERT INTO event_journal (id, aggregate_id, aggregate_type, aggregate_version, event_type, event_version, payload, metadata)
 VALUES %s
-RETURNING id, aggregate_version, global_position, created_at
+RETURNING id, aggregate_version, transaction_id, sequence, created_at
 `
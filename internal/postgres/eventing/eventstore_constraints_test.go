@@ -0,0 +1,148 @@
+package eventing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsmidt/soccerbuddy/internal/core"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+)
+
+// testEventConstraints adds and removes the given unique constraints and lookups.
+type testEventConstraints struct {
+	*eventing.EventBase
+
+	add     []eventing.UniqueConstraint
+	remove  []eventing.UniqueConstraint
+	lookups eventing.LookupMap
+}
+
+func newTestEventConstraints(id eventing.AggregateID) *testEventConstraints {
+	return &testEventConstraints{
+		EventBase: eventing.NewEventBase(id, "test", "v1", "TestEventConstraints"),
+	}
+}
+
+func (t *testEventConstraints) IsShredded() bool {
+	return false
+}
+
+func (t *testEventConstraints) UniqueConstraintsToAdd() []eventing.UniqueConstraint {
+	return t.add
+}
+
+func (t *testEventConstraints) UniqueConstraintsToRemove() []eventing.UniqueConstraint {
+	return t.remove
+}
+
+func (t *testEventConstraints) LookupValues() eventing.LookupMap {
+	return t.lookups
+}
+
+func appendConstraintsEvent(t *testing.T, es eventing.EventStore, event *testEventConstraints) error {
+	t.Helper()
+	_, err := es.Append(context.Background(), core.Must2(eventing.NewAggregateChangeIntent(
+		event.AggregateID(), "test", 0, []eventing.Event{event}, eventing.VersionMatcherAlways,
+	)))
+	return err
+}
+
+func Test_pgEventStore_RemoveUniqueConstraints(t *testing.T) {
+	t.Run("removes multiple constraints of an event", func(t *testing.T) {
+		t.Parallel()
+
+		pool, cleanup := postgres.GetTestPool()
+		t.Cleanup(cleanup)
+		es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+
+		owner := idgen.New[eventing.AggregateID]()
+		added := newTestEventConstraints(owner)
+		added.add = []eventing.UniqueConstraint{
+			eventing.NewUniqueConstraint(owner, "field_a", "a"),
+			eventing.NewUniqueConstraint(owner, "field_b", "b"),
+		}
+		if err := appendConstraintsEvent(t, es, added); err != nil {
+			t.Fatalf("failed to add constraints: %v", err)
+		}
+		removed := newTestEventConstraints(owner)
+		removed.remove = added.add
+		if err := appendConstraintsEvent(t, es, removed); err != nil {
+			t.Fatalf("failed to remove constraints: %v", err)
+		}
+
+		// Both values must be free again.
+		other := idgen.New[eventing.AggregateID]()
+		reused := newTestEventConstraints(other)
+		reused.add = []eventing.UniqueConstraint{
+			eventing.NewUniqueConstraint(other, "field_a", "a"),
+			eventing.NewUniqueConstraint(other, "field_b", "b"),
+		}
+		if err := appendConstraintsEvent(t, es, reused); err != nil {
+			t.Errorf("expected the removed constraints to be free, got %v", err)
+		}
+	})
+
+	t.Run("removes all constraints of the owner along with others", func(t *testing.T) {
+		t.Parallel()
+
+		pool, cleanup := postgres.GetTestPool()
+		t.Cleanup(cleanup)
+		es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+
+		owner := idgen.New[eventing.AggregateID]()
+		added := newTestEventConstraints(owner)
+		added.add = []eventing.UniqueConstraint{
+			eventing.NewUniqueConstraint(owner, "field_a", "a"),
+			eventing.NewUniqueConstraint(owner, "field_b", "b"),
+		}
+		if err := appendConstraintsEvent(t, es, added); err != nil {
+			t.Fatalf("failed to add constraints: %v", err)
+		}
+		removed := newTestEventConstraints(owner)
+		removed.remove = []eventing.UniqueConstraint{
+			eventing.NewDeleteAllConstraint(owner),
+			eventing.NewUniqueConstraint(owner, "field_b", "b"),
+		}
+		if err := appendConstraintsEvent(t, es, removed); err != nil {
+			t.Fatalf("failed to remove constraints: %v", err)
+		}
+
+		other := idgen.New[eventing.AggregateID]()
+		reused := newTestEventConstraints(other)
+		reused.add = []eventing.UniqueConstraint{eventing.NewUniqueConstraint(other, "field_a", "a")}
+		if err := appendConstraintsEvent(t, es, reused); err != nil {
+			t.Errorf("expected the removed constraints to be free, got %v", err)
+		}
+	})
+}
+
+func Test_pgEventStore_UpdateLookup(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+	es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+
+	owner := idgen.New[eventing.AggregateID]()
+	for _, value := range []eventing.LookupFieldValue{"old", "new"} {
+		event := newTestEventConstraints(owner)
+		event.lookups = eventing.LookupMap{"field_a": value}
+		if err := appendConstraintsEvent(t, es, event); err != nil {
+			t.Fatalf("failed to append lookup %s: %v", value, err)
+		}
+	}
+
+	found, err := es.OwnerLookup(context.Background(), eventing.LookupOpts{
+		AggregateType: "test",
+		FieldName:     "field_a",
+		FieldValue:    "new",
+	})
+	if err != nil {
+		t.Fatalf("expected the updated lookup to be found, got %v", err)
+	}
+	if found != owner {
+		t.Errorf("expected owner %s, got %s", owner, found)
+	}
+}
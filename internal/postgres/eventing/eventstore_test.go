@@ -11,6 +11,7 @@ import (
 	"github.com/rsmidt/soccerbuddy/internal/core"
 	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/eventing/eventingtest"
 	"github.com/rsmidt/soccerbuddy/internal/postgres"
 )
 
@@ -64,6 +65,14 @@ func (s *stubCrypto) DecryptEvents(ctx context.Context, events []eventing.Event)
 	return nil
 }
 
+func (s *stubCrypto) ShredKeys(ctx context.Context, owners ...eventing.AggregateID) error {
+	return nil
+}
+
+func (s *stubCrypto) HashLookupValue(field, value string) string {
+	return value
+}
+
 func newTestEvent(id string) *testEvent {
 	return &testEvent{
 		EventBase: eventing.NewEventBase(eventing.AggregateID(id), "test", "v1", "TestEvent"),
@@ -119,7 +128,7 @@ func Test_pgEventStore_Append(t *testing.T) {
 		name   string
 		fields fields
 		args   args
-		verify func(t *testing.T, events []*eventing.JournalEvent, err error)
+		verify func(t *testing.T, events [][]*eventing.JournalEvent, err error)
 	}{
 		{
 			name: "appends an intent with just one event",
@@ -135,15 +144,18 @@ func Test_pgEventStore_Append(t *testing.T) {
 					)),
 				},
 			},
-			verify: func(t *testing.T, events []*eventing.JournalEvent, err error) {
+			verify: func(t *testing.T, events [][]*eventing.JournalEvent, err error) {
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
 				}
 				if len(events) != 1 {
-					t.Fatalf("expected 1 event, got %d", len(events))
+					t.Fatalf("expected 1 intent, got %d", len(events))
+				}
+				if len(events[0]) != 1 {
+					t.Fatalf("expected 1 event, got %d", len(events[0]))
 				}
 
-				event := events[0]
+				event := events[0][0]
 				if event.AggregateVersion() != 1 {
 					t.Errorf("expected aggregate version 1, got %d", event.AggregateVersion())
 				}
@@ -176,7 +188,7 @@ func Test_pgEventStore_Append(t *testing.T) {
 					)),
 				},
 			},
-			verify: func(t *testing.T, events []*eventing.JournalEvent, err error) {
+			verify: func(t *testing.T, events [][]*eventing.JournalEvent, err error) {
 				expectedErr := eventing.NewUniqueConstraintError(
 					eventing.NewUniqueConstraint(
 						"9e0563c7-9b1d-47d7-b120-d6aa2f1db7e1",
@@ -221,7 +233,7 @@ func Test_pgEventStore_Append(t *testing.T) {
 			newTestEvent(string(aggregateID)),
 		}
 
-		journalEvents, err := es.Append(context.Background(), core.Must2(eventing.NewAggregateChangeIntent(
+		appended, err := es.Append(context.Background(), core.Must2(eventing.NewAggregateChangeIntent(
 			aggregateID,
 			aggregateType,
 			0,
@@ -231,6 +243,7 @@ func Test_pgEventStore_Append(t *testing.T) {
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
+		journalEvents := appended[0]
 
 		if len(journalEvents) != 3 {
 			t.Fatalf("expected 3 events, got %d", len(journalEvents))
@@ -250,7 +263,7 @@ func Test_pgEventStore_Append(t *testing.T) {
 			}
 
 			// Verify the journal position is increasing
-			if i > 0 && event.JournalPosition().Deref().Compare(journalEvents[i-1].JournalPosition().Deref()) < 1 {
+			if i > 0 && event.JournalPosition().Compare(journalEvents[i-1].JournalPosition()) < 1 {
 				t.Errorf("event %d: journal position should be strictly increasing, got %v <= %v",
 					i, event.JournalPosition(), journalEvents[i-1].JournalPosition())
 			}
@@ -279,11 +292,11 @@ func Test_pgEventStore_Append(t *testing.T) {
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if len(events) != 1 {
-			t.Fatalf("expected 1 event, got %d", len(events))
+		if len(events) != 1 || len(events[0]) != 1 {
+			t.Fatalf("expected 1 event, got %v", events)
 		}
-		if events[0].AggregateVersion() != 1 {
-			t.Errorf("expected version 1, got %d", events[0].AggregateVersion())
+		if events[0][0].AggregateVersion() != 1 {
+			t.Errorf("expected version 1, got %d", events[0][0].AggregateVersion())
 		}
 
 		// Second append with wrong version matcher
@@ -303,6 +316,107 @@ func Test_pgEventStore_Append(t *testing.T) {
 	})
 }
 
+func Test_pgEventStore_AppendMultipleIntents(t *testing.T) {
+	t.Run("appends all intents atomically", func(t *testing.T) {
+		t.Parallel()
+
+		pool, cleanup := postgres.GetTestPool()
+		t.Cleanup(cleanup)
+
+		es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+
+		idA := idgen.New[eventing.AggregateID]()
+		idB := idgen.New[eventing.AggregateID]()
+		appended, err := es.Append(context.Background(),
+			core.Must2(eventing.NewAggregateChangeIntent(idA, "test", 0, []eventing.Event{
+				newTestEvent(string(idA)),
+				newTestEvent(string(idA)),
+			}, eventing.VersionMatcherExact)),
+			core.Must2(eventing.NewAggregateChangeIntent(idB, "test", 0, []eventing.Event{
+				newTestEvent(string(idB)),
+			}, eventing.VersionMatcherExact)),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(appended) != 2 {
+			t.Fatalf("expected 2 intents, got %d", len(appended))
+		}
+		if len(appended[0]) != 2 {
+			t.Fatalf("expected 2 events for first intent, got %d", len(appended[0]))
+		}
+		if len(appended[1]) != 1 {
+			t.Fatalf("expected 1 event for second intent, got %d", len(appended[1]))
+		}
+		for _, event := range appended[0] {
+			if event.AggregateID() != idA {
+				t.Errorf("expected aggregate ID %s, got %s", idA, event.AggregateID())
+			}
+		}
+		if appended[1][0].AggregateID() != idB {
+			t.Errorf("expected aggregate ID %s, got %s", idB, appended[1][0].AggregateID())
+		}
+		if appended[1][0].AggregateVersion() != 1 {
+			t.Errorf("expected version 1, got %d", appended[1][0].AggregateVersion())
+		}
+	})
+
+	t.Run("rolls back all intents if one fails", func(t *testing.T) {
+		t.Parallel()
+
+		pool, cleanup := postgres.GetTestPool()
+		t.Cleanup(cleanup)
+
+		es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+
+		idA := idgen.New[eventing.AggregateID]()
+		idB := idgen.New[eventing.AggregateID]()
+		core.Must2(es.Append(context.Background(), core.Must2(eventing.NewAggregateChangeIntent(
+			idB, "test", 0, []eventing.Event{newTestEvent(string(idB))}, eventing.VersionMatcherExact,
+		))))
+
+		// The second intent is outdated, so the first one must not be persisted either.
+		_, err := es.Append(context.Background(),
+			core.Must2(eventing.NewAggregateChangeIntent(idA, "test", 0, []eventing.Event{
+				newTestEvent(string(idA)),
+			}, eventing.VersionMatcherExact)),
+			core.Must2(eventing.NewAggregateChangeIntent(idB, "test", 0, []eventing.Event{
+				newTestEvent(string(idB)),
+			}, eventing.VersionMatcherExact)),
+		)
+		if !errors.Is(err, eventing.ErrVersionMismatch) {
+			t.Fatalf("expected ErrVersionMismatch, got %v", err)
+		}
+
+		var builder eventing.JournalQueryBuilder
+		events, err := es.Query(context.Background(), builder.WithAggregate("test").AggregateID(idA).Finish().MustBuild())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(events) != 0 {
+			t.Errorf("expected 0 events, got %d", len(events))
+		}
+	})
+
+	t.Run("rejects multiple intents for the same aggregate", func(t *testing.T) {
+		t.Parallel()
+
+		pool, cleanup := postgres.GetTestPool()
+		t.Cleanup(cleanup)
+
+		es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+
+		id := idgen.New[eventing.AggregateID]()
+		_, err := es.Append(context.Background(),
+			core.Must2(eventing.NewAggregateChangeIntent(id, "test", 0, []eventing.Event{newTestEvent(string(id))}, eventing.VersionMatcherAlways)),
+			core.Must2(eventing.NewAggregateChangeIntent(id, "test", 0, []eventing.Event{newTestEvent(string(id))}, eventing.VersionMatcherAlways)),
+		)
+		if !errors.Is(err, eventing.ErrDuplicateIntents) {
+			t.Errorf("expected ErrDuplicateIntents, got %v", err)
+		}
+	})
+}
+
 func TestMarshalsEventWithBaseEvent(t *testing.T) {
 	t.Parallel()
 
@@ -411,3 +525,253 @@ func TestPgEventStore_Query(t *testing.T) {
 		}
 	})
 }
+
+var _ eventing.Snapshotter = (*testSnapshotView)(nil)
+
+type testSnapshotView struct {
+	eventing.BaseWriter
+
+	id              eventing.AggregateID
+	snapshotVersion eventing.SnapshotVersion
+
+	Count    int `json:"count"`
+	replayed int
+}
+
+func newTestSnapshotView(id eventing.AggregateID, snapshotVersion eventing.SnapshotVersion) *testSnapshotView {
+	return &testSnapshotView{
+		BaseWriter:      *eventing.NewBaseWriter(id, "test", eventing.VersionMatcherExact),
+		id:              id,
+		snapshotVersion: snapshotVersion,
+	}
+}
+
+func (v *testSnapshotView) Query() eventing.JournalQuery {
+	var builder eventing.JournalQueryBuilder
+	return builder.WithAggregate("test").AggregateID(v.id).Finish().MustBuild()
+}
+
+func (v *testSnapshotView) Reduce(events []*eventing.JournalEvent) {
+	v.Count += len(events)
+	v.replayed += len(events)
+	v.BaseWriter.Reduce(events)
+}
+
+func (v *testSnapshotView) SnapshotVersion() eventing.SnapshotVersion {
+	return v.snapshotVersion
+}
+
+func (v *testSnapshotView) Snapshot() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (v *testSnapshotView) RestoreSnapshot(version eventing.AggregateVersion, payload []byte) error {
+	if err := json.Unmarshal(payload, v); err != nil {
+		return err
+	}
+	v.RestoreVersion(version)
+	return nil
+}
+
+func Test_pgEventStore_ViewWithSnapshot(t *testing.T) {
+	appendTestEvents := func(t *testing.T, es eventing.EventStore, aggregateID eventing.AggregateID, n int) {
+		t.Helper()
+		events := make([]eventing.Event, n)
+		for i := range events {
+			events[i] = newTestEvent(string(aggregateID))
+		}
+		_, err := es.Append(context.Background(), core.Must2(eventing.NewAggregateChangeIntent(
+			aggregateID,
+			"test",
+			0,
+			events,
+			eventing.VersionMatcherAlways,
+		)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	t.Run("restores from snapshot and replays only newer events", func(t *testing.T) {
+		t.Parallel()
+
+		pool, cleanup := postgres.GetTestPool()
+		t.Cleanup(cleanup)
+
+		snapshots := NewSnapshotStore(pool, &stubCrypto{})
+		es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{}, WithSnapshots(snapshots, 2))
+		aggregateID := idgen.New[eventing.AggregateID]()
+		appendTestEvents(t, es, aggregateID, 3)
+
+		// The first view replays everything and takes a snapshot.
+		view := newTestSnapshotView(aggregateID, 1)
+		if err := es.View(context.Background(), view); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if view.replayed != 3 {
+			t.Errorf("expected 3 replayed events, got %d", view.replayed)
+		}
+		snapshot, err := snapshots.LoadSnapshot(context.Background(), aggregateID, "test", false)
+		if err != nil {
+			t.Fatalf("expected snapshot, got %v", err)
+		}
+		if snapshot.AggregateVersion != 3 {
+			t.Errorf("expected snapshot at version 3, got %d", snapshot.AggregateVersion)
+		}
+
+		appendTestEvents(t, es, aggregateID, 1)
+
+		view = newTestSnapshotView(aggregateID, 1)
+		if err := es.View(context.Background(), view); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if view.replayed != 1 {
+			t.Errorf("expected 1 replayed event, got %d", view.replayed)
+		}
+		if view.Count != 4 {
+			t.Errorf("expected count 4, got %d", view.Count)
+		}
+		if view.Aggregate().Version != 4 {
+			t.Errorf("expected version 4, got %d", view.Aggregate().Version)
+		}
+	})
+
+	t.Run("discards snapshot with outdated version", func(t *testing.T) {
+		t.Parallel()
+
+		pool, cleanup := postgres.GetTestPool()
+		t.Cleanup(cleanup)
+
+		snapshots := NewSnapshotStore(pool, &stubCrypto{})
+		es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{}, WithSnapshots(snapshots, 2))
+		aggregateID := idgen.New[eventing.AggregateID]()
+		appendTestEvents(t, es, aggregateID, 3)
+
+		if err := es.View(context.Background(), newTestSnapshotView(aggregateID, 1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		view := newTestSnapshotView(aggregateID, 2)
+		if err := es.View(context.Background(), view); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if view.replayed != 3 {
+			t.Errorf("expected 3 replayed events, got %d", view.replayed)
+		}
+		snapshot, err := snapshots.LoadSnapshot(context.Background(), aggregateID, "test", false)
+		if err != nil {
+			t.Fatalf("expected snapshot, got %v", err)
+		}
+		if snapshot.SnapshotVersion != 2 {
+			t.Errorf("expected snapshot version 2, got %d", snapshot.SnapshotVersion)
+		}
+	})
+}
+
+func Test_pgEventStore_Metadata(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+
+	es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+	aggregateID := idgen.New[eventing.AggregateID]()
+
+	md := eventing.Metadata{
+		CorrelationID: "correlation",
+		CausationID:   "causation",
+		PrincipalID:   "principal",
+		ClientIP:      "127.0.0.1",
+		UserAgent:     "test",
+	}
+	ctx := eventing.NewContextWithMetadata(context.Background(), md)
+	appended, err := es.Append(ctx, core.Must2(eventing.NewAggregateChangeIntent(
+		aggregateID,
+		"test",
+		0,
+		[]eventing.Event{newTestEvent(string(aggregateID))},
+		eventing.VersionMatcherAlways,
+	)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := appended[0][0].Metadata(); got == nil || *got != md {
+		t.Errorf("expected appended metadata %v, got %v", md, got)
+	}
+
+	var builder eventing.JournalQueryBuilder
+	events, err := es.Query(context.Background(), builder.WithAggregate("test").AggregateID(aggregateID).Finish().MustBuild())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if got := events[0].Metadata(); got == nil || *got != md {
+		t.Errorf("expected queried metadata %v, got %v", md, got)
+	}
+}
+
+func Test_pgEventStore_QueryIter(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+
+	es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+	aggregateID := idgen.New[eventing.AggregateID]()
+
+	// Span more than a single page.
+	n := queryIterPageSize + 10
+	events := make([]eventing.Event, n)
+	for i := range events {
+		events[i] = newTestEvent(string(aggregateID))
+	}
+	_, err := es.Append(context.Background(), core.Must2(eventing.NewAggregateChangeIntent(
+		aggregateID,
+		"test",
+		0,
+		events,
+		eventing.VersionMatcherAlways,
+	)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var builder eventing.JournalQueryBuilder
+	query := builder.WithAggregate("test").AggregateID(aggregateID).Finish().MustBuild()
+
+	var version eventing.AggregateVersion
+	for event, err := range es.QueryIter(context.Background(), query) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if event.AggregateVersion() != version+1 {
+			t.Fatalf("expected version %d, got %d", version+1, event.AggregateVersion())
+		}
+		version = event.AggregateVersion()
+	}
+	if int(version) != n {
+		t.Errorf("expected %d events, got %d", n, version)
+	}
+
+	limited := eventing.NewJournalQueryBuilderFrom(query).WithLimit(queryIterPageSize + 1).MustBuild()
+	var count int
+	for _, err := range es.QueryIter(context.Background(), limited) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+	}
+	if count != queryIterPageSize+1 {
+		t.Errorf("expected %d events, got %d", queryIterPageSize+1, count)
+	}
+}
+
+func Test_pgEventStore_Conformance(t *testing.T) {
+	eventingtest.RunConformance(t, func(t *testing.T, mapper eventing.JournalEventMapper, crypto eventing.EventCrypto) eventing.EventStore {
+		pool, cleanup := postgres.GetTestPool()
+		t.Cleanup(cleanup)
+		return NewEventStore(postgres.GetTestLogger(), pool, mapper, crypto)
+	})
+}
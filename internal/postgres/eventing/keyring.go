@@ -0,0 +1,103 @@
+package eventing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"io"
+)
+
+const keySize = 32
+
+var (
+	ErrUnknownKeyID = errors.New("unknown key-encryption key")
+	// ErrUnwrappedKey is returned for data keys that are still stored in plaintext. They are wrapped by [WrapKeys].
+	ErrUnwrappedKey = errors.New("data key is not wrapped")
+)
+
+// KeyID identifies a key-encryption key.
+type KeyID string
+
+// KeyEncryptionKey is a master key that wraps the per-owner data keys.
+type KeyEncryptionKey struct {
+	ID  KeyID
+	Key []byte
+}
+
+// Keyring holds all known key-encryption keys.
+// New data keys are always wrapped with the active key, while any known key can unwrap.
+type Keyring struct {
+	active KeyID
+	keys   map[KeyID]cipher.AEAD
+}
+
+func NewKeyring(active KeyID, keks ...KeyEncryptionKey) (*Keyring, error) {
+	keyring := &Keyring{
+		active: active,
+		keys:   make(map[KeyID]cipher.AEAD, len(keks)),
+	}
+	for _, kek := range keks {
+		if kek.ID == "" {
+			return nil, errors.New("key-encryption key without ID")
+		}
+		if len(kek.Key) != keySize {
+			return nil, fmt.Errorf("key-encryption key %s must be %d bytes long", kek.ID, keySize)
+		}
+		if _, ok := keyring.keys[kek.ID]; ok {
+			return nil, fmt.Errorf("duplicate key-encryption key %s", kek.ID)
+		}
+		aead, err := newAEAD(kek.Key)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[kek.ID] = aead
+	}
+	if _, ok := keyring.keys[active]; !ok {
+		return nil, fmt.Errorf("active key-encryption key %s: %w", active, ErrUnknownKeyID)
+	}
+	return keyring, nil
+}
+
+// ActiveKeyID returns the ID of the key that wraps new data keys.
+func (k *Keyring) ActiveKeyID() KeyID {
+	return k.active
+}
+
+// wrap encrypts the data key of the owner with the active key-encryption key.
+// The owner is bound as additional data, so a wrapped key cannot be moved to another owner.
+func (k *Keyring) wrap(owner eventing.AggregateID, dataKey []byte) (KeyID, []byte, error) {
+	aead := k.keys[k.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, err
+	}
+	return k.active, aead.Seal(nonce, nonce, dataKey, []byte(owner)), nil
+}
+
+// unwrap decrypts the data key of the owner with the key-encryption key it was wrapped with.
+func (k *Keyring) unwrap(owner eventing.AggregateID, id KeyID, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("key-encryption key %s: %w", id, ErrUnknownKeyID)
+	}
+	nonceSize := aead.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+	dataKey, err := aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], []byte(owner))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key of %s: %w", owner, err)
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
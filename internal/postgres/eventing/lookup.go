@@ -0,0 +1,102 @@
+package eventing
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+)
+
+// HashSensitiveLookups replaces the plaintext values of sensitive lookups and unique constraints with their hash.
+// Such values have been stored before their fields were declared sensitive.
+// Values that are already hashed are skipped, so it is safe to run this on every start.
+// Returns the number of hashed values.
+func HashSensitiveLookups(ctx context.Context, pool *pgxpool.Pool, hasher eventing.LookupHasher, lookupFields []eventing.LookupFieldName, constraintFields []string) (int, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.HashSensitiveLookups")
+	defer span.End()
+
+	var hashed int
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, selectPlaintextLookupsSQL, lookupFields, eventing.HashedLookupPrefix+"%")
+		if err != nil {
+			return err
+		}
+		type lookup struct {
+			id         string
+			fieldName  eventing.LookupFieldName
+			fieldValue eventing.LookupFieldValue
+		}
+		lookups, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (lookup, error) {
+			var l lookup
+			err := row.Scan(&l.id, &l.fieldName, &l.fieldValue)
+			return l, err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to select plaintext lookups: %w", err)
+		}
+
+		rows, err = tx.Query(ctx, selectPlaintextConstraintsSQL, constraintFields, eventing.HashedLookupPrefix+"%")
+		if err != nil {
+			return err
+		}
+		constraints, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (eventing.UniqueConstraint, error) {
+			var (
+				field, value string
+				ownerID      eventing.AggregateID
+			)
+			err := row.Scan(&field, &value, &ownerID)
+			return eventing.NewSensitiveUniqueConstraint(ownerID, field, value), err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to select plaintext unique constraints: %w", err)
+		}
+
+		batch := &pgx.Batch{}
+		for _, l := range lookups {
+			batch.Queue(updateLookupValueSQL, hasher.HashLookupValue(string(l.fieldName), string(l.fieldValue)), l.id)
+		}
+		for _, constraint := range constraints {
+			protected := constraint.Protect(hasher)
+			batch.Queue(updateConstraintValueSQL, protected.ConstrainedValue(), constraint.ConstrainedField(), constraint.ConstrainedValue())
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to hash sensitive lookups: %w", err)
+		}
+		hashed = len(lookups) + len(constraints)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return hashed, nil
+}
+
+const selectPlaintextLookupsSQL = `
+SELECT id, field_name, field_value
+FROM event_journal_lookup
+WHERE field_name = ANY ($1)
+AND field_value NOT LIKE $2
+FOR UPDATE
+`
+
+const selectPlaintextConstraintsSQL = `
+SELECT field, value, COALESCE(owner_aggregate_id, '')
+FROM unique_constraint
+WHERE field = ANY ($1)
+AND value NOT LIKE $2
+FOR UPDATE
+`
+
+const updateLookupValueSQL = `
+UPDATE event_journal_lookup
+SET field_value = $1
+WHERE id = $2
+`
+
+const updateConstraintValueSQL = `
+UPDATE unique_constraint
+SET value = $1
+WHERE field = $2 AND value = $3
+`
@@ -0,0 +1,55 @@
+package eventing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsmidt/soccerbuddy/internal/core"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+)
+
+func TestHashSensitiveLookups(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	// Use a unique field, as the tables are shared with other tests.
+	field := "email_" + idgen.NewString()
+	owner := idgen.New[eventing.AggregateID]()
+	core.Must2(pool.Exec(ctx, "INSERT INTO event_journal_lookup (id, owner_aggregate_id, owner_aggregate_type, field_name, field_value) VALUES ($1, $2, 'test', $3, 'Jane@example.com')", idgen.NewString(), owner, field))
+	core.Must2(pool.Exec(ctx, "INSERT INTO unique_constraint (field, value, owner_aggregate_id) VALUES ($1, 'Jane@example.com', $2)", field, owner))
+
+	hashed, err := HashSensitiveLookups(ctx, pool, testHasher, []eventing.LookupFieldName{eventing.LookupFieldName(field)}, []string{field})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hashed != 2 {
+		t.Errorf("expected 2 hashed values, got %d", hashed)
+	}
+	es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, NewEventCrypto(pool, core.Must2(NewKeyring("k1", KeyEncryptionKey{ID: "k1", Key: newTestKey(1)})), testHasher))
+	found, err := es.OwnerLookup(ctx, eventing.LookupOpts{AggregateType: "test", FieldName: eventing.LookupFieldName(field), FieldValue: "Jane@example.com", Sensitive: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found != owner {
+		t.Errorf("expected owner %s, got %s", owner, found)
+	}
+	var constraintValue string
+	core.Must(pool.QueryRow(ctx, "SELECT value FROM unique_constraint WHERE owner_aggregate_id = $1", owner).Scan(&constraintValue))
+	if expected := testHasher.HashLookupValue(field, "jane@example.com"); constraintValue != expected {
+		t.Errorf("expected constraint value %s, got %s", expected, constraintValue)
+	}
+
+	// Hashed values are not hashed again.
+	hashed, err = HashSensitiveLookups(ctx, pool, testHasher, []eventing.LookupFieldName{eventing.LookupFieldName(field)}, []string{field})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hashed != 0 {
+		t.Errorf("expected 0 hashed values, got %d", hashed)
+	}
+}
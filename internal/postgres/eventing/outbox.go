@@ -0,0 +1,331 @@
+package eventing
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	outboxBatchSize              = 100
+	defaultOutboxPollingInterval = 5 * time.Second
+	outboxMinBackoff             = time.Second
+	outboxMaxBackoff             = 10 * time.Minute
+	// outboxLease is the time a claimed entry is reserved for its dispatcher before it is due again.
+	outboxLease = 5 * time.Minute
+	// outboxMaxAttempts is the number of attempts after which an entry is dead-lettered.
+	outboxMaxAttempts = 20
+)
+
+var _ eventing.Outbox = (*Outbox)(nil)
+
+// Outbox writes an entry per interested handler in the same transaction as the events
+// and delivers them to the handlers afterward.
+// Entries are leased to an instance while they are delivered, so multiple instances can dispatch concurrently.
+type Outbox struct {
+	log     *slog.Logger
+	pool    *pgxpool.Pool
+	mapper  eventing.JournalEventMapper
+	crypto  eventing.EventDecrypter
+	trigger chan struct{}
+
+	mu       sync.RWMutex
+	handlers map[string]eventing.OutboxHandler
+
+	pollingInterval time.Duration
+}
+
+type OutboxOpt func(o *Outbox)
+
+// WithOutboxPollingInterval sets the interval in which Run dispatches due entries.
+func WithOutboxPollingInterval(interval time.Duration) OutboxOpt {
+	return func(o *Outbox) {
+		o.pollingInterval = interval
+	}
+}
+
+func NewOutbox(log *slog.Logger, pool *pgxpool.Pool, mapper eventing.JournalEventMapper, crypto eventing.EventDecrypter, opts ...OutboxOpt) *Outbox {
+	o := &Outbox{
+		log:             log,
+		pool:            pool,
+		mapper:          mapper,
+		crypto:          crypto,
+		trigger:         make(chan struct{}, 1),
+		handlers:        make(map[string]eventing.OutboxHandler),
+		pollingInterval: defaultOutboxPollingInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *Outbox) Register(handler eventing.OutboxHandler) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.handlers[handler.Name()] = handler
+}
+
+// write adds the entries for the persisted events to the outbox.
+func (o *Outbox) write(ctx context.Context, tx pgx.Tx, events []*eventing.JournalEvent) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.Outbox.write")
+	defer span.End()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	var rows [][]any
+	for _, event := range events {
+		interest := eventing.EventInterest{AggType: event.AggregateType(), EventType: event.EventType()}
+		for name, handler := range o.handlers {
+			if handler.Interests().IsInterestedIn(interest) {
+				rows = append(rows, []any{idgen.NewString(), name, event.EventID()})
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"outbox"}, []string{"id", "handler", "event_id"}, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) Trigger() {
+	select {
+	case o.trigger <- struct{}{}:
+	default:
+		// A dispatch is already pending.
+	}
+}
+
+func (o *Outbox) Run(ctx context.Context) error {
+	timer := time.NewTimer(o.pollingInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		case <-o.trigger:
+		}
+		if _, err := o.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			o.log.Error("Failed to dispatch outbox", slog.Any("error", err))
+		}
+		timer.Reset(o.pollingInterval)
+	}
+}
+
+func (o *Outbox) Dispatch(ctx context.Context, handlers ...string) (int, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.Outbox.Dispatch")
+	defer span.End()
+
+	o.mu.RLock()
+	if len(handlers) == 0 {
+		handlers = slices.Collect(maps.Keys(o.handlers))
+	} else {
+		// Entries of unknown handlers can't be delivered and would be selected over and over again.
+		handlers = slices.DeleteFunc(slices.Clone(handlers), func(name string) bool {
+			_, ok := o.handlers[name]
+			return !ok
+		})
+	}
+	o.mu.RUnlock()
+	if len(handlers) == 0 {
+		return 0, nil
+	}
+	var delivered int
+	for ctx.Err() == nil {
+		n, processed, err := o.dispatchBatch(ctx, handlers)
+		delivered += n
+		if err != nil {
+			return delivered, err
+		}
+		if processed < outboxBatchSize {
+			break
+		}
+	}
+	return delivered, ctx.Err()
+}
+
+type outboxEntry struct {
+	id       string
+	handler  string
+	attempts int
+	eventID  eventing.EventID
+	position eventing.JournalPosition
+	event    *eventing.JournalEvent
+	// err is set if the event of the entry could not be mapped or decrypted.
+	err error
+}
+
+// dispatchBatch delivers a batch of due entries and returns the number of delivered and processed entries.
+// No transaction is held open while the handlers run, as it would hold back the projections that wait for older transactions.
+func (o *Outbox) dispatchBatch(ctx context.Context, handlers []string) (int, int, error) {
+	entries, err := o.claimDueEntries(ctx, handlers)
+	if err != nil || len(entries) == 0 {
+		return 0, 0, err
+	}
+	o.decryptEntries(ctx, entries)
+
+	var delivered int
+	batch := &pgx.Batch{}
+	for _, entry := range entries {
+		if err := o.deliver(ctx, entry); err != nil {
+			o.queueFailure(batch, entry, err)
+			continue
+		}
+		batch.Queue("DELETE FROM outbox WHERE id = $1", entry.id)
+		delivered++
+	}
+	err = pgx.BeginFunc(ctx, o.pool, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		// The entries are delivered again once their lease expired.
+		return 0, 0, fmt.Errorf("failed to update outbox: %w", err)
+	}
+	return delivered, len(entries), nil
+}
+
+// deliver hands the event of the entry to its handler.
+func (o *Outbox) deliver(ctx context.Context, entry *outboxEntry) error {
+	if entry.err != nil {
+		return entry.err
+	}
+	o.mu.RLock()
+	handler, ok := o.handlers[entry.handler]
+	o.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("handler %s is not registered", entry.handler)
+	}
+	// Events appended by the handler are caused by the delivered event.
+	return handler.Handle(eventing.NewContextWithCausation(ctx, entry.event), entry.event)
+}
+
+// queueFailure reschedules the entry with a backoff or dead-letters it once it ran out of attempts.
+func (o *Outbox) queueFailure(batch *pgx.Batch, entry *outboxEntry, err error) {
+	log := o.log.With(
+		slog.String("handler", entry.handler),
+		slog.String("event_id", string(entry.eventID)),
+		slog.Int("attempts", entry.attempts),
+		slog.Any("error", err))
+	if entry.attempts >= outboxMaxAttempts {
+		log.Error("Dead-lettering outbox entry")
+		batch.Queue(deadLetterOutboxEntrySQL, entry.id, err.Error())
+		return
+	}
+	backoff := outboxBackoff(entry.attempts)
+	log.Warn("Failed to deliver outbox entry", slog.Duration("backoff", backoff))
+	batch.Queue(retryOutboxEntrySQL, entry.id, err.Error(), backoff)
+}
+
+// claimDueEntries leases a batch of due entries to this instance and counts the attempt.
+// The claim is committed right away, entries of a crashed instance become due again once their lease expired.
+func (o *Outbox) claimDueEntries(ctx context.Context, handlers []string) ([]*outboxEntry, error) {
+	var entries []*outboxEntry
+	err := pgx.BeginFunc(ctx, o.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, claimDueOutboxEntriesSQL, handlers, outboxBatchSize, outboxLease)
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox entries: %w", err)
+		}
+		var (
+			aggregateID      eventing.AggregateID
+			aggregateType    eventing.AggregateType
+			aggregateVersion eventing.AggregateVersion
+			eventType        eventing.EventType
+			eventVersion     eventing.EventVersion
+			payload          []byte
+			metadata         *eventing.Metadata
+			createdAt        time.Time
+		)
+		entries, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*outboxEntry, error) {
+			var entry outboxEntry
+			metadata = nil
+			err := row.Scan(&entry.id, &entry.handler, &entry.attempts, &entry.eventID, &aggregateID, &aggregateType, &aggregateVersion, &entry.position.TransactionID, &entry.position.Sequence, &eventType, &eventVersion, &payload, &metadata, &createdAt)
+			if err != nil {
+				return nil, err
+			}
+			// An event that can't be mapped only fails its own entry.
+			entry.event, entry.err = o.mapper.MapFrom(aggregateID, aggregateType, eventVersion, eventType, entry.eventID, aggregateVersion, entry.position, createdAt, payload)
+			if entry.err != nil {
+				entry.err = fmt.Errorf("failed to map event: %w", entry.err)
+				return &entry, nil
+			}
+			entry.event.SetMetadata(metadata)
+			return &entry, nil
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entries, func(a, b *outboxEntry) int {
+		return a.position.Compare(b.position)
+	})
+	return entries, nil
+}
+
+// decryptEntries decrypts the events of the entries one by one, so that an event that can't be decrypted only fails its own entry.
+func (o *Outbox) decryptEntries(ctx context.Context, entries []*outboxEntry) {
+	for _, entry := range entries {
+		if entry.err != nil {
+			continue
+		}
+		if err := o.crypto.DecryptEvents(ctx, eventing.JournalEventsWithMetadata([]*eventing.JournalEvent{entry.event})); err != nil {
+			entry.err = fmt.Errorf("failed to decrypt event: %w", err)
+		}
+	}
+}
+
+// outboxBackoff returns the exponential backoff after the given number of failed attempts.
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxMinBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, outboxMaxBackoff)
+}
+
+const claimDueOutboxEntriesSQL = `
+WITH due AS (SELECT o.id
+             FROM outbox o
+             JOIN event_journal ej ON ej.id = o.event_id
+             WHERE o.handler = ANY ($1)
+             AND o.dead_lettered_at IS NULL
+             AND o.next_attempt_at <= NOW()
+             ORDER BY ej.transaction_id ASC, ej.sequence ASC
+             LIMIT $2
+             FOR UPDATE OF o SKIP LOCKED)
+UPDATE outbox o
+SET attempts = o.attempts + 1, next_attempt_at = NOW() + $3::interval
+FROM due, event_journal ej
+WHERE o.id = due.id
+AND ej.id = o.event_id
+RETURNING o.id, o.handler, o.attempts,
+          ej.id, ej.aggregate_id, ej.aggregate_type, ej.aggregate_version, ej.transaction_id, ej.sequence, ej.event_type, ej.event_version, ej.payload, ej.metadata, ej.created_at
+`
+
+const retryOutboxEntrySQL = `
+UPDATE outbox
+SET last_error = $2, next_attempt_at = NOW() + $3::interval
+WHERE id = $1
+`
+
+const deadLetterOutboxEntrySQL = `
+UPDATE outbox
+SET last_error = $2, dead_lettered_at = NOW()
+WHERE id = $1
+`
@@ -0,0 +1,180 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsmidt/soccerbuddy/internal/core"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+)
+
+type testOutboxHandler struct {
+	name    string
+	failing bool
+	handled []eventing.EventID
+}
+
+func (h *testOutboxHandler) Name() string {
+	return h.name
+}
+
+func (h *testOutboxHandler) Interests() eventing.EventInterestSet {
+	return eventing.NewInterestSet(eventing.EventInterest{AggType: "test", EventType: "TestEvent"})
+}
+
+func (h *testOutboxHandler) Handle(ctx context.Context, event *eventing.JournalEvent) error {
+	if h.failing {
+		return errors.New("failed")
+	}
+	h.handled = append(h.handled, event.EventID())
+	return nil
+}
+
+// shreddedCrypto fails to decrypt the events of an owner, as if its key was lost.
+type shreddedCrypto struct {
+	stubCrypto
+	owner eventing.AggregateID
+}
+
+func (s *shreddedCrypto) DecryptEvents(ctx context.Context, events []eventing.Event) error {
+	for _, event := range events {
+		if event.AggregateID() == s.owner {
+			return errors.New("key not found")
+		}
+	}
+	return nil
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: time.Second},
+		{attempts: 2, expected: 2 * time.Second},
+		{attempts: 5, expected: 16 * time.Second},
+		{attempts: 100, expected: outboxMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.expected {
+			t.Errorf("expected backoff %s after %d attempts, got %s", tt.expected, tt.attempts, got)
+		}
+	}
+}
+
+func TestOutbox_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	delivered := &testOutboxHandler{name: "delivered"}
+	failing := &testOutboxHandler{name: "failing", failing: true}
+	outbox := NewOutbox(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+	outbox.Register(delivered)
+	outbox.Register(failing)
+	es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{}, WithOutbox(outbox))
+
+	aggregateID := idgen.New[eventing.AggregateID]()
+	appended, err := es.Append(ctx, core.Must2(eventing.NewAggregateChangeIntent(
+		aggregateID,
+		"test",
+		0,
+		[]eventing.Event{newTestEvent(string(aggregateID)), newTestEventUniqueConstraint(string(aggregateID))},
+		eventing.VersionMatcherAlways,
+	)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only the event of interest is delivered.
+	n, err := outbox.Dispatch(ctx, delivered.Name(), failing.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 delivered entry, got %d", n)
+	}
+	if len(delivered.handled) != 1 || delivered.handled[0] != appended[0][0].EventID() {
+		t.Errorf("expected event %s to be delivered, got %v", appended[0][0].EventID(), delivered.handled)
+	}
+
+	// Delivered entries are removed, failed ones are retried after a backoff.
+	var attempts int
+	var lastError string
+	core.Must(pool.QueryRow(ctx, "SELECT attempts, last_error FROM outbox WHERE handler = $1", failing.Name()).Scan(&attempts, &lastError))
+	if attempts != 1 || lastError != "failed" {
+		t.Errorf("expected 1 failed attempt, got %d with %q", attempts, lastError)
+	}
+	failing.failing = false
+	if n, err := outbox.Dispatch(ctx, delivered.Name(), failing.Name()); err != nil || n != 0 {
+		t.Errorf("expected no due entries, got %d, %v", n, err)
+	}
+	core.Must2(pool.Exec(ctx, "UPDATE outbox SET next_attempt_at = NOW() WHERE handler = $1", failing.Name()))
+	if n, err := outbox.Dispatch(ctx, failing.Name()); err != nil || n != 1 {
+		t.Errorf("expected the failed entry to be delivered, got %d, %v", n, err)
+	}
+	var pending int
+	core.Must(pool.QueryRow(ctx, "SELECT COUNT(*) FROM outbox WHERE handler = ANY ($1)", []string{delivered.Name(), failing.Name()}).Scan(&pending))
+	if pending != 0 {
+		t.Errorf("expected no pending entries, got %d", pending)
+	}
+}
+
+func TestOutbox_DeadLetter(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	shredded := idgen.New[eventing.AggregateID]()
+	handler := &testOutboxHandler{name: "handler"}
+	outbox := NewOutbox(postgres.GetTestLogger(), pool, &testRegistry{}, &shreddedCrypto{owner: shredded})
+	outbox.Register(handler)
+	es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{}, WithOutbox(outbox))
+
+	readable := idgen.New[eventing.AggregateID]()
+	for _, id := range []eventing.AggregateID{shredded, readable} {
+		_, err := es.Append(ctx, core.Must2(eventing.NewAggregateChangeIntent(id, "test", 0, []eventing.Event{newTestEvent(string(id))}, eventing.VersionMatcherAlways)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// An event that can't be decrypted only fails its own entry.
+	n, err := outbox.Dispatch(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || len(handler.handled) != 1 {
+		t.Fatalf("expected the readable event to be delivered, got %d", n)
+	}
+
+	// The failed entry is dead-lettered once it ran out of attempts.
+	core.Must2(pool.Exec(ctx, "UPDATE outbox SET attempts = $1, next_attempt_at = NOW()", outboxMaxAttempts-1))
+	if n, err := outbox.Dispatch(ctx); err != nil || n != 0 {
+		t.Fatalf("expected no delivery, got %d, %v", n, err)
+	}
+	var attempts int
+	var deadLettered bool
+	core.Must(pool.QueryRow(ctx, "SELECT attempts, dead_lettered_at IS NOT NULL FROM outbox").Scan(&attempts, &deadLettered))
+	if attempts != outboxMaxAttempts || !deadLettered {
+		t.Errorf("expected the entry to be dead-lettered after %d attempts, got %d", outboxMaxAttempts, attempts)
+	}
+
+	// Dead letters are never due again.
+	core.Must2(pool.Exec(ctx, "UPDATE outbox SET next_attempt_at = NOW()"))
+	if n, err := outbox.Dispatch(ctx); err != nil || n != 0 {
+		t.Errorf("expected the dead letter to be skipped, got %d, %v", n, err)
+	}
+	core.Must(pool.QueryRow(ctx, "SELECT attempts FROM outbox").Scan(&attempts))
+	if attempts != outboxMaxAttempts {
+		t.Errorf("expected the dead letter not to be claimed again, got %d attempts", attempts)
+	}
+}
@@ -0,0 +1,115 @@
+package eventing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"time"
+)
+
+const plaintextEventBatchSize = 500
+
+// EncryptPlaintextEvents rewrites the events of the versions that stored values in plaintext.
+// The events are upcast by the mapper, which marks their values as plaintext, and are stored encrypted in the
+// current version, so that their values can be shredded along with their owner.
+// Rewritten events no longer match their version, so it is safe to run this repeatedly.
+// Returns the number of rewritten events.
+func EncryptPlaintextEvents(ctx context.Context, pool *pgxpool.Pool, mapper eventing.JournalEventMapper, crypto eventing.EventCrypto, versions ...eventing.PlaintextEventVersion) (int, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.EncryptPlaintextEvents")
+	defer span.End()
+
+	var encrypted int
+	for _, version := range versions {
+		for ctx.Err() == nil {
+			var processed int
+			err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+				var err error
+				processed, err = encryptPlaintextEventBatch(postgres.WithTx(ctx, tx), tx, mapper, crypto, version)
+				return err
+			})
+			if err != nil {
+				tracing.RecordError(ctx, err)
+				return encrypted, fmt.Errorf("failed to encrypt %s %s events: %w", version.EventType, version.EventVersion, err)
+			}
+			encrypted += processed
+			if processed < plaintextEventBatchSize {
+				break
+			}
+		}
+	}
+	return encrypted, ctx.Err()
+}
+
+func encryptPlaintextEventBatch(ctx context.Context, tx pgx.Tx, mapper eventing.JournalEventMapper, crypto eventing.EventCrypto, version eventing.PlaintextEventVersion) (int, error) {
+	rows, err := tx.Query(ctx, selectPlaintextEventsSQL, version.AggregateType, version.EventType, version.EventVersion, plaintextEventBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		id               eventing.EventID
+		aggregateID      eventing.AggregateID
+		aggregateVersion eventing.AggregateVersion
+		payload          []byte
+		createdAt        time.Time
+	)
+	journalEvents, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*eventing.JournalEvent, error) {
+		if err := row.Scan(&id, &aggregateID, &aggregateVersion, &payload, &createdAt); err != nil {
+			return nil, err
+		}
+		event, err := mapper.MapFrom(aggregateID, version.AggregateType, version.EventVersion, version.EventType, id, aggregateVersion, eventing.JournalPosition{}, createdAt, payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map event %s: %w", id, err)
+		}
+		// The version would be selected over and over again otherwise.
+		if event.EventVersion() == version.EventVersion {
+			return nil, fmt.Errorf("event %s has not been upcast", id)
+		}
+		return event, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	events := make([]eventing.Event, len(journalEvents))
+	for i, event := range journalEvents {
+		events[i] = event.Event
+	}
+	// Decrypting strips the plaintext marker of the values, so that they are encrypted as they are.
+	if err := crypto.DecryptEvents(ctx, events); err != nil {
+		return 0, err
+	}
+	if err := crypto.EncryptEvents(ctx, events); err != nil {
+		return 0, err
+	}
+	batch := &pgx.Batch{}
+	for _, event := range journalEvents {
+		payload, err := json.Marshal(event.Event)
+		if err != nil {
+			return 0, err
+		}
+		batch.Queue(updateEncryptedEventSQL, payload, event.EventVersion(), event.EventID())
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, fmt.Errorf("failed to update events: %w", err)
+	}
+	return len(journalEvents), nil
+}
+
+const selectPlaintextEventsSQL = `
+SELECT id, aggregate_id, aggregate_version, payload, created_at
+FROM event_journal
+WHERE aggregate_type = $1 AND event_type = $2 AND event_version = $3
+LIMIT $4
+FOR UPDATE SKIP LOCKED
+`
+
+const updateEncryptedEventSQL = `
+UPDATE event_journal
+SET payload = $1, event_version = $2
+WHERE id = $3
+`
@@ -0,0 +1,128 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+)
+
+var _ eventing.ProjectionFailureStore = (*ProjectionFailureStore)(nil)
+
+// ProjectionFailureStore keeps the failures of the projectors of all supervisors,
+// so that they can be inspected and re-driven from any instance.
+type ProjectionFailureStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewProjectionFailureStore(pool *pgxpool.Pool) *ProjectionFailureStore {
+	return &ProjectionFailureStore{pool: pool}
+}
+
+func (s *ProjectionFailureStore) Record(ctx context.Context, projection eventing.ProjectionName, event *eventing.JournalEvent, cause error, policy eventing.FailurePolicy) (*eventing.ProjectionFailure, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectionFailureStore.Record")
+	defer span.End()
+
+	var failure *eventing.ProjectionFailure
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var attempts int
+		err := tx.QueryRow(ctx, "SELECT attempts FROM projection_failure WHERE projection_name = $1 AND event_id = $2 FOR UPDATE", projection, event.EventID()).Scan(&attempts)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		attempts++
+		state := eventing.NextFailureState(policy, attempts)
+		if _, err := tx.Exec(ctx, upsertProjectionFailureSQL, projection, event.EventID(), state, attempts, cause.Error(), policy.Backoff(attempts)); err != nil {
+			return err
+		}
+		failure, err = s.get(ctx, tx, projection, event.EventID())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record projection failure: %w", err)
+	}
+	return failure, nil
+}
+
+func (s *ProjectionFailureStore) Blocked(ctx context.Context, projection eventing.ProjectionName) (bool, error) {
+	var blocked bool
+	err := s.pool.QueryRow(ctx, blockedProjectionSQL, projection, eventing.FailureStateHalted, eventing.FailureStateRetrying).Scan(&blocked)
+	return blocked, err
+}
+
+func (s *ProjectionFailureStore) Resolve(ctx context.Context, projection eventing.ProjectionName) error {
+	_, err := s.pool.Exec(ctx, "DELETE FROM projection_failure WHERE projection_name = $1 AND state = $2", projection, eventing.FailureStateRetrying)
+	return err
+}
+
+func (s *ProjectionFailureStore) List(ctx context.Context, projection eventing.ProjectionName) ([]*eventing.ProjectionFailure, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectionFailureStore.List")
+	defer span.End()
+
+	rows, err := s.pool.Query(ctx, selectProjectionFailuresSQL+" WHERE ($1 = '' OR pf.projection_name = $1) ORDER BY pf.projection_name, ej.transaction_id, ej.sequence", projection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projection failures: %w", err)
+	}
+	return pgx.CollectRows(rows, scanProjectionFailure)
+}
+
+func (s *ProjectionFailureStore) Get(ctx context.Context, projection eventing.ProjectionName, eventID eventing.EventID) (*eventing.ProjectionFailure, error) {
+	return s.get(ctx, s.pool, projection, eventID)
+}
+
+func (s *ProjectionFailureStore) get(ctx context.Context, db postgres.DB, projection eventing.ProjectionName, eventID eventing.EventID) (*eventing.ProjectionFailure, error) {
+	rows, err := db.Query(ctx, selectProjectionFailuresSQL+" WHERE pf.projection_name = $1 AND pf.event_id = $2", projection, eventID)
+	if err != nil {
+		return nil, err
+	}
+	failure, err := pgx.CollectExactlyOneRow(rows, scanProjectionFailure)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, eventing.ErrProjectionFailureNotFound
+	}
+	return failure, err
+}
+
+func (s *ProjectionFailureStore) Delete(ctx context.Context, projection eventing.ProjectionName, eventID eventing.EventID) error {
+	_, err := s.pool.Exec(ctx, "DELETE FROM projection_failure WHERE projection_name = $1 AND event_id = $2", projection, eventID)
+	return err
+}
+
+func (s *ProjectionFailureStore) Clear(ctx context.Context, projection eventing.ProjectionName) error {
+	_, err := s.pool.Exec(ctx, "DELETE FROM projection_failure WHERE projection_name = $1", projection)
+	return err
+}
+
+func scanProjectionFailure(row pgx.CollectableRow) (*eventing.ProjectionFailure, error) {
+	var f eventing.ProjectionFailure
+	err := row.Scan(
+		&f.Projection, &f.EventID, &f.State, &f.Attempts, &f.LastError, &f.NextAttemptAt, &f.CreatedAt, &f.UpdatedAt,
+		&f.EventType, &f.AggregateID, &f.AggregateType, &f.AggregateVersion, &f.Position.TransactionID, &f.Position.Sequence,
+	)
+	return &f, err
+}
+
+const upsertProjectionFailureSQL = `
+INSERT INTO projection_failure (projection_name, event_id, state, attempts, last_error, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, NOW() + $6::interval)
+ON CONFLICT (projection_name, event_id) DO UPDATE
+SET state = excluded.state, attempts = excluded.attempts, last_error = excluded.last_error, next_attempt_at = excluded.next_attempt_at, updated_at = NOW()
+`
+
+const blockedProjectionSQL = `
+SELECT EXISTS (
+	SELECT 1 FROM projection_failure
+	WHERE projection_name = $1
+	AND (state = $2 OR (state = $3 AND next_attempt_at > NOW()))
+)
+`
+
+const selectProjectionFailuresSQL = `
+SELECT pf.projection_name, pf.event_id, pf.state, pf.attempts, pf.last_error, pf.next_attempt_at, pf.created_at, pf.updated_at,
+       ej.event_type, ej.aggregate_id, ej.aggregate_type, ej.aggregate_version, ej.transaction_id, ej.sequence
+FROM projection_failure pf
+JOIN event_journal ej ON ej.id = pf.event_id
+`
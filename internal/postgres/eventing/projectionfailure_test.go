@@ -0,0 +1,82 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsmidt/soccerbuddy/internal/core"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+)
+
+func TestProjectionFailureStore(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	es := NewEventStore(postgres.GetTestLogger(), pool, &testRegistry{}, &stubCrypto{})
+	aggregateID := idgen.New[eventing.AggregateID]()
+	appended, err := es.Append(ctx, core.Must2(eventing.NewAggregateChangeIntent(
+		aggregateID,
+		"test",
+		0,
+		[]eventing.Event{newTestEvent(string(aggregateID))},
+		eventing.VersionMatcherAlways,
+	)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event := appended[0][0]
+
+	store := NewProjectionFailureStore(pool)
+	projection := eventing.ProjectionName("test_" + idgen.NewString())
+	policy := eventing.FailurePolicy{Action: eventing.FailureActionDeadLetter, MaxAttempts: 2}
+
+	// The first attempt is retried and holds back the projection.
+	failure, err := store.Record(ctx, projection, event, errors.New("failed"), eventing.FailurePolicy{Action: eventing.FailureActionRetry, MinBackoff: time.Hour, MaxBackoff: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failure.State != eventing.FailureStateRetrying || failure.Attempts != 1 || failure.AggregateID != aggregateID {
+		t.Errorf("expected first retrying attempt of the event, got %+v", failure)
+	}
+	if blocked, err := store.Blocked(ctx, projection); err != nil || !blocked {
+		t.Errorf("expected projection to be blocked, got %t, %v", blocked, err)
+	}
+
+	// The second attempt dead-letters the event, which no longer holds back the projection.
+	failure, err = store.Record(ctx, projection, event, errors.New("failed again"), policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failure.State != eventing.FailureStateDeadLettered || failure.Attempts != 2 || failure.LastError != "failed again" {
+		t.Errorf("expected dead-lettered failure after 2 attempts, got %+v", failure)
+	}
+	if blocked, err := store.Blocked(ctx, projection); err != nil || blocked {
+		t.Errorf("expected projection not to be blocked, got %t, %v", blocked, err)
+	}
+
+	// Resolving only removes retried failures.
+	if err := store.Resolve(ctx, projection); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	failures, err := store.List(ctx, projection)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(failures) != 1 || failures[0].EventID != event.EventID() {
+		t.Errorf("expected the dead letter to be listed, got %d failures", len(failures))
+	}
+
+	if err := store.Delete(ctx, projection, event.EventID()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Get(ctx, projection, event.EventID()); !errors.Is(err, eventing.ErrProjectionFailureNotFound) {
+		t.Errorf("expected %v, got %v", eventing.ErrProjectionFailureNotFound, err)
+	}
+}
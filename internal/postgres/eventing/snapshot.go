@@ -0,0 +1,123 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+)
+
+const (
+	snapshotEventType    = eventing.EventType("snapshot")
+	snapshotEventVersion = eventing.EventVersion("v1")
+)
+
+type pgSnapshotStore struct {
+	pool   *pgxpool.Pool
+	crypto eventing.EventCrypto
+}
+
+func NewSnapshotStore(pool *pgxpool.Pool, crypto eventing.EventCrypto) eventing.SnapshotStore {
+	return &pgSnapshotStore{
+		pool:   pool,
+		crypto: crypto,
+	}
+}
+
+func (p *pgSnapshotStore) LoadSnapshot(ctx context.Context, aggregateID eventing.AggregateID, aggregateType eventing.AggregateType, encrypted bool) (*eventing.Snapshot, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.SnapshotStore.LoadSnapshot")
+	defer span.End()
+
+	db := postgres.GetDBFromContext(ctx, p.pool)
+	stmt := "SELECT aggregate_version, snapshot_version, payload, encrypted FROM aggregate_snapshot WHERE aggregate_id = $1 AND aggregate_type = $2"
+	var (
+		snapshot = eventing.Snapshot{
+			AggregateID:   aggregateID,
+			AggregateType: aggregateType,
+		}
+		isEncrypted bool
+	)
+	err := db.QueryRow(ctx, stmt, aggregateID, aggregateType).Scan(&snapshot.AggregateVersion, &snapshot.SnapshotVersion, &snapshot.Payload, &isEncrypted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, eventing.ErrSnapshotNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	// Never hand out a plain snapshot for an aggregate that expects an encrypted one (or vice versa).
+	if isEncrypted != encrypted {
+		return nil, eventing.ErrSnapshotNotFound
+	}
+	if !isEncrypted {
+		return &snapshot, nil
+	}
+
+	event := newSnapshotEvent(aggregateID, aggregateType, snapshot.Payload)
+	if err := p.crypto.DecryptEvents(ctx, []eventing.Event{event}); err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot: %w", err)
+	}
+	// The key of the aggregate is gone, so is the snapshot.
+	if event.IsShredded() {
+		return nil, eventing.ErrSnapshotNotFound
+	}
+	snapshot.Payload = []byte(event.Payload.Value)
+	return &snapshot, nil
+}
+
+func (p *pgSnapshotStore) SaveSnapshot(ctx context.Context, snapshot *eventing.Snapshot, encrypted bool) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.SnapshotStore.SaveSnapshot")
+	defer span.End()
+
+	payload := snapshot.Payload
+	if encrypted {
+		event := newSnapshotEvent(snapshot.AggregateID, snapshot.AggregateType, payload)
+		if err := p.crypto.EncryptEvents(ctx, []eventing.Event{event}); err != nil {
+			return fmt.Errorf("failed to encrypt snapshot: %w", err)
+		}
+		payload = []byte(event.Payload.Value)
+	}
+
+	db := postgres.GetDBFromContext(ctx, p.pool)
+	stmt := `INSERT INTO aggregate_snapshot (aggregate_id, aggregate_type, aggregate_version, snapshot_version, payload, encrypted)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (aggregate_id, aggregate_type) DO UPDATE SET aggregate_version = excluded.aggregate_version,
+                                                         snapshot_version  = excluded.snapshot_version,
+                                                         payload           = excluded.payload,
+                                                         encrypted         = excluded.encrypted,
+                                                         updated_at        = CURRENT_TIMESTAMP
+WHERE aggregate_snapshot.aggregate_version <= excluded.aggregate_version`
+	_, err := db.Exec(ctx, stmt, snapshot.AggregateID, snapshot.AggregateType, snapshot.AggregateVersion, snapshot.SnapshotVersion, payload, encrypted)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshotEvent wraps a snapshot payload so that it can be passed through the [eventing.EventCrypto].
+type snapshotEvent struct {
+	*eventing.EventBase
+
+	Payload eventing.EncryptedString
+}
+
+func newSnapshotEvent(aggregateID eventing.AggregateID, aggregateType eventing.AggregateType, payload []byte) *snapshotEvent {
+	return &snapshotEvent{
+		EventBase: eventing.NewEventBase(aggregateID, aggregateType, snapshotEventVersion, snapshotEventType),
+		Payload:   eventing.NewEncryptedString(string(payload)),
+	}
+}
+
+func (s *snapshotEvent) IsShredded() bool {
+	return s.Payload.IsShredded
+}
+
+func (s *snapshotEvent) DeclareOwners() []eventing.AggregateID {
+	return []eventing.AggregateID{s.AggregateID()}
+}
+
+func (s *snapshotEvent) AcceptCrypto(transformer eventing.CryptoTransformer) error {
+	return transformer.Transform(s.AggregateID(), "payload", &s.Payload)
+}
@@ -10,14 +10,18 @@ import (
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/postgres"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
-	"github.com/shopspring/decimal"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
 	"sync/atomic"
 )
 
 const (
 	pgCodeLockNotAvailable = "55P03"
+
+	// supervisorType is reported in the status of the projections.
+	supervisorType = "postgres"
 )
 
 var (
@@ -32,6 +36,14 @@ type PostgresProjector interface {
 	ProjectWithTx(ctx context.Context, tx pgx.Tx, events ...*eventing.JournalEvent) error
 }
 
+// DroppableProjector is a projector whose projected data is removed when the projection is reset.
+type DroppableProjector interface {
+	eventing.Projector
+
+	// Drop removes all projected data. It runs within the transaction that rewinds the projection state.
+	Drop(ctx context.Context) error
+}
+
 type projectorSupervisor struct {
 	mu                  sync.RWMutex
 	pool                *pgxpool.Pool
@@ -40,13 +52,17 @@ type projectorSupervisor struct {
 	projectorByInterest map[eventing.EventInterest][]eventing.Projector
 	log                 *slog.Logger
 	started             atomic.Bool
+	counters            eventing.ProjectionCounters
+	positions           eventing.ProjectedPositions
+	failures            eventing.ProjectionFailureStore
 }
 
-func NewProjectorSupervisor(log *slog.Logger, pool *pgxpool.Pool, es eventing.EventStore) eventing.ProjectorSupervisor {
+func NewProjectorSupervisor(log *slog.Logger, pool *pgxpool.Pool, es eventing.EventStore, failures eventing.ProjectionFailureStore) eventing.ProjectorSupervisor {
 	return &projectorSupervisor{
 		log:                 log,
 		pool:                pool,
 		es:                  es,
+		failures:            failures,
 		projectors:          make(map[eventing.ProjectionName]eventing.Projector),
 		projectorByInterest: make(map[eventing.EventInterest][]eventing.Projector),
 	}
@@ -81,10 +97,15 @@ func (ps *projectorSupervisor) Notify(ctx context.Context, interests ...eventing
 	ps.mu.RLock()
 	ps.mu.RUnlock()
 
+	position, positioned := eventing.NotifiedPositionFromContext(ctx)
 	var triggeredProjectors []eventing.Projector
 	for _, interest := range interests {
-		if projectors, ok := ps.projectorByInterest[interest]; ok {
-			triggeredProjectors = append(triggeredProjectors, projectors...)
+		for _, projector := range ps.projectorByInterest[interest] {
+			// An earlier run might have already caught up on the event.
+			if positioned && ps.positions.Covers(projector.Projection(), position) {
+				continue
+			}
+			triggeredProjectors = append(triggeredProjectors, projector)
 		}
 	}
 	if err := ps.trigger(ctx, false, triggeredProjectors...); err != nil {
@@ -125,61 +146,295 @@ func (ps *projectorSupervisor) Trigger(ctx context.Context, projection ...eventi
 		tracing.RecordError(ctx, err)
 	}
 }
+
+func (ps *projectorSupervisor) Advance(ctx context.Context, projection eventing.ProjectionName) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectorSupervisor.Advance")
+	defer span.End()
+
+	ps.mu.RLock()
+	projector, ok := ps.projectors[projection]
+	ps.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+	if state, err := ps.readProjectionState(ctx, projection); err != nil {
+		tracing.RecordError(ctx, err)
+		return err
+	} else if state.Paused {
+		return eventing.ErrProjectionPaused
+	}
+	if err := ps.trigger(ctx, true, projector); err != nil {
+		tracing.RecordError(ctx, err)
+		return err
+	}
+	return nil
+}
+
 func (ps *projectorSupervisor) trigger(ctx context.Context, wait bool, projectors ...eventing.Projector) (rerr error) {
 	for _, projector := range projectors {
 		projection := string(projector.Projection())
 
 		ps.log.Debug("Advancing projector", slog.String("projection", projection))
 
-		err := pgx.BeginFunc(ctx, ps.pool, func(tx pgx.Tx) error {
-			ctx := postgres.WithTx(ctx, tx)
-
-			// Enable by fetching and locking the projection state.
-			state, err := ps.fetchProjectionState(ctx, wait, tx, projection)
-			if errors.Is(err, pgx.ErrNoRows) {
-				// Initialize the projection state if not found.
-				state, err = ps.initProjectionState(ctx, tx, projection)
-				if err != nil {
-					return err
-				}
-			} else if errors.Is(err, errAlreadyLocked) {
-				// The projection is currently being updated from somewhere else, we can skip.
-				return nil
-			} else if err != nil {
-				return err
-			}
-
-			// Fetch the events from the event store.
-			queryWithPos := eventing.NewJournalQueryBuilderFrom(projector.Query()).
-				WithJournalPositionAfter(eventing.JournalPosition(state.GlobalPosition)).MustBuild()
-			events, err := ps.es.Query(ctx, queryWithPos, eventing.WithLimitToOldestRunningTransaction())
+		// Catch up in bounded batches, each one checkpointed in its own transaction.
+		for ctx.Err() == nil {
+			processed, err := ps.advance(ctx, wait, projector)
 			if err != nil {
-				return err
+				ps.counters.AddFailure(projector.Projection())
+				ps.log.
+					With(slog.String("err", err.Error())).
+					With(slog.String("projection", projection)).
+					Error("Failed to advance projector")
+
+				rerr = errors.Join(rerr, err)
+				break
 			}
-
-			txProjector, ok := projector.(PostgresProjector)
-			if !ok {
-				err = projector.Project(ctx, events...)
-			} else {
-				err = txProjector.ProjectWithTx(ctx, tx, events...)
+			ps.counters.AddProcessed(projector.Projection(), processed)
+			if processed < eventing.ProjectionBatchSize {
+				break
 			}
+		}
+	}
+	return
+}
+
+// advance projects the next batch of events and returns the number of processed events.
+func (ps *projectorSupervisor) advance(ctx context.Context, wait bool, projector eventing.Projector) (int, error) {
+	projection := string(projector.Projection())
+
+	var (
+		processed  int
+		projectErr error
+		position   eventing.JournalPosition
+	)
+	err := pgx.BeginFunc(ctx, ps.pool, func(tx pgx.Tx) error {
+		ctx := postgres.WithTx(ctx, tx)
+
+		// Enable by fetching and locking the projection state.
+		state, err := ps.fetchProjectionState(ctx, wait, tx, projection)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Initialize the projection state if not found.
+			state, err = ps.initProjectionState(ctx, tx, projection)
 			if err != nil {
 				return err
 			}
+		} else if errors.Is(err, errAlreadyLocked) {
+			// The projection is currently being updated from somewhere else, we can skip.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if state.Paused {
+			return nil
+		}
 
-			// Advance the projection state.
-			return ps.updateProjectionState(ctx, tx, projection, events)
-		})
+		// A failed event holds back the projection until its next attempt or until it is re-driven.
+		if blocked, err := ps.failures.Blocked(ctx, projector.Projection()); err != nil {
+			return err
+		} else if blocked {
+			return nil
+		}
+
+		// Fetch the next batch of events from the event store.
+		queryWithPos := eventing.NewJournalQueryBuilderFrom(projector.Query()).
+			WithJournalPositionAfter(state.Position).
+			WithLimit(eventing.ProjectionBatchSize).
+			MustBuild()
+		events, err := ps.es.Query(ctx, queryWithPos, eventing.WithLimitToOldestRunningTransaction())
 		if err != nil {
-			ps.log.
-				With(slog.String("err", err.Error())).
-				With(slog.String("projection", projection)).
-				Error("Failed to advance projector")
+			return err
+		}
 
-			rerr = errors.Join(rerr, err)
+		// Checkpoint the handled events even if projecting a later one failed.
+		processed, projectErr = eventing.ProjectWithPolicy(ctx, ps.failures, projector, events, func(ctx context.Context, events ...*eventing.JournalEvent) error {
+			return ps.project(ctx, tx, projector, events...)
+		})
+		position = state.Position
+		if processed > 0 {
+			position = events[processed-1].JournalPosition()
 		}
+		return ps.updateProjectionState(ctx, tx, projection, events[:processed])
+	})
+	if err != nil {
+		return 0, err
 	}
-	return
+	ps.positions.Advance(projector.Projection(), position)
+	return processed, projectErr
+}
+
+// project projects the events within a savepoint, so that a failure only rolls back the changes of these events.
+func (ps *projectorSupervisor) project(ctx context.Context, tx pgx.Tx, projector eventing.Projector, events ...*eventing.JournalEvent) error {
+	return pgx.BeginFunc(ctx, tx, func(tx pgx.Tx) error {
+		ctx := postgres.WithTx(ctx, tx)
+		if txProjector, ok := projector.(PostgresProjector); ok {
+			return txProjector.ProjectWithTx(ctx, tx, events...)
+		}
+		return projector.Project(ctx, events...)
+	})
+}
+
+// Redrive projects the event of a failure of the projection once more and advances the projection afterward.
+func (ps *projectorSupervisor) Redrive(ctx context.Context, projection eventing.ProjectionName, eventID eventing.EventID) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectorSupervisor.Redrive")
+	defer span.End()
+
+	ps.mu.RLock()
+	projector, ok := ps.projectors[projection]
+	ps.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+
+	err := pgx.BeginFunc(ctx, ps.pool, func(tx pgx.Tx) error {
+		ctx := postgres.WithTx(ctx, tx)
+
+		// Wait for a running projection, so that the event is not projected concurrently.
+		if _, err := ps.fetchProjectionState(ctx, true, tx, string(projection)); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		return eventing.RedriveFailure(ctx, ps.es, ps.failures, projector, eventID, func(ctx context.Context, events ...*eventing.JournalEvent) error {
+			return ps.project(ctx, tx, projector, events...)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return ps.trigger(ctx, true, projector)
+}
+
+// Reset rewinds the state of the projection, so that the next advancement replays it from the start of the journal.
+// The projected data of a [DroppableProjector] is dropped along with the state, any other projector has to apply
+// replayed events idempotently.
+func (ps *projectorSupervisor) Reset(ctx context.Context, projection eventing.ProjectionName) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectorSupervisor.Reset")
+	defer span.End()
+
+	ps.mu.RLock()
+	projector, ok := ps.projectors[projection]
+	ps.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+
+	ps.log.Info("Resetting projection", slog.String("projection", string(projection)))
+	err := pgx.BeginFunc(ctx, ps.pool, func(tx pgx.Tx) error {
+		ctx := postgres.WithTx(ctx, tx)
+
+		// Wait for a running projection to finish before its state is removed.
+		if _, err := ps.fetchProjectionState(ctx, true, tx, string(projection)); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if droppable, ok := projector.(DroppableProjector); ok {
+			if err := droppable.Drop(ctx); err != nil {
+				return fmt.Errorf("failed to drop projection: %w", err)
+			}
+		}
+		// A paused projection stays paused and is replayed once it is resumed.
+		_, err := tx.Exec(ctx, "UPDATE projection_state SET last_processed_event_id = NULL, last_processed_timestamp = NULL, aggregate_version = 0, transaction_id = '0', sequence = 0, updated_at = NOW() WHERE projection_name = $1", projection)
+		return err
+	})
+	if err == nil {
+		// Replaying the projection retries all failed events anyway.
+		err = ps.failures.Clear(ctx, projection)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reset projection state: %w", err)
+	}
+	ps.positions.Forget(projection)
+	if err := projector.Init(ctx); err != nil {
+		return fmt.Errorf("failed to init projection: %w", err)
+	}
+	return nil
+}
+
+func (ps *projectorSupervisor) Pause(ctx context.Context, projection eventing.ProjectionName) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectorSupervisor.Pause")
+	defer span.End()
+
+	if _, err := ps.setPaused(ctx, projection, true); err != nil {
+		return err
+	}
+	ps.log.Info("Paused projection", slog.String("projection", string(projection)))
+	return nil
+}
+
+func (ps *projectorSupervisor) Resume(ctx context.Context, projection eventing.ProjectionName) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectorSupervisor.Resume")
+	defer span.End()
+
+	projector, err := ps.setPaused(ctx, projection, false)
+	if err != nil {
+		return err
+	}
+	ps.log.Info("Resumed projection", slog.String("projection", string(projection)))
+	return ps.trigger(ctx, true, projector)
+}
+
+// setPaused updates the paused flag of the projection state, which waits for a running advancement to finish.
+func (ps *projectorSupervisor) setPaused(ctx context.Context, projection eventing.ProjectionName, paused bool) (eventing.Projector, error) {
+	ps.mu.RLock()
+	projector, ok := ps.projectors[projection]
+	ps.mu.RUnlock()
+	if !ok {
+		return nil, eventing.ErrProjectionNotFound
+	}
+
+	_, err := ps.pool.Exec(ctx, "INSERT INTO projection_state (projection_name, paused) VALUES ($1, $2) ON CONFLICT (projection_name) DO UPDATE SET paused = EXCLUDED.paused", projection, paused)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update paused flag of projection: %w", err)
+	}
+	return projector, nil
+}
+
+func (ps *projectorSupervisor) Await(ctx context.Context, projection eventing.ProjectionName, position eventing.JournalPosition) error {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectorSupervisor.Await")
+	defer span.End()
+
+	ps.mu.RLock()
+	projector, ok := ps.projectors[projection]
+	ps.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+	return eventing.AwaitProjection(ctx, ps.es, projector, position, func(ctx context.Context) (eventing.JournalPosition, error) {
+		state, err := ps.readProjectionState(ctx, projection)
+		return state.Position, err
+	})
+}
+
+func (ps *projectorSupervisor) Status(ctx context.Context) ([]eventing.ProjectionStatus, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "pg.ProjectorSupervisor.Status")
+	defer span.End()
+
+	ps.mu.RLock()
+	projectors := slices.Collect(maps.Values(ps.projectors))
+	ps.mu.RUnlock()
+
+	statuses := make([]eventing.ProjectionStatus, 0, len(projectors))
+	for _, projector := range projectors {
+		state, err := ps.readProjectionState(ctx, projector.Projection())
+		if err != nil {
+			return nil, err
+		}
+		status, err := eventing.NewProjectionStatus(ctx, ps.es, projector, state, &ps.counters)
+		if err != nil {
+			return nil, err
+		}
+		status.Supervisor = supervisorType
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// readProjectionState reads the state without locking it, as the projection might be advanced at the same time.
+func (ps *projectorSupervisor) readProjectionState(ctx context.Context, projection eventing.ProjectionName) (eventing.ProjectionState, error) {
+	state := eventing.ProjectionState{Name: projection}
+	err := ps.pool.QueryRow(ctx, readProjectionStateSQL, projection).
+		Scan(&state.Name, &state.LastProcessedEventID, &state.LastProcessedTimestamp, &state.AggregateVersion, &state.Position.TransactionID, &state.Position.Sequence, &state.UpdatedAt, &state.Paused)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return eventing.ProjectionState{Name: projection}, nil
+	}
+	return state, err
 }
 
 func (ps *projectorSupervisor) fetchProjectionState(ctx context.Context, wait bool, tx pgx.Tx, projection string) (*eventing.ProjectionState, error) {
@@ -200,9 +455,7 @@ func (ps *projectorSupervisor) fetchProjectionState(ctx context.Context, wait bo
 	}
 	state, err := pgx.CollectOneRow(rows, func(row pgx.CollectableRow) (eventing.ProjectionState, error) {
 		var state eventing.ProjectionState
-		var position decimal.Decimal
-		err := row.Scan(&state.Name, &state.LastProcessedEventID, &state.LastProcessedTimestamp, &state.AggregateVersion, &position, &state.UpdatedAt)
-		state.GlobalPosition = position
+		err := row.Scan(&state.Name, &state.LastProcessedEventID, &state.LastProcessedTimestamp, &state.AggregateVersion, &state.Position.TransactionID, &state.Position.Sequence, &state.UpdatedAt, &state.Paused)
 		return state, err
 	})
 	if err != nil {
@@ -228,7 +481,7 @@ func (ps *projectorSupervisor) updateProjectionState(ctx context.Context, tx pgx
 	// Find the last event.
 	var lastEvent *eventing.JournalEvent
 	for _, event := range events {
-		if lastEvent == nil || event.JournalPosition().Deref().GreaterThan(lastEvent.JournalPosition().Deref()) {
+		if lastEvent == nil || event.JournalPosition().After(lastEvent.JournalPosition()) {
 			lastEvent = event
 		}
 	}
@@ -239,7 +492,8 @@ func (ps *projectorSupervisor) updateProjectionState(ctx context.Context, tx pgx
 		lastEvent.EventID(),
 		lastEvent.InsertedAt(),
 		lastEvent.AggregateVersion(),
-		lastEvent.JournalPosition().Deref(),
+		lastEvent.JournalPosition().TransactionID,
+		lastEvent.JournalPosition().Sequence,
 		projection,
 	)
 	return err
@@ -255,16 +509,23 @@ func (ps *projectorSupervisor) initProjectionState(ctx context.Context, tx pgx.T
 
 const getProjectionStateSQL = `
 SELECT
-	projection_name, last_processed_event_id, last_processed_timestamp, aggregate_version, global_position, updated_at
+	projection_name, last_processed_event_id, last_processed_timestamp, aggregate_version, transaction_id, sequence, updated_at, paused
 FROM projection_state
 WHERE projection_name = $1
 FOR UPDATE
 `
 
+const readProjectionStateSQL = `
+SELECT
+	projection_name, last_processed_event_id, last_processed_timestamp, aggregate_version, transaction_id, sequence, updated_at, paused
+FROM projection_state
+WHERE projection_name = $1
+`
+
 const updateProjectionStateSQL = `
 UPDATE projection_state 
-SET last_processed_event_id = $1, last_processed_timestamp = $2, aggregate_version = $3, global_position = $4, updated_at = NOW() 
-WHERE projection_name = $5
+SET last_processed_event_id = $1, last_processed_timestamp = $2, aggregate_version = $3, transaction_id = $4, sequence = $5, updated_at = NOW() 
+WHERE projection_name = $6
 `
 
 const updateProjectionStateEmptySQL = `
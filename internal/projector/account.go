@@ -6,14 +6,24 @@ import (
 	"github.com/redis/rueidis"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
 	"time"
 )
 
-const (
-	ProjectionAccountName    eventing.ProjectionName = "accounts"
-	ProjectionAccountIDXName                         = "projectionAccountV1Idx"
-	ProjectionAccountPrefix                          = "projection:accounts:v1:"
+const ProjectionAccountName eventing.ProjectionName = "accounts"
+
+// ProjectionAccountKeyspace holds the accounts projection.
+var ProjectionAccountKeyspace = rdeventing.Keyspace{
+	Projection: ProjectionAccountName,
+	Name:       "accounts",
+	Index:      "projectionAccount",
+	Version:    1,
+}
+
+var (
+	projectionAccountPrefix = ProjectionAccountKeyspace.Prefix(ProjectionAccountKeyspace.Version)
+	projectionAccountIndex  = ProjectionAccountKeyspace.IndexName(ProjectionAccountKeyspace.Version)
 )
 
 type AccountProjection struct {
@@ -51,10 +61,10 @@ func (r *rdAccountProjector) Init(ctx context.Context) error {
 
 	cmd := r.rd.B().
 		FtCreate().
-		Index(ProjectionAccountIDXName).
+		Index(projectionAccountIndex).
 		OnJson().
 		Prefix(1).
-		Prefix(ProjectionAccountPrefix).
+		Prefix(projectionAccountPrefix).
 		Schema().
 		FieldName("$.first_name").As("first_name").Text().Nostem().
 		FieldName("$.last_name").As("last_name").Text().Nostem().
@@ -71,6 +81,10 @@ func (r *rdAccountProjector) Init(ctx context.Context) error {
 }
 
 func (r *rdAccountProjector) Query() eventing.JournalQuery {
+	return accountQuery()
+}
+
+func accountQuery() eventing.JournalQuery {
 	var builder eventing.JournalQueryBuilder
 	return builder.
 		WithAggregate(domain.AccountAggregateType).
@@ -87,6 +101,14 @@ func (r *rdAccountProjector) Projection() eventing.ProjectionName {
 	return ProjectionAccountName
 }
 
+func (r *rdAccountProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (r *rdAccountProjector) Keyspace() rdeventing.Keyspace {
+	return ProjectionAccountKeyspace
+}
+
 func (r *rdAccountProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
 	var err error
 	for _, event := range events {
@@ -110,7 +132,7 @@ func (r *rdAccountProjector) Project(ctx context.Context, events ...*eventing.Jo
 
 func (r *rdAccountProjector) getProjection(ctx context.Context, id domain.AccountID) (*AccountProjection, error) {
 	var p AccountProjection
-	cmd := r.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", ProjectionAccountPrefix, id)).Path(".").Build()
+	cmd := r.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", projectionAccountPrefix, id)).Path(".").Build()
 	return &p, r.rd.Do(ctx, cmd).DecodeJSON(&p)
 }
 
@@ -124,21 +146,21 @@ func (r *rdAccountProjector) insertAccountCreatedEvent(ctx context.Context, even
 		CreatedAt:     event.InsertedAt(),
 		LinkedPersons: AccountLinkedPersonsSet{},
 	}
-	key := fmt.Sprintf("%s%s", ProjectionAccountPrefix, p.ID)
+	key := fmt.Sprintf("%s%s", projectionAccountPrefix, p.ID)
 	return insertJSON(ctx, r.rd, key, &p)
 }
 
 func (r *rdAccountProjector) insertRootAccountCreatedEvent(ctx context.Context, event *eventing.JournalEvent, e *domain.RootAccountCreatedEvent) error {
 	p := AccountProjection{
 		ID:            domain.AccountID(event.AggregateID()),
-		FirstName:     e.FirstName,
-		LastName:      e.LastName,
-		Email:         e.Email,
+		FirstName:     e.FirstName.Value,
+		LastName:      e.LastName.Value,
+		Email:         e.Email.Value,
 		IsRoot:        true,
 		CreatedAt:     event.InsertedAt(),
 		LinkedPersons: AccountLinkedPersonsSet{},
 	}
-	key := fmt.Sprintf("%s%s", ProjectionAccountPrefix, p.ID)
+	key := fmt.Sprintf("%s%s", projectionAccountPrefix, p.ID)
 	return insertJSON(ctx, r.rd, key, &p)
 }
 
@@ -152,7 +174,7 @@ func (r *rdAccountProjector) insertAccountRegisteredEvent(ctx context.Context, e
 		CreatedAt:     event.InsertedAt(),
 		LinkedPersons: AccountLinkedPersonsSet{},
 	}
-	key := fmt.Sprintf("%s%s", ProjectionAccountPrefix, p.ID)
+	key := fmt.Sprintf("%s%s", projectionAccountPrefix, p.ID)
 	return insertJSON(ctx, r.rd, key, &p)
 }
 
@@ -180,6 +202,6 @@ func (r *rdAccountProjector) insertAccountLinkedToPersonEvent(ctx context.Contex
 		LinkedBy:      linkedBy,
 		UsedLinkToken: e.UsedLinkToken,
 	}
-	key := fmt.Sprintf("%s%s", ProjectionAccountPrefix, p.ID)
+	key := fmt.Sprintf("%s%s", projectionAccountPrefix, p.ID)
 	return insertJSON(ctx, r.rd, key, &p)
 }
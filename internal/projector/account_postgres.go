@@ -0,0 +1,112 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+)
+
+// pgAccountProjector projects the same AccountProjection as rdAccountProjector, but into postgres.
+type pgAccountProjector struct{}
+
+func NewPostgresAccountProjector() eventing.Projector {
+	return &pgAccountProjector{}
+}
+
+func (p *pgAccountProjector) Init(ctx context.Context) error {
+	return nil
+}
+
+func (p *pgAccountProjector) Query() eventing.JournalQuery {
+	return accountQuery()
+}
+
+func (p *pgAccountProjector) Projection() eventing.ProjectionName {
+	return ProjectionAccountName
+}
+
+func (p *pgAccountProjector) Drop(ctx context.Context) error {
+	return dropProjection(ctx, pgAccountTable, ProjectionAccountName)
+}
+
+func (p *pgAccountProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (p *pgAccountProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return p.ProjectWithTx(ctx, tx, events...)
+}
+
+func (p *pgAccountProjector) ProjectWithTx(ctx context.Context, tx pgx.Tx, events ...*eventing.JournalEvent) error {
+	ctx, span := tracing.Tracer.Start(ctx, "projector.postgres.Account.Project")
+	defer span.End()
+
+	var err error
+	for _, event := range events {
+		switch e := event.Event.(type) {
+		case *domain.AccountCreatedEvent:
+			err = p.insertAccount(ctx, tx, event, e.FirstName.Value, e.LastName.Value, e.Email.Value, false)
+		case *domain.RootAccountCreatedEvent:
+			err = p.insertAccount(ctx, tx, event, e.FirstName.Value, e.LastName.Value, e.Email.Value, true)
+		case *domain.AccountRegisteredEvent:
+			err = p.insertAccount(ctx, tx, event, e.FirstName.Value, e.LastName.Value, e.Email.Value, false)
+		case *domain.AccountLinkedToPersonEvent:
+			err = p.insertAccountLinkedToPersonEvent(ctx, tx, event, e)
+		}
+		if err != nil {
+			tracing.RecordError(ctx, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pgAccountProjector) insertAccount(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, firstName, lastName, email string, isRoot bool) error {
+	a := AccountProjection{
+		ID:            domain.AccountID(event.AggregateID()),
+		FirstName:     firstName,
+		LastName:      lastName,
+		Email:         email,
+		IsRoot:        isRoot,
+		CreatedAt:     event.InsertedAt(),
+		LinkedPersons: AccountLinkedPersonsSet{},
+	}
+	return putDocument(ctx, tx, pgAccountTable, string(a.ID), &a)
+}
+
+func (p *pgAccountProjector) insertAccountLinkedToPersonEvent(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, e *domain.AccountLinkedToPersonEvent) error {
+	var a AccountProjection
+	if err := getDocument(ctx, tx, pgAccountTable, string(e.AggregateID()), &a); err != nil {
+		return err
+	}
+	var linkedBy *OperatorProjection
+	if e.LinkedBy != nil {
+		var operator AccountProjection
+		if err := getDocument(ctx, tx, pgAccountTable, string(e.LinkedBy.ActorID), &operator); err != nil {
+			return err
+		}
+		linkedBy = &OperatorProjection{
+			ActorID:       e.LinkedBy.ActorID,
+			ActorFullName: fmt.Sprintf("%s %s", operator.FirstName, operator.LastName),
+			OnBehalfOf:    e.LinkedBy.OnBehalfOf,
+		}
+	}
+	if a.LinkedPersons == nil {
+		a.LinkedPersons = AccountLinkedPersonsSet{}
+	}
+	a.LinkedPersons[e.PersonID] = &AccountLinkedPersonProjection{
+		PersonID:      e.PersonID,
+		LinkedAs:      e.LinkedAs,
+		LinkedAt:      event.InsertedAt(),
+		LinkedBy:      linkedBy,
+		UsedLinkToken: e.UsedLinkToken,
+	}
+	return putDocument(ctx, tx, pgAccountTable, string(a.ID), &a)
+}
@@ -0,0 +1,41 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"testing"
+)
+
+func TestAccountReadModel_ProjectsLinkedPersons(t *testing.T) {
+	runReadModelTest(t, func(t *testing.T, project projectFunc, models ReadModels) {
+		f := newReadModelFixture()
+		linkedID := idgen.New[domain.AccountID]()
+		personID := idgen.New[domain.PersonID]()
+		email := fmt.Sprintf("%s@example.com", linkedID)
+
+		project(t, ProjectionAccountName, journalEvents(append(f.events(),
+			domain.NewAccountCreatedEvent(linkedID, "Erika", "Mustermann", email, "hash"),
+			domain.NewAccountLinkedToPersonEvent(linkedID, personID, domain.AccountLinkParent, &f.operator, f.clubID, nil),
+		)...)...)
+
+		account, err := models.Account(context.Background(), linkedID)
+		if err != nil {
+			t.Fatalf("failed to read account: %v", err)
+		}
+		if account.FirstName != "Erika" || account.LastName != "Mustermann" || account.Email != email || account.IsRoot {
+			t.Errorf("unexpected account %+v", account)
+		}
+		link, ok := account.LinkedPersons[personID]
+		if !ok {
+			t.Fatalf("expected person %s to be linked", personID)
+		}
+		if link.LinkedAs != domain.AccountLinkParent {
+			t.Errorf("expected person to be linked as %s, got %s", domain.AccountLinkParent, link.LinkedAs)
+		}
+		if link.LinkedBy == nil || link.LinkedBy.ActorFullName != "Max Mustermann" {
+			t.Errorf("expected person to be linked by Max Mustermann, got %+v", link.LinkedBy)
+		}
+	})
+}
@@ -6,14 +6,24 @@ import (
 	"github.com/redis/rueidis"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
 	"time"
 )
 
-const (
-	ProjectionClubName    eventing.ProjectionName = "clubs"
-	ProjectionClubIDXName                         = "projectionClubV1Idx"
-	ProjectionClubPrefix                          = "projection:clubs:v1:"
+const ProjectionClubName eventing.ProjectionName = "clubs"
+
+// ProjectionClubKeyspace holds the clubs projection.
+var ProjectionClubKeyspace = rdeventing.Keyspace{
+	Projection: ProjectionClubName,
+	Name:       "clubs",
+	Index:      "projectionClub",
+	Version:    1,
+}
+
+var (
+	projectionClubPrefix = ProjectionClubKeyspace.Prefix(ProjectionClubKeyspace.Version)
+	projectionClubIndex  = ProjectionClubKeyspace.IndexName(ProjectionClubKeyspace.Version)
 )
 
 type ClubProjection struct {
@@ -38,10 +48,10 @@ func (r *rdClubProjector) Init(ctx context.Context) error {
 
 	cmd := r.rd.B().
 		FtCreate().
-		Index(ProjectionClubIDXName).
+		Index(projectionClubIndex).
 		OnJson().
 		Prefix(1).
-		Prefix(ProjectionClubPrefix).
+		Prefix(projectionClubPrefix).
 		Schema().
 		FieldName("$.name").As("name").Text().Nostem().
 		FieldName("$.slug").As("slug").Tag().
@@ -57,6 +67,10 @@ func (r *rdClubProjector) Init(ctx context.Context) error {
 }
 
 func (r *rdClubProjector) Query() eventing.JournalQuery {
+	return clubQuery()
+}
+
+func clubQuery() eventing.JournalQuery {
 	var builder eventing.JournalQueryBuilder
 	return builder.
 		WithAggregate(domain.ClubAggregateType).
@@ -68,6 +82,14 @@ func (r *rdClubProjector) Projection() eventing.ProjectionName {
 	return ProjectionClubName
 }
 
+func (r *rdClubProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (r *rdClubProjector) Keyspace() rdeventing.Keyspace {
+	return ProjectionClubKeyspace
+}
+
 func (r *rdClubProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
 	var err error
 	for _, event := range events {
@@ -90,7 +112,7 @@ func (r *rdClubProjector) getProjection(ctx context.Context, id domain.ClubID) (
 }
 
 func (r *rdClubProjector) key(id domain.ClubID) string {
-	return fmt.Sprintf("%s%s", ProjectionClubPrefix, id)
+	return fmt.Sprintf("%s%s", projectionClubPrefix, id)
 }
 
 func (r *rdClubProjector) insertClubCreatedEvent(ctx context.Context, event *eventing.JournalEvent, e *domain.ClubCreatedEvent) error {
@@ -0,0 +1,68 @@
+package projector
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+)
+
+// pgClubProjector projects the same ClubProjection as rdClubProjector, but into postgres.
+type pgClubProjector struct{}
+
+func NewPostgresClubProjector() eventing.Projector {
+	return &pgClubProjector{}
+}
+
+func (p *pgClubProjector) Init(ctx context.Context) error {
+	return nil
+}
+
+func (p *pgClubProjector) Query() eventing.JournalQuery {
+	return clubQuery()
+}
+
+func (p *pgClubProjector) Projection() eventing.ProjectionName {
+	return ProjectionClubName
+}
+
+func (p *pgClubProjector) Drop(ctx context.Context) error {
+	return dropProjection(ctx, pgClubTable, ProjectionClubName)
+}
+
+func (p *pgClubProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (p *pgClubProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return p.ProjectWithTx(ctx, tx, events...)
+}
+
+func (p *pgClubProjector) ProjectWithTx(ctx context.Context, tx pgx.Tx, events ...*eventing.JournalEvent) error {
+	ctx, span := tracing.Tracer.Start(ctx, "projector.postgres.Club.Project")
+	defer span.End()
+
+	var err error
+	for _, event := range events {
+		switch e := event.Event.(type) {
+		case *domain.ClubCreatedEvent:
+			err = putDocument(ctx, tx, pgClubTable, string(e.AggregateID()), &ClubProjection{
+				ID:        domain.ClubID(e.AggregateID()),
+				Name:      e.Name,
+				Slug:      e.Slug,
+				CreatedAt: event.InsertedAt(),
+				UpdatedAt: event.InsertedAt(),
+			})
+		}
+		if err != nil {
+			tracing.RecordError(ctx, err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package projector
+
+import (
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"testing"
+)
+
+func TestClubReadModel_ReturnsExistingClubs(t *testing.T) {
+	runReadModelTest(t, func(t *testing.T, project projectFunc, models ReadModels) {
+		f := newReadModelFixture()
+
+		project(t, ProjectionClubName, journalEvents(f.events()...)...)
+
+		clubs, err := models.Clubs(context.Background(), []domain.ClubID{f.clubID, idgen.New[domain.ClubID]()})
+		if err != nil {
+			t.Fatalf("failed to read clubs: %v", err)
+		}
+		if len(clubs) != 1 {
+			t.Fatalf("expected 1 club, got %d", len(clubs))
+		}
+		if clubs[0].ID != f.clubID || clubs[0].Name != "Club" || clubs[0].Slug != string(f.clubID) {
+			t.Errorf("unexpected club %+v", clubs[0])
+		}
+	})
+}
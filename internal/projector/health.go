@@ -0,0 +1,107 @@
+package projector
+
+import (
+	"encoding/json"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+)
+
+// HealthView is the response of the health handler.
+type HealthView struct {
+	Status      string                  `json:"status"`
+	Notifier    *NotifierHealthView     `json:"notifier,omitempty"`
+	Projections []*ProjectionHealthView `json:"projections"`
+}
+
+type NotifierHealthView struct {
+	Status       string  `json:"status"`
+	State        string  `json:"state"`
+	SinceSeconds float64 `json:"since_seconds"`
+	Reconnects   int     `json:"reconnects"`
+	LastError    string  `json:"last_error,omitempty"`
+}
+
+type ProjectionHealthView struct {
+	Name                string  `json:"name"`
+	Status              string  `json:"status"`
+	Position            string  `json:"position"`
+	LagEvents           int     `json:"lag_events"`
+	LagSeconds          float64 `json:"lag_seconds"`
+	ProcessedEvents     int64   `json:"processed_events"`
+	Failures            int64   `json:"failures"`
+	SinceLastRunSeconds float64 `json:"since_last_run_seconds"`
+}
+
+// NewHealthHandler reports the projections as degraded once they lag behind the journal by more than maxLag.
+// Degraded projections still serve queries, but with stale data, so the handler responds with 503 to make that noticeable.
+// The projections are degraded as well while the notifier is not listening, as they then only advance by polling.
+func NewHealthHandler(log *slog.Logger, supervisors *Supervisors, notifier eventing.EventNotifier, maxLag time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := supervisors.Status(r.Context())
+		if err != nil {
+			log.ErrorContext(r.Context(), "Failed to get projection status", slog.String("err", err.Error()))
+			http.Error(w, "failed to get projection status", http.StatusInternalServerError)
+			return
+		}
+
+		view := newHealthView(statuses, maxLag)
+		view.addNotifier(notifier.Status())
+		w.Header().Set("Content-Type", "application/json")
+		if view.Status != HealthStatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(view); err != nil {
+			log.ErrorContext(r.Context(), "Failed to write health response", slog.String("err", err.Error()))
+		}
+	})
+}
+
+func newHealthView(statuses []eventing.ProjectionStatus, maxLag time.Duration) *HealthView {
+	view := &HealthView{Status: HealthStatusOK, Projections: make([]*ProjectionHealthView, len(statuses))}
+	for i, status := range statuses {
+		projection := &ProjectionHealthView{
+			Name:                string(status.State.Name),
+			Status:              HealthStatusOK,
+			Position:            status.State.Position.String(),
+			LagEvents:           status.LagEvents,
+			LagSeconds:          status.Lag.Seconds(),
+			ProcessedEvents:     status.ProcessedEvents,
+			Failures:            status.Failures,
+			SinceLastRunSeconds: status.SinceLastRun().Seconds(),
+		}
+		if status.Lag > maxLag {
+			projection.Status = HealthStatusDegraded
+			view.Status = HealthStatusDegraded
+		}
+		view.Projections[i] = projection
+	}
+	slices.SortFunc(view.Projections, func(a, b *ProjectionHealthView) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return view
+}
+
+func (v *HealthView) addNotifier(status eventing.NotifierStatus) {
+	v.Notifier = &NotifierHealthView{
+		Status:       HealthStatusOK,
+		State:        string(status.State),
+		SinceSeconds: time.Since(status.Since).Seconds(),
+		Reconnects:   status.Reconnects,
+	}
+	if status.LastError != nil {
+		v.Notifier.LastError = status.LastError.Error()
+	}
+	if status.State != eventing.NotifierStateListening {
+		v.Notifier.Status = HealthStatusDegraded
+		v.Status = HealthStatusDegraded
+	}
+}
@@ -0,0 +1,50 @@
+package projector
+
+import (
+	"errors"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"testing"
+	"time"
+)
+
+func TestNewHealthView(t *testing.T) {
+	statuses := []eventing.ProjectionStatus{
+		{State: eventing.ProjectionState{Name: "trainings"}, LagEvents: 12, Lag: 2 * time.Minute},
+		{State: eventing.ProjectionState{Name: "accounts"}},
+	}
+
+	view := newHealthView(statuses, time.Minute)
+	if view.Status != HealthStatusDegraded {
+		t.Errorf("expected status %s, got %s", HealthStatusDegraded, view.Status)
+	}
+	if len(view.Projections) != 2 {
+		t.Fatalf("expected 2 projections, got %d", len(view.Projections))
+	}
+	if accounts := view.Projections[0]; accounts.Name != "accounts" || accounts.Status != HealthStatusOK {
+		t.Errorf("expected healthy accounts first, got %s: %s", accounts.Name, accounts.Status)
+	}
+	if trainings := view.Projections[1]; trainings.Status != HealthStatusDegraded || trainings.LagEvents != 12 {
+		t.Errorf("expected degraded trainings with 12 pending events, got %s with %d", trainings.Status, trainings.LagEvents)
+	}
+
+	if view := newHealthView(statuses, 5*time.Minute); view.Status != HealthStatusOK {
+		t.Errorf("expected status %s below the threshold, got %s", HealthStatusOK, view.Status)
+	}
+}
+
+func TestHealthViewNotifier(t *testing.T) {
+	view := newHealthView(nil, time.Minute)
+	view.addNotifier(eventing.NotifierStatus{State: eventing.NotifierStateListening, Since: time.Now()})
+	if view.Status != HealthStatusOK || view.Notifier.Status != HealthStatusOK {
+		t.Errorf("expected a listening notifier to be healthy, got %s", view.Notifier.Status)
+	}
+
+	view = newHealthView(nil, time.Minute)
+	view.addNotifier(eventing.NotifierStatus{State: eventing.NotifierStateReconnecting, Since: time.Now(), Reconnects: 2, LastError: errors.New("conn closed")})
+	if view.Status != HealthStatusDegraded || view.Notifier.Status != HealthStatusDegraded {
+		t.Errorf("expected a reconnecting notifier to degrade the health, got %s", view.Notifier.Status)
+	}
+	if view.Notifier.Reconnects != 2 || view.Notifier.LastError != "conn closed" {
+		t.Errorf("unexpected notifier view %+v", view.Notifier)
+	}
+}
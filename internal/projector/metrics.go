@@ -0,0 +1,69 @@
+package projector
+
+import (
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterMetrics publishes the progress of the projections whenever metrics are collected.
+// The lag is reported for the journal as a whole, the processed events and failures only for this instance.
+func (m *Supervisors) RegisterMetrics() (metric.Registration, error) {
+	lagEvents, err := tracing.Meter.Int64ObservableGauge(
+		"projection.lag.events",
+		metric.WithDescription("The number of events that have not been projected yet."),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	lag, err := tracing.Meter.Float64ObservableGauge(
+		"projection.lag.duration",
+		metric.WithDescription("The age of the oldest event that has not been projected yet."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	processed, err := tracing.Meter.Int64ObservableCounter(
+		"projection.events.processed",
+		metric.WithDescription("The number of events projected by this instance."),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	failures, err := tracing.Meter.Int64ObservableCounter(
+		"projection.failures",
+		metric.WithDescription("The number of failed projection runs of this instance."),
+		metric.WithUnit("{failure}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	sinceLastRun, err := tracing.Meter.Float64ObservableGauge(
+		"projection.last_run.age",
+		metric.WithDescription("The time since the last successful projection run."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return tracing.Meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			attrs := metric.WithAttributes(attribute.String("projection", string(status.State.Name)))
+			o.ObserveInt64(lagEvents, int64(status.LagEvents), attrs)
+			o.ObserveFloat64(lag, status.Lag.Seconds(), attrs)
+			o.ObserveInt64(processed, status.ProcessedEvents, attrs)
+			o.ObserveInt64(failures, status.Failures, attrs)
+			o.ObserveFloat64(sinceLastRun, status.SinceLastRun().Seconds(), attrs)
+		}
+		return nil
+	}, lagEvents, lag, processed, failures, sinceLastRun)
+}
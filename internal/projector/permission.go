@@ -2,6 +2,7 @@ package projector
 
 import (
 	"context"
+	"errors"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
@@ -10,6 +11,9 @@ import (
 
 const (
 	PermissionProjectorName eventing.ProjectionName = "permission_projector"
+
+	// PermissionTriggerName is the name of the outbox handler that triggers the permission projection.
+	PermissionTriggerName = "permission_trigger"
 )
 
 type permissionProjector struct {
@@ -26,7 +30,16 @@ func (a *permissionProjector) Init(ctx context.Context) error {
 	return nil
 }
 
+// Drop removes all relations, so that a reset replays them without keeping the ones of deleted objects.
+func (a *permissionProjector) Drop(ctx context.Context) error {
+	return a.relationStore.ClearRelations(ctx)
+}
+
 func (a *permissionProjector) Query() eventing.JournalQuery {
+	return permissionQuery()
+}
+
+func permissionQuery() eventing.JournalQuery {
 	var builder eventing.JournalQueryBuilder
 	return builder.
 		WithAggregate(domain.AccountAggregateType).
@@ -39,7 +52,7 @@ func (a *permissionProjector) Query() eventing.JournalQuery {
 		WithAggregate(domain.TeamMemberAggregateType).
 		Events(domain.PersonInvitedToTeamEventType).Finish().
 		WithAggregate(domain.PersonAggregateType).
-		Events(domain.PersonCreatedEventType).Finish().
+		Events(domain.PersonCreatedEventType, domain.PersonShreddedEventType).Finish().
 		WithAggregate(domain.TeamAggregateType).
 		Events(domain.TeamCreatedEventType, domain.TeamDeletedEventType).Finish().
 		WithAggregate(domain.ClubAggregateType).
@@ -49,6 +62,37 @@ func (a *permissionProjector) Query() eventing.JournalQuery {
 		MustBuild()
 }
 
+// permissionTrigger advances the permission projection once one of its events has been persisted.
+type permissionTrigger struct {
+	supervisor eventing.ProjectorSupervisor
+	interests  eventing.EventInterestSet
+}
+
+// NewPermissionTrigger creates an [eventing.OutboxHandler] that triggers the permission projection of the supervisor.
+func NewPermissionTrigger(supervisor eventing.ProjectorSupervisor) eventing.OutboxHandler {
+	return &permissionTrigger{
+		supervisor: supervisor,
+		interests:  eventing.NewInterestSet(eventing.QueryToInterests(permissionQuery())...),
+	}
+}
+
+func (p *permissionTrigger) Name() string {
+	return PermissionTriggerName
+}
+
+func (p *permissionTrigger) Interests() eventing.EventInterestSet {
+	return p.interests
+}
+
+func (p *permissionTrigger) Handle(ctx context.Context, _ *eventing.JournalEvent) error {
+	err := p.supervisor.Advance(ctx, PermissionProjectorName)
+	if errors.Is(err, eventing.ErrProjectionPaused) {
+		// Resuming the projection catches up on the event.
+		return nil
+	}
+	return err
+}
+
 func (a *permissionProjector) Projection() eventing.ProjectionName {
 	return PermissionProjectorName
 }
@@ -72,6 +116,8 @@ func (a *permissionProjector) Project(ctx context.Context, events ...*eventing.J
 			err = a.createTeamMemberPermissions(ctx, event, e)
 		case *domain.PersonCreatedEvent:
 			err = a.createPersonPermissions(ctx, event, e)
+		case *domain.PersonShreddedEvent:
+			err = a.deletePersonPermissions(ctx, event, e)
 		case *domain.TeamCreatedEvent:
 			err = a.createTeamPermissions(ctx, event, e)
 		case *domain.TeamDeletedEvent:
@@ -165,6 +211,11 @@ func (a *permissionProjector) createPersonPermissions(ctx context.Context, event
 	return a.relationStore.AddRelations(ctx, relations)
 }
 
+func (a *permissionProjector) deletePersonPermissions(ctx context.Context, event *eventing.JournalEvent, e *domain.PersonShreddedEvent) error {
+	// Remove the person from the club, its teams and trainings, as well as all links to accounts.
+	return a.relationStore.RemoveAllRelations(ctx, authz.ResourcePersonName, event.AggregateID().Deref())
+}
+
 func (a *permissionProjector) createTeamPermissions(ctx context.Context, event *eventing.JournalEvent, e *domain.TeamCreatedEvent) error {
 	var builder authz.RelationBuilder
 	b := builder.
@@ -187,13 +238,8 @@ func (a *permissionProjector) createTeamPermissions(ctx context.Context, event *
 }
 
 func (a *permissionProjector) deleteTeamPermissions(ctx context.Context, event *eventing.JournalEvent, e *domain.TeamDeletedEvent) error {
-	var builder authz.RelationBuilder
-	relations := builder.
-		// Relate the club to the team as the owner.
-		Entity(authz.ResourceTeamName, event.AggregateID().Deref()).
-		Subject(authz.ResourceClubName, string(e.OwningClubID)).
-		Relate(authz.RelationOwner).Build()
-	return a.relationStore.RemoveRelations(ctx, relations)
+	// Remove the owning club, the admins, members and editors of the team, as well as the team of its trainings.
+	return a.relationStore.RemoveAllRelations(ctx, authz.ResourceTeamName, event.AggregateID().Deref())
 }
 
 func (a *permissionProjector) createClubPermissions(ctx context.Context, event *eventing.JournalEvent, e *domain.ClubCreatedEvent) error {
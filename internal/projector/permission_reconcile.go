@@ -0,0 +1,225 @@
+package projector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// reconcileBatchSize is the number of relations written or removed per request while repairing.
+const reconcileBatchSize = 100
+
+// reconciledEntityTypes are the entity types of the schema that relations are stored for.
+var reconciledEntityTypes = []string{
+	authz.ResourceSystemName,
+	authz.ResourceAccountName,
+	authz.ResourceTeamRoleName,
+	authz.ResourcePersonName,
+	authz.ResourceClubName,
+	authz.ResourceTeamName,
+	authz.ResourceTrainingName,
+}
+
+// PermissionDiff are the differences between the stored relations and the ones the journal implies.
+type PermissionDiff struct {
+	// Missing are the relations the journal implies that are not stored.
+	Missing []authz.Relation
+
+	// Stale are the stored relations the journal does not imply.
+	Stale []authz.Relation
+}
+
+func (d *PermissionDiff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Stale) == 0
+}
+
+// PermissionReconciler detects and repairs relations that drifted from the permission projection,
+// e.g. because removing them partially failed.
+type PermissionReconciler struct {
+	log         *slog.Logger
+	es          eventing.EventStore
+	supervisors *Supervisors
+	store       authz.RelationStore
+	reader      authz.RelationReader
+}
+
+func NewPermissionReconciler(log *slog.Logger, es eventing.EventStore, supervisors *Supervisors, store authz.RelationStore, reader authz.RelationReader) *PermissionReconciler {
+	return &PermissionReconciler{log: log, es: es, supervisors: supervisors, store: store, reader: reader}
+}
+
+// Reconcile replays the events of the permission projection into the expected relations and compares them with
+// the stored ones. Unless dryRun is set, missing relations are added and stale ones are removed.
+//
+// The permission projection is paused meanwhile, so that the stored relations match the replayed position.
+func (r *PermissionReconciler) Reconcile(ctx context.Context, dryRun bool) (diff *PermissionDiff, err error) {
+	ctx, span := tracing.Tracer.Start(ctx, "projector.PermissionReconciler.Reconcile")
+	defer span.End()
+
+	state, err := r.pause(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Paused {
+		defer func() {
+			// Resume even if the reconciliation has been cancelled, as the projection would be stuck otherwise.
+			if resumeErr := r.supervisors.Resume(context.WithoutCancel(ctx), PermissionProjectorName); resumeErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to resume permission projection: %w", resumeErr))
+			}
+		}()
+	}
+
+	expected, err := r.expectedRelations(ctx, state.Position)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(relationSet)
+	for _, entityType := range reconciledEntityTypes {
+		relations, err := r.reader.ReadRelations(ctx, entityType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read relations of %s: %w", entityType, err)
+		}
+		_ = stored.AddRelations(ctx, relations)
+	}
+
+	diff = diffRelations(expected, stored)
+	r.log.InfoContext(ctx, "Compared permission relations", slog.Int("missing", len(diff.Missing)), slog.Int("stale", len(diff.Stale)), slog.Bool("dry_run", dryRun))
+	if dryRun || diff.Empty() {
+		return diff, nil
+	}
+	return diff, r.repair(ctx, diff)
+}
+
+// pause pauses the permission projection and returns its state, which reports whether it was paused already.
+func (r *PermissionReconciler) pause(ctx context.Context) (eventing.ProjectionState, error) {
+	statuses, err := r.supervisors.Status(ctx)
+	if err != nil {
+		return eventing.ProjectionState{}, err
+	}
+	i := slices.IndexFunc(statuses, func(status eventing.ProjectionStatus) bool {
+		return status.State.Name == PermissionProjectorName
+	})
+	if i < 0 {
+		return eventing.ProjectionState{}, eventing.ErrProjectionNotFound
+	}
+	if statuses[i].State.Paused {
+		return statuses[i].State, nil
+	}
+	if err := r.supervisors.Pause(ctx, PermissionProjectorName); err != nil {
+		return eventing.ProjectionState{}, fmt.Errorf("failed to pause permission projection: %w", err)
+	}
+
+	// The projection might have advanced until it has been paused.
+	statuses, err = r.supervisors.Status(ctx)
+	if err != nil {
+		return eventing.ProjectionState{}, err
+	}
+	state := statuses[slices.IndexFunc(statuses, func(status eventing.ProjectionStatus) bool {
+		return status.State.Name == PermissionProjectorName
+	})].State
+	state.Paused = false
+	return state, nil
+}
+
+// expectedRelations replays the events of the permission projection up to position.
+func (r *PermissionReconciler) expectedRelations(ctx context.Context, position eventing.JournalPosition) (relationSet, error) {
+	expected := make(relationSet)
+	replay := NewPermissionProjector(expected)
+
+	var after eventing.JournalPosition
+	for {
+		query := eventing.NewJournalQueryBuilderFrom(replay.Query()).
+			WithJournalPositionAfter(after).
+			WithLimit(eventing.ProjectionBatchSize).
+			MustBuild()
+		events, err := r.es.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query permission events: %w", err)
+		}
+		i := slices.IndexFunc(events, func(event *eventing.JournalEvent) bool {
+			return event.JournalPosition().After(position)
+		})
+		if i >= 0 {
+			events = events[:i]
+		}
+		if err := replay.Project(ctx, events...); err != nil {
+			return nil, err
+		}
+		if i >= 0 || len(events) < eventing.ProjectionBatchSize {
+			return expected, nil
+		}
+		after = events[len(events)-1].JournalPosition()
+	}
+}
+
+func (r *PermissionReconciler) repair(ctx context.Context, diff *PermissionDiff) error {
+	for batch := range slices.Chunk(diff.Missing, reconcileBatchSize) {
+		if err := r.store.AddRelations(ctx, batch); err != nil {
+			return fmt.Errorf("failed to add missing relations: %w", err)
+		}
+	}
+	for batch := range slices.Chunk(diff.Stale, reconcileBatchSize) {
+		if err := r.store.RemoveRelations(ctx, batch); err != nil {
+			return fmt.Errorf("failed to remove stale relations: %w", err)
+		}
+	}
+	r.log.InfoContext(ctx, "Repaired permission relations", slog.Int("added", len(diff.Missing)), slog.Int("removed", len(diff.Stale)))
+	return nil
+}
+
+// diffRelations returns the relations that are only expected as missing and the ones that are only stored as stale.
+func diffRelations(expected, stored relationSet) *PermissionDiff {
+	diff := &PermissionDiff{}
+	for relation := range expected {
+		if _, ok := stored[relation]; !ok {
+			diff.Missing = append(diff.Missing, relation)
+		}
+	}
+	for relation := range stored {
+		if _, ok := expected[relation]; !ok {
+			diff.Stale = append(diff.Stale, relation)
+		}
+	}
+	compare := func(a, b authz.Relation) int {
+		return strings.Compare(a.String(), b.String())
+	}
+	slices.SortFunc(diff.Missing, compare)
+	slices.SortFunc(diff.Stale, compare)
+	return diff
+}
+
+// relationSet is an in-memory relation store the permission events are replayed into.
+type relationSet map[authz.Relation]struct{}
+
+func (s relationSet) AddRelations(_ context.Context, relations []authz.Relation) error {
+	for _, relation := range relations {
+		s[relation] = struct{}{}
+	}
+	return nil
+}
+
+func (s relationSet) RemoveRelations(_ context.Context, relations []authz.Relation) error {
+	for _, relation := range relations {
+		delete(s, relation)
+	}
+	return nil
+}
+
+func (s relationSet) RemoveAllRelations(_ context.Context, typ, id string) error {
+	for relation := range s {
+		if (relation.EntityType == typ && relation.EntityID == id) || (relation.SubjectType == typ && relation.SubjectID == id) {
+			delete(s, relation)
+		}
+	}
+	return nil
+}
+
+func (s relationSet) ClearRelations(_ context.Context) error {
+	clear(s)
+	return nil
+}
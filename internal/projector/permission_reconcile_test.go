@@ -0,0 +1,278 @@
+package projector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/core"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	memeventing "github.com/rsmidt/soccerbuddy/internal/memory/eventing"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiffRelations(t *testing.T) {
+	owner := authz.Relation{EntityType: authz.ResourceTeamName, EntityID: "team", SubjectType: authz.ResourceClubName, SubjectID: "club", Relation: authz.RelationOwner}
+	editor := authz.Relation{EntityType: authz.ResourceTeamName, EntityID: "team", SubjectType: authz.ResourceTeamRoleName, SubjectID: "coach", Relation: authz.RelationEditor}
+	stale := authz.Relation{EntityType: authz.ResourceTeamName, EntityID: "deleted", SubjectType: authz.ResourceClubName, SubjectID: "club", Relation: authz.RelationOwner}
+
+	expected := relationSet{owner: {}, editor: {}}
+	stored := relationSet{owner: {}, stale: {}}
+
+	diff := diffRelations(expected, stored)
+	if len(diff.Missing) != 1 || diff.Missing[0] != editor {
+		t.Errorf("expected %s to be missing, got %v", editor, diff.Missing)
+	}
+	if len(diff.Stale) != 1 || diff.Stale[0] != stale {
+		t.Errorf("expected %s to be stale, got %v", stale, diff.Stale)
+	}
+	if diff := diffRelations(expected, expected); !diff.Empty() {
+		t.Errorf("expected no differences, got %+v", diff)
+	}
+}
+
+func TestPermissionProjectorDeletesTeamRelations(t *testing.T) {
+	ctx := context.Background()
+	set := make(relationSet)
+	projector := NewPermissionProjector(set)
+
+	personID := domain.PersonID("person")
+	operator := domain.Operator{ActorID: "account", OnBehalfOf: &personID}
+	events := []*eventing.JournalEvent{
+		eventing.NewJournalEvent(domain.NewTeamCreatedEvent("team", "Team", "team", "club", operator, time.Now()), "1", 1, eventing.JournalPosition{TransactionID: 1, Sequence: 1}, time.Now()),
+		eventing.NewJournalEvent(domain.NewTeamCreatedEvent("other", "Other", "other", "club", operator, time.Now()), "2", 1, eventing.JournalPosition{TransactionID: 2, Sequence: 2}, time.Now()),
+	}
+	if err := projector.Project(ctx, events...); err != nil {
+		t.Fatalf("failed to project team creation: %v", err)
+	}
+	created := len(set)
+
+	deleted := eventing.NewJournalEvent(domain.NewTeamDeletedEvent("team", "club", operator), "3", 2, eventing.JournalPosition{TransactionID: 3, Sequence: 3}, time.Now())
+	if err := projector.Project(ctx, deleted); err != nil {
+		t.Fatalf("failed to project team deletion: %v", err)
+	}
+	for relation := range set {
+		if relation.EntityID == "team" || relation.SubjectID == "team" {
+			t.Errorf("expected relation %s of the deleted team to be removed", relation)
+		}
+	}
+	if len(set) != created/2 {
+		t.Errorf("expected the relations of the other team to remain, got %d of %d", len(set), created)
+	}
+}
+
+func TestPermissionReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	es := memeventing.NewEventStore(slog.New(slog.NewTextHandler(io.Discard, nil)), teamRegistry{}, stubCrypto{})
+
+	personID := domain.PersonID("person")
+	operator := domain.Operator{ActorID: "account", OnBehalfOf: &personID}
+	var projected []*eventing.JournalEvent
+	for _, event := range []eventing.Event{
+		domain.NewTeamCreatedEvent("team", "Team", "team", "club", operator, time.Now()),
+		domain.NewTeamCreatedEvent("other", "Other", "other", "club", operator, time.Now()),
+	} {
+		projected = append(projected, appendTeamEvent(t, es, event))
+	}
+	// The deletion has not been projected yet, so it must not be reconciled either.
+	appendTeamEvent(t, es, domain.NewTeamDeletedEvent("other", "club", operator))
+
+	stored := &storedRelations{relationSet: make(relationSet)}
+	if err := NewPermissionProjector(stored).Project(ctx, projected...); err != nil {
+		t.Fatalf("failed to project team creation: %v", err)
+	}
+	var missing authz.Relation
+	for relation := range stored.relationSet {
+		missing = relation
+		break
+	}
+	stale := authz.Relation{EntityType: authz.ResourceTeamName, EntityID: "deleted", SubjectType: authz.ResourceClubName, SubjectID: "club", Relation: authz.RelationOwner}
+	delete(stored.relationSet, missing)
+	stored.relationSet[stale] = struct{}{}
+
+	supervisor := &reconcileSupervisor{state: eventing.ProjectionState{Name: PermissionProjectorName, Position: projected[1].JournalPosition()}}
+	supervisors := &Supervisors{Postgres: supervisor}
+	reconciler := NewPermissionReconciler(slog.New(slog.NewTextHandler(io.Discard, nil)), es, supervisors, stored, stored)
+
+	t.Run("dry run reports the drift", func(t *testing.T) {
+		supervisor.calls = nil
+		diff, err := reconciler.Reconcile(ctx, true)
+		if err != nil {
+			t.Fatalf("failed to reconcile: %v", err)
+		}
+		if len(diff.Missing) != 1 || diff.Missing[0] != missing {
+			t.Errorf("expected %s to be missing, got %v", missing, diff.Missing)
+		}
+		if len(diff.Stale) != 1 || diff.Stale[0] != stale {
+			t.Errorf("expected %s to be stale, got %v", stale, diff.Stale)
+		}
+		if _, ok := stored.relationSet[missing]; ok {
+			t.Errorf("expected the dry run to leave the relations untouched")
+		}
+		supervisor.expectCalls(t, "pause", "resume")
+	})
+
+	t.Run("repairs the drift", func(t *testing.T) {
+		supervisor.calls = nil
+		if _, err := reconciler.Reconcile(ctx, false); err != nil {
+			t.Fatalf("failed to reconcile: %v", err)
+		}
+		supervisor.expectCalls(t, "pause", "resume")
+
+		diff, err := reconciler.Reconcile(ctx, true)
+		if err != nil {
+			t.Fatalf("failed to reconcile: %v", err)
+		}
+		if !diff.Empty() {
+			t.Errorf("expected no differences after the repair, got %+v", diff)
+		}
+	})
+
+	t.Run("resumes the projection on failure", func(t *testing.T) {
+		supervisor.calls = nil
+		stored.err = errors.New("unavailable")
+		t.Cleanup(func() { stored.err = nil })
+
+		if _, err := reconciler.Reconcile(ctx, false); !errors.Is(err, stored.err) {
+			t.Errorf("expected the read failure, got %v", err)
+		}
+		supervisor.expectCalls(t, "pause", "resume")
+		if supervisor.state.Paused {
+			t.Errorf("expected the projection to be resumed")
+		}
+	})
+
+	t.Run("keeps a paused projection paused", func(t *testing.T) {
+		supervisor.calls = nil
+		supervisor.state.Paused = true
+		t.Cleanup(func() { supervisor.state.Paused = false })
+
+		if _, err := reconciler.Reconcile(ctx, true); err != nil {
+			t.Fatalf("failed to reconcile: %v", err)
+		}
+		supervisor.expectCalls(t)
+	})
+}
+
+func appendTeamEvent(t *testing.T, es eventing.EventStore, event eventing.Event) *eventing.JournalEvent {
+	t.Helper()
+	persisted, err := es.Append(context.Background(), core.Must2(eventing.NewAggregateChangeIntent(
+		event.AggregateID(), domain.TeamAggregateType, 0, []eventing.Event{event}, eventing.VersionMatcherAlways,
+	)))
+	if err != nil {
+		t.Fatalf("failed to append %s: %v", event.EventType(), err)
+	}
+	return persisted[0][0]
+}
+
+// storedRelations are the relations the reconciler reads and repairs. Reading them fails with err, if set.
+type storedRelations struct {
+	relationSet
+
+	err error
+}
+
+func (s *storedRelations) ReadRelations(_ context.Context, entityType string) ([]authz.Relation, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var relations []authz.Relation
+	for relation := range s.relationSet {
+		if relation.EntityType == entityType {
+			relations = append(relations, relation)
+		}
+	}
+	return relations, nil
+}
+
+// reconcileSupervisor records the pauses and resumes of the permission projection.
+type reconcileSupervisor struct {
+	eventing.ProjectorSupervisor
+
+	state eventing.ProjectionState
+	calls []string
+}
+
+func (s *reconcileSupervisor) Status(context.Context) ([]eventing.ProjectionStatus, error) {
+	return []eventing.ProjectionStatus{{Supervisor: "test", State: s.state}}, nil
+}
+
+func (s *reconcileSupervisor) Pause(_ context.Context, projection eventing.ProjectionName) error {
+	if projection != s.state.Name {
+		return eventing.ErrProjectionNotFound
+	}
+	s.state.Paused = true
+	s.calls = append(s.calls, "pause")
+	return nil
+}
+
+func (s *reconcileSupervisor) Resume(_ context.Context, projection eventing.ProjectionName) error {
+	if projection != s.state.Name {
+		return eventing.ErrProjectionNotFound
+	}
+	s.state.Paused = false
+	s.calls = append(s.calls, "resume")
+	return nil
+}
+
+func (s *reconcileSupervisor) expectCalls(t *testing.T, calls ...string) {
+	t.Helper()
+	if !slices.Equal(s.calls, calls) {
+		t.Errorf("expected calls %v, got %v", calls, s.calls)
+	}
+}
+
+// teamRegistry maps the team events the reconciler replays.
+type teamRegistry struct{}
+
+func (teamRegistry) MapFrom(
+	aggregateID eventing.AggregateID,
+	aggregateType eventing.AggregateType,
+	eventVersion eventing.EventVersion,
+	eventType eventing.EventType,
+	eventID eventing.EventID,
+	aggregateVersion eventing.AggregateVersion,
+	journalPosition eventing.JournalPosition,
+	insertedAt time.Time,
+	payload []byte,
+) (*eventing.JournalEvent, error) {
+	base := eventing.NewEventBase(aggregateID, aggregateType, eventVersion, eventType)
+
+	var event eventing.Event
+	switch eventType {
+	case domain.TeamCreatedEventType:
+		event = &domain.TeamCreatedEvent{EventBase: base}
+	case domain.TeamDeletedEventType:
+		event = &domain.TeamDeletedEvent{EventBase: base}
+	default:
+		return nil, errors.New("event not registered")
+	}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return eventing.NewJournalEvent(event, eventID, aggregateVersion, journalPosition, insertedAt), nil
+}
+
+type stubCrypto struct{}
+
+func (stubCrypto) EncryptEvents(context.Context, []eventing.Event) error {
+	return nil
+}
+
+func (stubCrypto) DecryptEvents(context.Context, []eventing.Event) error {
+	return nil
+}
+
+func (stubCrypto) ShredKeys(context.Context, ...eventing.AggregateID) error {
+	return nil
+}
+
+func (stubCrypto) HashLookupValue(_, value string) string {
+	return value
+}
@@ -6,15 +6,25 @@ import (
 	"github.com/redis/rueidis"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
 	"slices"
 	"time"
 )
 
-const (
-	ProjectionPersonName    eventing.ProjectionName = "persons"
-	ProjectionPersonIDXName                         = "projectionPersonV1Idx"
-	ProjectionPersonPrefix                          = "projection:persons:v1:"
+const ProjectionPersonName eventing.ProjectionName = "persons"
+
+// ProjectionPersonKeyspace holds the persons projection.
+var ProjectionPersonKeyspace = rdeventing.Keyspace{
+	Projection: ProjectionPersonName,
+	Name:       "persons",
+	Index:      "projectionPerson",
+	Version:    1,
+}
+
+var (
+	projectionPersonPrefix = ProjectionPersonKeyspace.Prefix(ProjectionPersonKeyspace.Version)
+	projectionPersonIndex  = ProjectionPersonKeyspace.IndexName(ProjectionPersonKeyspace.Version)
 )
 
 type OperatorProjection struct {
@@ -38,6 +48,23 @@ type PersonProjection struct {
 	Club           clubProjection             `json:"club"`
 }
 
+// addTeam adds the team the person joined, replacing a previous membership in the same team.
+// Projecting the event again must not add the team twice, see [rdeventing.Write].
+func (p *PersonProjection) addTeam(team *teamProjection) {
+	p.Teams = slices.DeleteFunc(p.Teams, func(t *teamProjection) bool {
+		return t.ID == team.ID
+	})
+	p.Teams = append(p.Teams, team)
+}
+
+// addPendingLink adds the pending link, replacing a previous one with the same token.
+func (p *PersonProjection) addPendingLink(link *PendingLinkProjection) {
+	p.PendingLinks = slices.DeleteFunc(p.PendingLinks, func(l *PendingLinkProjection) bool {
+		return l.Token == link.Token
+	})
+	p.PendingLinks = append(p.PendingLinks, link)
+}
+
 type teamProjection struct {
 	ID           domain.TeamID         `json:"id"`
 	Name         string                `json:"name"`
@@ -83,10 +110,10 @@ func (r *rdPersonProjector) Init(ctx context.Context) error {
 
 	cmd := r.rd.B().
 		FtCreate().
-		Index(ProjectionPersonIDXName).
+		Index(projectionPersonIndex).
 		OnJson().
 		Prefix(1).
-		Prefix(ProjectionPersonPrefix).
+		Prefix(projectionPersonPrefix).
 		Schema().
 		FieldName("$.owning_club_id").As("owning_club_id").Tag().
 		FieldName("$.first_name").As("first_name").Text().Nostem().
@@ -105,10 +132,14 @@ func (r *rdPersonProjector) Init(ctx context.Context) error {
 }
 
 func (r *rdPersonProjector) Query() eventing.JournalQuery {
+	return personQuery()
+}
+
+func personQuery() eventing.JournalQuery {
 	var builder eventing.JournalQueryBuilder
 	return builder.
 		WithAggregate(domain.PersonAggregateType).
-		Events(domain.PersonCreatedEventType, domain.PersonLinkInitiatedEventType, domain.PersonLinkClaimedEventType).Finish().
+		Events(domain.PersonCreatedEventType, domain.PersonLinkInitiatedEventType, domain.PersonLinkClaimedEventType, domain.PersonShreddedEventType).Finish().
 		WithAggregate(domain.AccountAggregateType).
 		Events(
 			domain.AccountCreatedEventType,
@@ -128,6 +159,14 @@ func (r *rdPersonProjector) Projection() eventing.ProjectionName {
 	return ProjectionPersonName
 }
 
+func (r *rdPersonProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (r *rdPersonProjector) Keyspace() rdeventing.Keyspace {
+	return ProjectionPersonKeyspace
+}
+
 func (r *rdPersonProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
 	ctx, span := tracing.Tracer.Start(ctx, "projector.redis.Person.Project")
 	defer span.End()
@@ -141,6 +180,8 @@ func (r *rdPersonProjector) Project(ctx context.Context, events ...*eventing.Jou
 			err = r.insertPendingLink(ctx, event, e)
 		case *domain.PersonLinkClaimedEvent:
 			err = r.handleLinkClaimed(ctx, event, e)
+		case *domain.PersonShreddedEvent:
+			err = r.deletePerson(ctx, event, e)
 		case *domain.AccountCreatedEvent:
 			err = r.handleAccountLookup(ctx, event, e)
 		case *domain.RootAccountCreatedEvent:
@@ -189,10 +230,15 @@ func (r *rdPersonProjector) insertPerson(ctx context.Context, event *eventing.Jo
 			Name: c.Name,
 		},
 	}
-	key := fmt.Sprintf("%s%s", ProjectionPersonPrefix, event.AggregateID())
+	key := fmt.Sprintf("%s%s", projectionPersonPrefix, event.AggregateID())
 	return insertJSON(ctx, r.rd, key, &projection)
 }
 
+func (r *rdPersonProjector) deletePerson(ctx context.Context, event *eventing.JournalEvent, e *domain.PersonShreddedEvent) error {
+	key := fmt.Sprintf("%s%s", projectionPersonPrefix, event.AggregateID())
+	return rdeventing.Write(ctx, r.rd, r.rd.B().Del().Key(key).Build())
+}
+
 func (r *rdPersonProjector) insertTeamMember(ctx context.Context, event *eventing.JournalEvent, e *domain.PersonInvitedToTeamEvent) error {
 	t, err := r.lookupTeam(ctx, e.TeamID)
 	if err != nil {
@@ -202,14 +248,14 @@ func (r *rdPersonProjector) insertTeamMember(ctx context.Context, event *eventin
 	if err != nil {
 		return err
 	}
-	projection.Teams = append(projection.Teams, &teamProjection{
+	projection.addTeam(&teamProjection{
 		ID:           e.TeamID,
 		Name:         t.Name,
 		Role:         e.AssignedRole,
 		JoinedAt:     event.InsertedAt(),
 		OwningClubID: t.OwningClubID,
 	})
-	key := fmt.Sprintf("%s%s", ProjectionPersonPrefix, projection.ID)
+	key := fmt.Sprintf("%s%s", projectionPersonPrefix, projection.ID)
 	return insertJSON(ctx, r.rd, key, projection)
 }
 
@@ -222,7 +268,7 @@ func (r *rdPersonProjector) insertPendingLink(ctx context.Context, event *eventi
 	if err != nil {
 		return err
 	}
-	projection.PendingLinks = append(projection.PendingLinks, &PendingLinkProjection{
+	projection.addPendingLink(&PendingLinkProjection{
 		Token:     e.Token,
 		LinkAs:    e.LinkAs,
 		ExpiresAt: e.ExpiresAt,
@@ -233,14 +279,14 @@ func (r *rdPersonProjector) insertPendingLink(ctx context.Context, event *eventi
 		},
 		InvitedAt: event.InsertedAt(),
 	})
-	key := fmt.Sprintf("%s%s", ProjectionPersonPrefix, projection.ID)
+	key := fmt.Sprintf("%s%s", projectionPersonPrefix, projection.ID)
 	return insertJSON(ctx, r.rd, key, projection)
 
 }
 
 func (r *rdPersonProjector) getProjection(ctx context.Context, id domain.PersonID) (*PersonProjection, error) {
 	var p PersonProjection
-	cmd := r.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", ProjectionPersonPrefix, id)).Path(".").Build()
+	cmd := r.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", projectionPersonPrefix, id)).Path(".").Build()
 	return &p, r.rd.Do(ctx, cmd).DecodeJSON(&p)
 }
 
@@ -273,10 +319,19 @@ func (r *rdPersonProjector) handleLinkClaimed(ctx context.Context, event *eventi
 		InvitedBy: &pl.InvitedBy,
 		InvitedAt: &pl.InvitedAt,
 	})
-	key := fmt.Sprintf("%s%s", ProjectionPersonPrefix, projection.ID)
+	key := fmt.Sprintf("%s%s", projectionPersonPrefix, projection.ID)
 	return insertJSON(ctx, r.rd, key, projection)
 }
 
+// personFullName returns the full name of the created person, or the redacted string if the person has been shredded.
+// Projectors redact the name of a shredded person the same way, so that a replay yields the same projection.
+func personFullName(e *domain.PersonCreatedEvent) string {
+	if e.IsShredded() {
+		return domain.RedactedString
+	}
+	return fmt.Sprintf("%s %s", e.FirstName.Value, e.LastName.Value)
+}
+
 func maybeParseTime(timeStr string) time.Time {
 	if timeStr == "" {
 		return time.Time{}
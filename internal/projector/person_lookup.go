@@ -7,10 +7,11 @@ import (
 	"github.com/redis/rueidis"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
 )
 
-const (
-	projectionPersonLookupPrefix = "projection_lookup:persons:v1:"
+var (
+	projectionPersonLookupPrefix = ProjectionPersonKeyspace.LookupPrefix(ProjectionPersonKeyspace.Version)
 
 	projectionPersonAccountLookupPrefix = projectionPersonLookupPrefix + "accounts:"
 	projectionPersonTeamLookupPrefix    = projectionPersonLookupPrefix + "teams:"
@@ -43,7 +44,7 @@ func (r *rdPersonProjector) handleAccountLookup(ctx context.Context, event *even
 func (r *rdPersonProjector) handleRootAccountLookup(ctx context.Context, event *eventing.JournalEvent, e *domain.RootAccountCreatedEvent) error {
 	return r.insertAccountLookup(ctx, &personAccountLookup{
 		ID:       domain.AccountID(event.AggregateID()),
-		FullName: fmt.Sprintf("%s %s", e.FirstName, e.LastName),
+		FullName: fmt.Sprintf("%s %s", e.FirstName.Value, e.LastName.Value),
 	})
 }
 
@@ -102,6 +103,5 @@ func insertJSON(ctx context.Context, client rueidis.Client, key string, value an
 		return err
 	}
 	cmd := client.B().JsonSet().Key(key).Path(".").Value(string(val)).Build()
-	res := client.Do(ctx, cmd)
-	return res.Error()
+	return rdeventing.Write(ctx, client, cmd)
 }
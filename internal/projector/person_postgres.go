@@ -0,0 +1,211 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"slices"
+)
+
+const (
+	pgPersonAccountLookup = "accounts"
+	pgPersonTeamLookup    = "teams"
+	pgPersonClubLookup    = "clubs"
+)
+
+// pgPersonProjector projects the same PersonProjection as rdPersonProjector, but into postgres.
+type pgPersonProjector struct{}
+
+func NewPostgresPersonProjector() eventing.Projector {
+	return &pgPersonProjector{}
+}
+
+func (p *pgPersonProjector) Init(ctx context.Context) error {
+	return nil
+}
+
+func (p *pgPersonProjector) Query() eventing.JournalQuery {
+	return personQuery()
+}
+
+func (p *pgPersonProjector) Projection() eventing.ProjectionName {
+	return ProjectionPersonName
+}
+
+func (p *pgPersonProjector) Drop(ctx context.Context) error {
+	return dropProjection(ctx, pgPersonTable, ProjectionPersonName)
+}
+
+func (p *pgPersonProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (p *pgPersonProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return p.ProjectWithTx(ctx, tx, events...)
+}
+
+func (p *pgPersonProjector) ProjectWithTx(ctx context.Context, tx pgx.Tx, events ...*eventing.JournalEvent) error {
+	ctx, span := tracing.Tracer.Start(ctx, "projector.postgres.Person.Project")
+	defer span.End()
+
+	var err error
+	for _, event := range events {
+		switch e := event.Event.(type) {
+		case *domain.PersonCreatedEvent:
+			err = p.insertPerson(ctx, tx, event, e)
+		case *domain.PersonLinkInitiatedEvent:
+			err = p.insertPendingLink(ctx, tx, event, e)
+		case *domain.PersonLinkClaimedEvent:
+			err = p.handleLinkClaimed(ctx, tx, event, e)
+		case *domain.PersonShreddedEvent:
+			err = deleteDocument(ctx, tx, pgPersonTable, string(event.AggregateID()))
+		case *domain.AccountCreatedEvent:
+			err = p.insertAccountLookup(ctx, tx, event, e.FirstName.Value, e.LastName.Value)
+		case *domain.RootAccountCreatedEvent:
+			err = p.insertAccountLookup(ctx, tx, event, e.FirstName.Value, e.LastName.Value)
+		case *domain.AccountRegisteredEvent:
+			err = p.insertAccountLookup(ctx, tx, event, e.FirstName.Value, e.LastName.Value)
+		case *domain.TeamCreatedEvent:
+			err = putLookup(ctx, tx, ProjectionPersonName, pgPersonTeamLookup, string(event.AggregateID()), &personTeamLookup{
+				ID:           domain.TeamID(event.AggregateID()),
+				Name:         e.Name,
+				OwningClubID: e.OwningClubID,
+			})
+		case *domain.ClubCreatedEvent:
+			err = putLookup(ctx, tx, ProjectionPersonName, pgPersonClubLookup, string(event.AggregateID()), &personClubLookup{
+				ID:   domain.ClubID(event.AggregateID()),
+				Name: e.Name,
+			})
+		case *domain.PersonInvitedToTeamEvent:
+			err = p.insertTeamMember(ctx, tx, event, e)
+		}
+		if err != nil {
+			tracing.RecordError(ctx, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pgPersonProjector) insertAccountLookup(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, firstName, lastName string) error {
+	return putLookup(ctx, tx, ProjectionPersonName, pgPersonAccountLookup, string(event.AggregateID()), &personAccountLookup{
+		ID:       domain.AccountID(event.AggregateID()),
+		FullName: fmt.Sprintf("%s %s", firstName, lastName),
+	})
+}
+
+func (p *pgPersonProjector) lookupAccount(ctx context.Context, tx pgx.Tx, id domain.AccountID) (*personAccountLookup, error) {
+	var lookup personAccountLookup
+	return &lookup, getLookup(ctx, tx, ProjectionPersonName, pgPersonAccountLookup, string(id), &lookup)
+}
+
+func (p *pgPersonProjector) getProjection(ctx context.Context, tx pgx.Tx, id domain.PersonID) (*PersonProjection, error) {
+	var projection PersonProjection
+	return &projection, getDocument(ctx, tx, pgPersonTable, string(id), &projection)
+}
+
+func (p *pgPersonProjector) insertPerson(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, e *domain.PersonCreatedEvent) error {
+	acc, err := p.lookupAccount(ctx, tx, e.Creator.ActorID)
+	if err != nil {
+		return err
+	}
+	var c personClubLookup
+	if err := getLookup(ctx, tx, ProjectionPersonName, pgPersonClubLookup, string(e.OwningClubID), &c); err != nil {
+		return err
+	}
+
+	projection := PersonProjection{
+		ID:           domain.PersonID(e.AggregateID()),
+		FirstName:    e.FirstName.Value,
+		LastName:     e.LastName.Value,
+		BirthDate:    maybeParseTime(e.Birthdate.Value),
+		OwningClubID: e.OwningClubID,
+		CreatedBy: OperatorProjection{
+			ActorID:       e.Creator.ActorID,
+			ActorFullName: acc.FullName,
+			OnBehalfOf:    e.Creator.OnBehalfOf,
+		},
+		CreatedAt: event.InsertedAt(),
+		Club: clubProjection{
+			ID:   c.ID,
+			Name: c.Name,
+		},
+	}
+	return putDocument(ctx, tx, pgPersonTable, string(projection.ID), &projection)
+}
+
+func (p *pgPersonProjector) insertTeamMember(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, e *domain.PersonInvitedToTeamEvent) error {
+	var t personTeamLookup
+	if err := getLookup(ctx, tx, ProjectionPersonName, pgPersonTeamLookup, string(e.TeamID), &t); err != nil {
+		return err
+	}
+	projection, err := p.getProjection(ctx, tx, e.PersonID)
+	if err != nil {
+		return err
+	}
+	projection.addTeam(&teamProjection{
+		ID:           e.TeamID,
+		Name:         t.Name,
+		Role:         e.AssignedRole,
+		JoinedAt:     event.InsertedAt(),
+		OwningClubID: t.OwningClubID,
+	})
+	return putDocument(ctx, tx, pgPersonTable, string(projection.ID), projection)
+}
+
+func (p *pgPersonProjector) insertPendingLink(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, e *domain.PersonLinkInitiatedEvent) error {
+	projection, err := p.getProjection(ctx, tx, domain.PersonID(e.AggregateID()))
+	if err != nil {
+		return err
+	}
+	inviter, err := p.lookupAccount(ctx, tx, e.InvitedBy.ActorID)
+	if err != nil {
+		return err
+	}
+	projection.addPendingLink(&PendingLinkProjection{
+		Token:     e.Token,
+		LinkAs:    e.LinkAs,
+		ExpiresAt: e.ExpiresAt,
+		InvitedBy: OperatorProjection{
+			ActorID:       e.InvitedBy.ActorID,
+			ActorFullName: inviter.FullName,
+			OnBehalfOf:    e.InvitedBy.OnBehalfOf,
+		},
+		InvitedAt: event.InsertedAt(),
+	})
+	return putDocument(ctx, tx, pgPersonTable, string(projection.ID), projection)
+}
+
+func (p *pgPersonProjector) handleLinkClaimed(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, e *domain.PersonLinkClaimedEvent) error {
+	projection, err := p.getProjection(ctx, tx, domain.PersonID(e.AggregateID()))
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(projection.PendingLinks, func(link *PendingLinkProjection) bool {
+		return link.Token == e.UsedToken
+	})
+	if i < 0 {
+		return fmt.Errorf("no pending link found for token %s", e.UsedToken)
+	}
+	pl := projection.PendingLinks[i]
+	projection.PendingLinks = slices.Delete(projection.PendingLinks, i, i+1)
+	linkedAccount, err := p.lookupAccount(ctx, tx, e.AccountID)
+	if err != nil {
+		return err
+	}
+	projection.LinkedAccounts = append(projection.LinkedAccounts, &LinkedAccountProjection{
+		LinkedAs:  e.LinkedAs,
+		LinkedAt:  event.InsertedAt(),
+		FullName:  linkedAccount.FullName,
+		InvitedBy: &pl.InvitedBy,
+		InvitedAt: &pl.InvitedAt,
+	})
+	return putDocument(ctx, tx, pgPersonTable, string(projection.ID), projection)
+}
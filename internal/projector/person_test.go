@@ -0,0 +1,111 @@
+package projector
+
+import (
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestPersonReadModel_ProjectsClubMembers(t *testing.T) {
+	runReadModelTest(t, func(t *testing.T, project projectFunc, models ReadModels) {
+		ctx := context.Background()
+		f := newReadModelFixture()
+		teamID := idgen.New[domain.TeamID]()
+		memberID := idgen.New[domain.PersonID]()
+		otherID := idgen.New[domain.PersonID]()
+		shreddedID := idgen.New[domain.PersonID]()
+		token := idgen.New[domain.PersonLinkToken]()
+
+		project(t, ProjectionPersonName, journalEvents(append(f.events(),
+			domain.NewTeamCreatedEvent(teamID, "Team", string(teamID), f.clubID, f.operator, time.Now()),
+			domain.NewPersonCreatedEvent(memberID, "Erika", "Mustermann", time.Now(), f.operator, f.clubID),
+			domain.NewPersonCreatedEvent(otherID, "Erika", "Musterfrau", time.Now(), f.operator, f.clubID),
+			domain.NewPersonCreatedEvent(shreddedID, "Erika", "Beispiel", time.Now(), f.operator, f.clubID),
+			domain.NewPersonInvitedToTeamEvent(idgen.New[domain.TeamMemberID](), memberID, teamID, f.operator, domain.TeamMemberRolePlayer),
+			domain.NewPersonLinkInitiatedEvent(otherID, f.operator, domain.AccountLinkParent, token, time.Now().Add(time.Hour)),
+			domain.NewPersonShreddedEvent(shreddedID, f.operator, f.clubID),
+		)...)...)
+
+		member, err := models.Person(ctx, memberID)
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if member.FirstName != "Erika" || member.LastName != "Mustermann" || member.Club.Name != "Club" || member.CreatedBy.ActorFullName != "Max Mustermann" {
+			t.Errorf("unexpected person %+v", member)
+		}
+		if len(member.Teams) != 1 || member.Teams[0].ID != teamID || member.Teams[0].Name != "Team" || member.Teams[0].Role != domain.TeamMemberRolePlayer {
+			t.Errorf("expected person to be a player of %s, got %+v", teamID, member.Teams)
+		}
+
+		inClub, err := models.PersonsInClub(ctx, f.clubID)
+		if err != nil {
+			t.Fatalf("failed to read persons in club: %v", err)
+		}
+		expectPersonIDs(t, inClub, memberID, otherID)
+
+		existing, err := models.Persons(ctx, []domain.PersonID{memberID, shreddedID})
+		if err != nil {
+			t.Fatalf("failed to read persons: %v", err)
+		}
+		expectPersonIDs(t, existing, memberID)
+
+		pending, err := models.PersonsByPendingLinkToken(ctx, token)
+		if err != nil {
+			t.Fatalf("failed to read persons by pending link token: %v", err)
+		}
+		expectPersonIDs(t, pending, otherID)
+
+		notInTeam, err := models.SearchPersonsNotInTeam(ctx, f.clubID, teamID, "Erika")
+		if err != nil {
+			t.Fatalf("failed to search persons: %v", err)
+		}
+		expectPersonIDs(t, notInTeam, otherID)
+	})
+}
+
+func expectPersonIDs(t *testing.T, persons []*PersonProjection, expected ...domain.PersonID) {
+	t.Helper()
+	if len(persons) != len(expected) {
+		t.Fatalf("expected %d persons, got %d", len(expected), len(persons))
+	}
+	ids := make(map[domain.PersonID]struct{}, len(persons))
+	for _, person := range persons {
+		ids[person.ID] = struct{}{}
+	}
+	for _, id := range expected {
+		if _, ok := ids[id]; !ok {
+			t.Errorf("expected person %s to be returned", id)
+		}
+	}
+}
+
+func TestPersonReadModel_ProjectsEventsIdempotently(t *testing.T) {
+	runReadModelTest(t, func(t *testing.T, project projectFunc, models ReadModels) {
+		f := newReadModelFixture()
+		teamID := idgen.New[domain.TeamID]()
+		personID := idgen.New[domain.PersonID]()
+
+		events := journalEvents(append(f.events(),
+			domain.NewTeamCreatedEvent(teamID, "Team", string(teamID), f.clubID, f.operator, time.Now()),
+			domain.NewPersonCreatedEvent(personID, "Erika", "Mustermann", time.Now(), f.operator, f.clubID),
+			domain.NewPersonInvitedToTeamEvent(idgen.New[domain.TeamMemberID](), personID, teamID, f.operator, domain.TeamMemberRolePlayer),
+			domain.NewPersonLinkInitiatedEvent(personID, f.operator, domain.AccountLinkParent, idgen.New[domain.PersonLinkToken](), time.Now().Add(time.Hour)),
+		)...)
+		project(t, ProjectionPersonName, events...)
+		// Events are projected again if one of their writes failed, so the read-modify-write ones must not add twice.
+		project(t, ProjectionPersonName, events[len(events)-2:]...)
+
+		person, err := models.Person(context.Background(), personID)
+		if err != nil {
+			t.Fatalf("failed to read person: %v", err)
+		}
+		if len(person.Teams) != 1 {
+			t.Errorf("expected 1 team, got %d", len(person.Teams))
+		}
+		if len(person.PendingLinks) != 1 {
+			t.Errorf("expected 1 pending link, got %d", len(person.PendingLinks))
+		}
+	})
+}
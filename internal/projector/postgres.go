@@ -0,0 +1,106 @@
+package projector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+)
+
+// The tables of the read models projected to postgres, see migration 007.
+const (
+	pgAccountTable  = "projection_account"
+	pgClubTable     = "projection_club"
+	pgPersonTable   = "projection_person"
+	pgTeamTable     = "projection_team"
+	pgTrainingTable = "projection_training"
+)
+
+var errNoTx = errors.New("postgres projectors must be projected within a transaction")
+
+// NewPostgresReadModelProjectors creates the projectors of the read models that are projected to postgres.
+// They have to be registered at the postgres supervisor, which projects them within its transactions.
+func NewPostgresReadModelProjectors() []eventing.Projector {
+	return []eventing.Projector{
+		NewPostgresPersonProjector(),
+		NewPostgresAccountProjector(),
+		NewPostgresTeamProjector(),
+		NewPostgresTrainingProjector(),
+		NewPostgresClubProjector(),
+	}
+}
+
+// txFromContext returns the transaction the supervisor projects in.
+func txFromContext(ctx context.Context) (pgx.Tx, error) {
+	tx, ok := ctx.Value(postgres.TransactionKey).(pgx.Tx)
+	if !ok {
+		return nil, errNoTx
+	}
+	return tx, nil
+}
+
+func getDocument(ctx context.Context, db postgres.DB, table string, id string, v any) error {
+	err := db.QueryRow(ctx, fmt.Sprintf("SELECT document FROM %s WHERE id = $1", table), id).Scan(v)
+	if err != nil {
+		return fmt.Errorf("failed to get document %s of %s: %w", id, table, err)
+	}
+	return nil
+}
+
+func putDocument(ctx context.Context, db postgres.DB, table string, id string, v any) error {
+	sql := fmt.Sprintf("INSERT INTO %s (id, document) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET document = excluded.document", table)
+	_, err := db.Exec(ctx, sql, id, v)
+	return err
+}
+
+func deleteDocument(ctx context.Context, db postgres.DB, table string, id string) error {
+	_, err := db.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", table), id)
+	return err
+}
+
+// dropProjection removes all documents of the table and the lookups of the projection.
+// It runs within the transaction in which the supervisor resets the projection.
+func dropProjection(ctx context.Context, table string, projection eventing.ProjectionName) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf("TRUNCATE %s", table)); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", table, err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM projection_lookup WHERE projection_name = $1", projection); err != nil {
+		return fmt.Errorf("failed to delete lookups of %s: %w", projection, err)
+	}
+	return nil
+}
+
+func collectDocuments[T any](rows pgx.Rows, err error) ([]*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*T, error) {
+		var t T
+		return &t, row.Scan(&t)
+	})
+}
+
+func getLookup(ctx context.Context, db postgres.DB, projection eventing.ProjectionName, kind string, id string, v any) error {
+	err := db.QueryRow(ctx, "SELECT document FROM projection_lookup WHERE projection_name = $1 AND kind = $2 AND id = $3", projection, kind, id).Scan(v)
+	if err != nil {
+		return fmt.Errorf("failed to get %s lookup %s of %s: %w", kind, id, projection, err)
+	}
+	return nil
+}
+
+func putLookup(ctx context.Context, db postgres.DB, projection eventing.ProjectionName, kind string, id string, v any) error {
+	_, err := db.Exec(ctx, upsertLookupSQL, projection, kind, id, v)
+	return err
+}
+
+const upsertLookupSQL = `
+INSERT INTO projection_lookup (projection_name, kind, id, document)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (projection_name, kind, id) DO UPDATE SET document = excluded.document
+`
@@ -0,0 +1,16 @@
+package projector
+
+import (
+	pgeventing "github.com/rsmidt/soccerbuddy/internal/postgres/eventing"
+	"testing"
+)
+
+func TestPostgresProjectorsAreDroppable(t *testing.T) {
+	// Resetting a projection that is not dropped would replay its events on top of the stale documents.
+	projectors := append(NewPostgresReadModelProjectors(), NewPermissionProjector(make(relationSet)))
+	for _, projector := range projectors {
+		if _, ok := projector.(pgeventing.DroppableProjector); !ok {
+			t.Errorf("expected %s to drop its data on reset", projector.Projection())
+		}
+	}
+}
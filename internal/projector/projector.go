@@ -2,52 +2,170 @@ package projector
 
 import (
 	"context"
-	"github.com/redis/rueidis"
+	"errors"
 	"github.com/rsmidt/soccerbuddy/internal/domain/authz"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"time"
 )
 
+// readModelFailurePolicy dead-letters events the read models keep failing on.
+// A single stale document is preferred over a read model that stops advancing altogether.
+var readModelFailurePolicy = eventing.FailurePolicy{
+	Action:      eventing.FailureActionDeadLetter,
+	MaxAttempts: 5,
+	MinBackoff:  time.Second,
+	MaxBackoff:  time.Minute,
+}
+
+// Supervisors routes calls to the supervisor a projection is registered at.
+// Redis is nil if the read models are projected to postgres as well.
 type Supervisors struct {
 	Postgres eventing.ProjectorSupervisor
 	Redis    eventing.ProjectorSupervisor
+	Failures eventing.ProjectionFailureStore
+
+	// registered maps the projections to the supervisor they are registered at.
+	registered map[eventing.ProjectionName]eventing.ProjectorSupervisor
 }
 
-func (m *Supervisors) Register(ctx context.Context, relationStore authz.RelationStore, rd rueidis.Client) error {
+// Register registers the permission projector at the postgres supervisor and the read model projectors at the
+// redis supervisor, or at the postgres supervisor if redis is not used.
+func (m *Supervisors) Register(ctx context.Context, relationStore authz.RelationStore, readModels []eventing.Projector) error {
 	permProjector := NewPermissionProjector(relationStore)
 	if err := permProjector.Init(ctx); err != nil {
 		return err
 	}
-	personProjector := NewPersonProjector(rd)
-	if err := personProjector.Init(ctx); err != nil {
-		return err
-	}
-	accountProjector := NewAccountProjector(rd)
-	if err := accountProjector.Init(ctx); err != nil {
-		return err
+	for _, projector := range readModels {
+		if err := projector.Init(ctx); err != nil {
+			return err
+		}
 	}
-	teamProjector := NewTeamProjector(rd)
-	if err := teamProjector.Init(ctx); err != nil {
-		return err
+
+	m.registered = make(map[eventing.ProjectionName]eventing.ProjectorSupervisor)
+	m.Postgres.Register(permProjector)
+	m.registered[permProjector.Projection()] = m.Postgres
+	readModelSupervisor := m.Postgres
+	if m.Redis != nil {
+		readModelSupervisor = m.Redis
 	}
-	trainingProjector := NewTrainingProjector(rd)
-	if err := trainingProjector.Init(ctx); err != nil {
-		return err
+	for _, projector := range readModels {
+		readModelSupervisor.Register(projector)
+		m.registered[projector.Projection()] = readModelSupervisor
 	}
-	clubProjector := NewClubProjector(rd)
-	if err := clubProjector.Init(ctx); err != nil {
-		return err
+	return nil
+}
+
+// all returns the supervisors in the order projections are looked up in.
+func (m *Supervisors) all() []eventing.ProjectorSupervisor {
+	if m.Redis == nil {
+		return []eventing.ProjectorSupervisor{m.Postgres}
 	}
+	return []eventing.ProjectorSupervisor{m.Redis, m.Postgres}
+}
 
-	m.Postgres.Register(permProjector)
-	m.Redis.Register(personProjector)
-	m.Redis.Register(accountProjector)
-	m.Redis.Register(teamProjector)
-	m.Redis.Register(trainingProjector)
-	m.Redis.Register(clubProjector)
-	return nil
+// route calls fn with the supervisors until one of them has the projection registered.
+func (m *Supervisors) route(fn func(supervisor eventing.ProjectorSupervisor) error) error {
+	var err error
+	for _, supervisor := range m.all() {
+		if err = fn(supervisor); !errors.Is(err, eventing.ErrProjectionNotFound) {
+			return err
+		}
+	}
+	return err
 }
 
 func (m *Supervisors) Enable() {
-	m.Postgres.Enable()
-	m.Redis.Enable()
+	for _, supervisor := range m.all() {
+		supervisor.Enable()
+	}
+}
+
+// Trigger advances the projections of all supervisors, see [eventing.ProjectorSupervisor.Trigger].
+func (m *Supervisors) Trigger(ctx context.Context) {
+	for _, supervisor := range m.all() {
+		supervisor.Trigger(ctx)
+	}
+}
+
+// Reset resets the projection at the supervisor it is registered at. The projection is replayed by its next advancement.
+func (m *Supervisors) Reset(ctx context.Context, projection eventing.ProjectionName) error {
+	return m.route(func(supervisor eventing.ProjectorSupervisor) error {
+		return supervisor.Reset(ctx, projection)
+	})
+}
+
+// TriggerProjection advances the projection at the supervisor it is registered at and waits for it to finish,
+// see [eventing.ProjectorSupervisor.Advance].
+func (m *Supervisors) TriggerProjection(ctx context.Context, projection eventing.ProjectionName) error {
+	supervisor, ok := m.registered[projection]
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+	return supervisor.Advance(ctx, projection)
+}
+
+// Pause pauses the projection at the supervisor it is registered at.
+func (m *Supervisors) Pause(ctx context.Context, projection eventing.ProjectionName) error {
+	return m.route(func(supervisor eventing.ProjectorSupervisor) error {
+		return supervisor.Pause(ctx, projection)
+	})
+}
+
+// Resume resumes the projection at the supervisor it is registered at.
+func (m *Supervisors) Resume(ctx context.Context, projection eventing.ProjectionName) error {
+	return m.route(func(supervisor eventing.ProjectorSupervisor) error {
+		return supervisor.Resume(ctx, projection)
+	})
+}
+
+// Redrive re-drives the failed event at the supervisor the projection is registered at.
+func (m *Supervisors) Redrive(ctx context.Context, projection eventing.ProjectionName, eventID eventing.EventID) error {
+	return m.route(func(supervisor eventing.ProjectorSupervisor) error {
+		return supervisor.Redrive(ctx, projection, eventID)
+	})
+}
+
+// Await waits for the projection at the supervisor it is registered at.
+func (m *Supervisors) Await(ctx context.Context, projection eventing.ProjectionName, position eventing.JournalPosition) error {
+	return m.route(func(supervisor eventing.ProjectorSupervisor) error {
+		return supervisor.Await(ctx, projection, position)
+	})
+}
+
+// Status reports the progress of the projections of all supervisors.
+func (m *Supervisors) Status(ctx context.Context) ([]eventing.ProjectionStatus, error) {
+	var statuses []eventing.ProjectionStatus
+	for _, supervisor := range m.all() {
+		status, err := supervisor.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status...)
+	}
+	return statuses, nil
+}
+
+// Interests returns the events the given read model projections are built from.
+// Unknown projections are ignored.
+func Interests(projections ...eventing.ProjectionName) []eventing.EventInterest {
+	var interests []eventing.EventInterest
+	for _, projection := range projections {
+		var query eventing.JournalQuery
+		switch projection {
+		case ProjectionAccountName:
+			query = accountQuery()
+		case ProjectionClubName:
+			query = clubQuery()
+		case ProjectionPersonName:
+			query = personQuery()
+		case ProjectionTeamName:
+			query = teamQuery()
+		case ProjectionTrainingName:
+			query = trainingQuery()
+		default:
+			continue
+		}
+		interests = append(interests, eventing.QueryToInterests(query)...)
+	}
+	return interests
 }
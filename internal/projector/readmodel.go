@@ -0,0 +1,208 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/rueidis"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/redis"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ReadModels reads the projections of the read models, independent of the store they are projected to.
+// Reads don't wait for the projections to catch up, callers have to await them if necessary.
+type ReadModels interface {
+	Account(ctx context.Context, id domain.AccountID) (*AccountProjection, error)
+
+	Person(ctx context.Context, id domain.PersonID) (*PersonProjection, error)
+	// Persons returns the persons of the IDs that exist.
+	Persons(ctx context.Context, ids []domain.PersonID) ([]*PersonProjection, error)
+	PersonsByPendingLinkToken(ctx context.Context, token domain.PersonLinkToken) ([]*PersonProjection, error)
+	PersonsInClub(ctx context.Context, clubID domain.ClubID) ([]*PersonProjection, error)
+	// SearchPersonsNotInTeam returns the persons of the club whose first or last name matches the search term.
+	SearchPersonsNotInTeam(ctx context.Context, clubID domain.ClubID, teamID domain.TeamID, term string) ([]*PersonProjection, error)
+
+	Team(ctx context.Context, id domain.TeamID) (*TeamProjection, error)
+	// Teams returns the teams of the club among the IDs.
+	Teams(ctx context.Context, clubID domain.ClubID, ids []domain.TeamID) ([]*TeamProjection, error)
+	TeamMembers(ctx context.Context, teamID domain.TeamID) ([]*TeamMemberProjection, error)
+
+	// Trainings returns the trainings of the team that are scheduled after minTime, ordered by their schedule.
+	Trainings(ctx context.Context, teamID domain.TeamID, minTime time.Time) ([]*TrainingProjection, error)
+	// NominatedTrainings is like Trainings, but only returns the trainings the person is nominated for.
+	NominatedTrainings(ctx context.Context, teamID domain.TeamID, personID domain.PersonID, minTime time.Time) ([]*TrainingProjection, error)
+
+	// Clubs returns the clubs of the IDs that exist.
+	Clubs(ctx context.Context, ids []domain.ClubID) ([]*ClubProjection, error)
+}
+
+var _ ReadModels = (*rdReadModels)(nil)
+
+type rdReadModels struct {
+	rd rueidis.Client
+}
+
+// NewRedisReadModels reads the live versions of the read models projected to Redis.
+func NewRedisReadModels(rd rueidis.Client) ReadModels {
+	return &rdReadModels{rd: rd}
+}
+
+func (r *rdReadModels) Account(ctx context.Context, id domain.AccountID) (*AccountProjection, error) {
+	prefix, err := ProjectionAccountKeyspace.LivePrefix(ctx, r.rd)
+	if err != nil {
+		return nil, err
+	}
+	var a AccountProjection
+	cmd := r.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", prefix, id)).Path(".").Build()
+	return &a, r.rd.Do(ctx, cmd).DecodeJSON(&a)
+}
+
+func (r *rdReadModels) Person(ctx context.Context, id domain.PersonID) (*PersonProjection, error) {
+	prefix, err := ProjectionPersonKeyspace.LivePrefix(ctx, r.rd)
+	if err != nil {
+		return nil, err
+	}
+	var p PersonProjection
+	cmd := r.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", prefix, id)).Path(".").Build()
+	if err := r.rd.Do(ctx, cmd).DecodeJSON(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *rdReadModels) Persons(ctx context.Context, ids []domain.PersonID) ([]*PersonProjection, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	prefix, err := ProjectionPersonKeyspace.LivePrefix(ctx, r.rd)
+	if err != nil {
+		return nil, err
+	}
+	var p []*PersonProjection
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("%s%s", prefix, id)
+	}
+	cmd := r.rd.B().JsonMget().Key(keys...).Path(".").Build()
+	if err := rueidis.DecodeSliceOfJSON(r.rd.Do(ctx, cmd), &p); err != nil {
+		return nil, err
+	}
+	return removeNils(p), nil
+}
+
+func (r *rdReadModels) PersonsByPendingLinkToken(ctx context.Context, token domain.PersonLinkToken) ([]*PersonProjection, error) {
+	return r.searchPersons(ctx, fmt.Sprintf("@pending_link_token:{%s}", token))
+}
+
+func (r *rdReadModels) PersonsInClub(ctx context.Context, clubID domain.ClubID) ([]*PersonProjection, error) {
+	return r.searchPersons(ctx, fmt.Sprintf("@owning_club_id:{%s}", clubID))
+}
+
+func (r *rdReadModels) SearchPersonsNotInTeam(ctx context.Context, clubID domain.ClubID, teamID domain.TeamID, term string) ([]*PersonProjection, error) {
+	return r.searchPersons(ctx, fmt.Sprintf("-@team_id:{%s} @owning_club_id:{%s} (@first_name:%%%%%s%%%% | @last_name: %%%%%s%%%%)", teamID, clubID, term, term))
+}
+
+func (r *rdReadModels) searchPersons(ctx context.Context, query string) ([]*PersonProjection, error) {
+	cmd := r.rd.B().FtSearch().Index(ProjectionPersonKeyspace.Alias()).Query(query).Dialect(4).Build()
+	_, docs, err := r.rd.Do(ctx, cmd).AsFtSearch()
+	if err != nil {
+		return nil, err
+	}
+	return redis.UnmarshalDocs[PersonProjection](docs)
+}
+
+func (r *rdReadModels) Team(ctx context.Context, id domain.TeamID) (*TeamProjection, error) {
+	prefix, err := ProjectionTeamKeyspace.LivePrefix(ctx, r.rd)
+	if err != nil {
+		return nil, err
+	}
+	var t TeamProjection
+	cmd := r.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", prefix, id)).Path(".").Build()
+	return &t, r.rd.Do(ctx, cmd).DecodeJSON(&t)
+}
+
+func (r *rdReadModels) Teams(ctx context.Context, clubID domain.ClubID, ids []domain.TeamID) ([]*TeamProjection, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	idFilter := make([]string, len(ids))
+	for i, id := range ids {
+		idFilter[i] = string(id)
+	}
+	cmd := r.rd.B().FtSearch().Index(ProjectionTeamKeyspace.Alias()).
+		Query(fmt.Sprintf("@id:{%s} @owning_club_id:{%s}", strings.Join(idFilter, "|"), clubID)).
+		Dialect(4).
+		Build()
+	_, docs, err := r.rd.Do(ctx, cmd).AsFtSearch()
+	if err != nil {
+		return nil, err
+	}
+	return redis.UnmarshalDocs[TeamProjection](docs)
+}
+
+func (r *rdReadModels) TeamMembers(ctx context.Context, teamID domain.TeamID) ([]*TeamMemberProjection, error) {
+	prefix, err := ProjectionTeamKeyspace.LivePrefix(ctx, r.rd)
+	if err != nil {
+		return nil, err
+	}
+	var members []*TeamMemberProjection
+	cmd := r.rd.B().JsonGet().Key(fmt.Sprintf("%s%s", prefix, teamID)).Path("$.members.*").Build()
+	return members, r.rd.Do(ctx, cmd).DecodeJSON(&members)
+}
+
+func (r *rdReadModels) Trainings(ctx context.Context, teamID domain.TeamID, minTime time.Time) ([]*TrainingProjection, error) {
+	return r.searchTrainings(ctx, fmt.Sprintf("@owning_team_id:{%s} @scheduled_at_ts:[%d +inf]", teamID, minTime.Unix()))
+}
+
+func (r *rdReadModels) NominatedTrainings(ctx context.Context, teamID domain.TeamID, personID domain.PersonID, minTime time.Time) ([]*TrainingProjection, error) {
+	return r.searchTrainings(ctx, fmt.Sprintf("@owning_team_id:{%s} @scheduled_at_ts:[%d +inf] @nominated_person_ids:{%s}", teamID, minTime.Unix(), personID))
+}
+
+func (r *rdReadModels) searchTrainings(ctx context.Context, query string) ([]*TrainingProjection, error) {
+	cmd := r.rd.B().FtSearch().Index(ProjectionTrainingKeyspace.Alias()).Query(query).Sortby("scheduled_at_ts").Asc().Dialect(4).Build()
+	_, docs, err := r.rd.Do(ctx, cmd).AsFtSearch()
+	if err != nil {
+		return nil, err
+	}
+	return redis.UnmarshalDocs[TrainingProjection](docs)
+}
+
+func (r *rdReadModels) Clubs(ctx context.Context, ids []domain.ClubID) ([]*ClubProjection, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	prefix, err := ProjectionClubKeyspace.LivePrefix(ctx, r.rd)
+	if err != nil {
+		return nil, err
+	}
+	var p []*ClubProjection
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("%s%s", prefix, id)
+	}
+	cmd := r.rd.B().JsonMget().Key(keys...).Path(".").Build()
+	if err := rueidis.DecodeSliceOfJSON(r.rd.Do(ctx, cmd), &p); err != nil {
+		return nil, err
+	}
+	return removeNils(p), nil
+}
+
+// NewRedisReadModelProjectors creates the projectors of the read models that are projected to Redis.
+func NewRedisReadModelProjectors(rd rueidis.Client) []eventing.Projector {
+	return []eventing.Projector{
+		NewPersonProjector(rd),
+		NewAccountProjector(rd),
+		NewTeamProjector(rd),
+		NewTrainingProjector(rd),
+		NewClubProjector(rd),
+	}
+}
+
+func removeNils[T any](s []*T) []*T {
+	return slices.DeleteFunc(s, func(t *T) bool {
+		return t == nil
+	})
+}
@@ -0,0 +1,119 @@
+package projector
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"strings"
+	"time"
+)
+
+var _ ReadModels = (*pgReadModels)(nil)
+
+type pgReadModels struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresReadModels reads the read models projected to postgres.
+func NewPostgresReadModels(pool *pgxpool.Pool) ReadModels {
+	return &pgReadModels{pool: pool}
+}
+
+func (r *pgReadModels) Account(ctx context.Context, id domain.AccountID) (*AccountProjection, error) {
+	var a AccountProjection
+	return &a, getDocument(ctx, r.pool, pgAccountTable, string(id), &a)
+}
+
+func (r *pgReadModels) Person(ctx context.Context, id domain.PersonID) (*PersonProjection, error) {
+	var p PersonProjection
+	if err := getDocument(ctx, r.pool, pgPersonTable, string(id), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *pgReadModels) Persons(ctx context.Context, ids []domain.PersonID) ([]*PersonProjection, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return collectDocuments[PersonProjection](r.pool.Query(ctx, selectDocumentsByIDSQL(pgPersonTable), toStrings(ids)))
+}
+
+func (r *pgReadModels) PersonsByPendingLinkToken(ctx context.Context, token domain.PersonLinkToken) ([]*PersonProjection, error) {
+	return collectDocuments[PersonProjection](r.pool.Query(ctx, `
+SELECT document FROM projection_person
+WHERE document @> jsonb_build_object('pending_links', jsonb_build_array(jsonb_build_object('token', $1::TEXT)))
+`, token))
+}
+
+func (r *pgReadModels) PersonsInClub(ctx context.Context, clubID domain.ClubID) ([]*PersonProjection, error) {
+	return collectDocuments[PersonProjection](r.pool.Query(ctx, "SELECT document FROM projection_person WHERE owning_club_id = $1", clubID))
+}
+
+func (r *pgReadModels) SearchPersonsNotInTeam(ctx context.Context, clubID domain.ClubID, teamID domain.TeamID, term string) ([]*PersonProjection, error) {
+	pattern := "%" + escapeLike(term) + "%"
+	return collectDocuments[PersonProjection](r.pool.Query(ctx, `
+SELECT document FROM projection_person
+WHERE owning_club_id = $1
+AND NOT document @> jsonb_build_object('teams', jsonb_build_array(jsonb_build_object('id', $2::TEXT)))
+AND (document ->> 'first_name' ILIKE $3 OR document ->> 'last_name' ILIKE $3)
+`, clubID, teamID, pattern))
+}
+
+func (r *pgReadModels) Team(ctx context.Context, id domain.TeamID) (*TeamProjection, error) {
+	var t TeamProjection
+	return &t, getDocument(ctx, r.pool, pgTeamTable, string(id), &t)
+}
+
+func (r *pgReadModels) Teams(ctx context.Context, clubID domain.ClubID, ids []domain.TeamID) ([]*TeamProjection, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return collectDocuments[TeamProjection](r.pool.Query(ctx, "SELECT document FROM projection_team WHERE owning_club_id = $1 AND id = ANY($2)", clubID, toStrings(ids)))
+}
+
+func (r *pgReadModels) TeamMembers(ctx context.Context, teamID domain.TeamID) ([]*TeamMemberProjection, error) {
+	return collectDocuments[TeamMemberProjection](r.pool.Query(ctx, "SELECT m.value FROM projection_team, jsonb_each(document -> 'members') m WHERE id = $1", teamID))
+}
+
+func (r *pgReadModels) Trainings(ctx context.Context, teamID domain.TeamID, minTime time.Time) ([]*TrainingProjection, error) {
+	return collectDocuments[TrainingProjection](r.pool.Query(ctx, `
+SELECT document FROM projection_training
+WHERE owning_team_id = $1 AND scheduled_at_ts >= $2
+ORDER BY scheduled_at_ts
+`, teamID, minTime.Unix()))
+}
+
+func (r *pgReadModels) NominatedTrainings(ctx context.Context, teamID domain.TeamID, personID domain.PersonID, minTime time.Time) ([]*TrainingProjection, error) {
+	return collectDocuments[TrainingProjection](r.pool.Query(ctx, `
+SELECT document FROM projection_training
+WHERE owning_team_id = $1 AND scheduled_at_ts >= $2
+AND document @> jsonb_build_object('nominated_person_ids', jsonb_build_array($3::TEXT))
+ORDER BY scheduled_at_ts
+`, teamID, minTime.Unix(), personID))
+}
+
+func (r *pgReadModels) Clubs(ctx context.Context, ids []domain.ClubID) ([]*ClubProjection, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return collectDocuments[ClubProjection](r.pool.Query(ctx, selectDocumentsByIDSQL(pgClubTable), toStrings(ids)))
+}
+
+// selectDocumentsByIDSQL selects the documents of the table in the order of the IDs.
+func selectDocumentsByIDSQL(table string) string {
+	return "SELECT document FROM " + table + " WHERE id = ANY($1) ORDER BY array_position($1, id)"
+}
+
+func toStrings[T ~string](s []T) []string {
+	res := make([]string, len(s))
+	for i, v := range s {
+		res[i] = string(v)
+	}
+	return res
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
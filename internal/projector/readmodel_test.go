@@ -0,0 +1,124 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/postgres"
+	"github.com/rsmidt/soccerbuddy/internal/redis"
+	"testing"
+	"time"
+)
+
+// readModelBackend projects events into one of the stores the read models support.
+// The same fixtures run against every backend, so that the read models behave the same regardless of the store.
+type readModelBackend struct {
+	name  string
+	setup func(t *testing.T) (project projectFunc, models ReadModels)
+}
+
+// projectFunc projects the events with the read model projector of the projection.
+type projectFunc func(t *testing.T, projection eventing.ProjectionName, events ...*eventing.JournalEvent)
+
+var readModelBackends = []readModelBackend{
+	{name: "postgres", setup: setupPostgresReadModels},
+	{name: "redis", setup: setupRedisReadModels},
+}
+
+// runReadModelTest runs the test against all read model backends.
+func runReadModelTest(t *testing.T, test func(t *testing.T, project projectFunc, models ReadModels)) {
+	for _, backend := range readModelBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			t.Parallel()
+			project, models := backend.setup(t)
+			test(t, project, models)
+		})
+	}
+}
+
+func setupPostgresReadModels(t *testing.T) (projectFunc, ReadModels) {
+	pool, cleanup := postgres.GetTestPool()
+	t.Cleanup(cleanup)
+
+	projectors := projectorsByName(NewPostgresReadModelProjectors())
+	project := func(t *testing.T, projection eventing.ProjectionName, events ...*eventing.JournalEvent) {
+		t.Helper()
+		ctx := context.Background()
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			t.Fatalf("failed to begin transaction: %v", err)
+		}
+		defer tx.Rollback(ctx)
+		if err := projectors[projection].Project(postgres.WithTx(ctx, tx), events...); err != nil {
+			t.Fatalf("failed to project %s: %v", projection, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			t.Fatalf("failed to commit projection: %v", err)
+		}
+	}
+	return project, NewPostgresReadModels(pool)
+}
+
+// setupRedisReadModels projects into the shared redis instance. The fixtures use unique IDs, so tests don't interfere.
+func setupRedisReadModels(t *testing.T) (projectFunc, ReadModels) {
+	rd, cleanup := redis.GetTestClient()
+	t.Cleanup(cleanup)
+
+	projectors := projectorsByName(NewRedisReadModelProjectors(rd))
+	for _, projector := range projectors {
+		if err := projector.Init(context.Background()); err != nil {
+			t.Fatalf("failed to init %s: %v", projector.Projection(), err)
+		}
+	}
+	project := func(t *testing.T, projection eventing.ProjectionName, events ...*eventing.JournalEvent) {
+		t.Helper()
+		if err := projectors[projection].Project(context.Background(), events...); err != nil {
+			t.Fatalf("failed to project %s: %v", projection, err)
+		}
+	}
+	return project, NewRedisReadModels(rd)
+}
+
+func projectorsByName(projectors []eventing.Projector) map[eventing.ProjectionName]eventing.Projector {
+	byName := make(map[eventing.ProjectionName]eventing.Projector, len(projectors))
+	for _, projector := range projectors {
+		byName[projector.Projection()] = projector
+	}
+	return byName
+}
+
+// journalEvents wraps the events as if they had been appended to the journal in order.
+func journalEvents(events ...eventing.Event) []*eventing.JournalEvent {
+	journaled := make([]*eventing.JournalEvent, len(events))
+	for i, event := range events {
+		position := eventing.JournalPosition{TransactionID: uint64(i + 1), Sequence: uint64(i + 1)}
+		journaled[i] = eventing.NewJournalEvent(event, idgen.New[eventing.EventID](), 1, position, time.Now())
+	}
+	return journaled
+}
+
+// readModelFixture is an account operating in a club, which most read models look up when projecting other events.
+type readModelFixture struct {
+	accountID domain.AccountID
+	clubID    domain.ClubID
+	operator  domain.Operator
+}
+
+func newReadModelFixture() readModelFixture {
+	accountID := idgen.New[domain.AccountID]()
+	return readModelFixture{
+		accountID: accountID,
+		clubID:    idgen.New[domain.ClubID](),
+		operator:  domain.Operator{ActorID: accountID},
+	}
+}
+
+// events creates the account and the club.
+func (f readModelFixture) events() []eventing.Event {
+	return []eventing.Event{
+		domain.NewAccountCreatedEvent(f.accountID, "Max", "Mustermann", fmt.Sprintf("%s@example.com", f.accountID), "hash"),
+		domain.NewClubCreatedEvent(f.clubID, "Club", string(f.clubID), time.Now()),
+	}
+}
@@ -7,14 +7,24 @@ import (
 	"github.com/redis/rueidis"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
 	"time"
 )
 
-const (
-	ProjectionTeamName    eventing.ProjectionName = "teams"
-	ProjectionTeamIDXName                         = "projectionTeamV1Idx"
-	ProjectionTeamPrefix                          = "projection:teams:v1:"
+const ProjectionTeamName eventing.ProjectionName = "teams"
+
+// ProjectionTeamKeyspace holds the teams projection.
+var ProjectionTeamKeyspace = rdeventing.Keyspace{
+	Projection: ProjectionTeamName,
+	Name:       "teams",
+	Index:      "projectionTeam",
+	Version:    2,
+}
+
+var (
+	projectionTeamPrefix = ProjectionTeamKeyspace.Prefix(ProjectionTeamKeyspace.Version)
+	projectionTeamIndex  = ProjectionTeamKeyspace.IndexName(ProjectionTeamKeyspace.Version)
 )
 
 type TeamProjection struct {
@@ -58,15 +68,16 @@ func (r *rdTeamProjector) Init(ctx context.Context) error {
 
 	cmd := r.rd.B().
 		FtCreate().
-		Index(ProjectionTeamIDXName).
+		Index(projectionTeamIndex).
 		OnJson().
 		Prefix(1).
-		Prefix(ProjectionTeamPrefix).
+		Prefix(projectionTeamPrefix).
 		Schema().
 		FieldName("$.id").As("id").Tag().
 		FieldName("$.name").As("name").Text().Nostem().
 		FieldName("$.slug").As("slug").Tag().
 		FieldName("$.owning_club_id").As("owning_club_id").Tag().
+		FieldName("$.members.*.person_id").As("member_person_ids").Tag().
 		Build()
 	if err := r.rd.Do(ctx, cmd).Error(); err != nil {
 		rderr, ok := rueidis.IsRedisErr(err)
@@ -79,6 +90,10 @@ func (r *rdTeamProjector) Init(ctx context.Context) error {
 }
 
 func (r *rdTeamProjector) Query() eventing.JournalQuery {
+	return teamQuery()
+}
+
+func teamQuery() eventing.JournalQuery {
 	var builder eventing.JournalQueryBuilder
 	return builder.
 		WithAggregate(domain.TeamAggregateType).
@@ -86,7 +101,7 @@ func (r *rdTeamProjector) Query() eventing.JournalQuery {
 		WithAggregate(domain.AccountAggregateType).
 		Events(domain.AccountCreatedEventType, domain.RootAccountCreatedEventType, domain.AccountRegisteredEventType).Finish().
 		WithAggregate(domain.PersonAggregateType).
-		Events(domain.PersonCreatedEventType).Finish().
+		Events(domain.PersonCreatedEventType, domain.PersonShreddedEventType).Finish().
 		WithAggregate(domain.TeamMemberAggregateType).
 		Events(domain.PersonInvitedToTeamEventType).Finish().
 		MustBuild()
@@ -96,6 +111,14 @@ func (r *rdTeamProjector) Projection() eventing.ProjectionName {
 	return ProjectionTeamName
 }
 
+func (r *rdTeamProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (r *rdTeamProjector) Keyspace() rdeventing.Keyspace {
+	return ProjectionTeamKeyspace
+}
+
 func (r *rdTeamProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
 	var err error
 	for _, event := range events {
@@ -110,6 +133,8 @@ func (r *rdTeamProjector) Project(ctx context.Context, events ...*eventing.Journ
 			err = r.handleRootAccountLookup(ctx, event, e)
 		case *domain.PersonCreatedEvent:
 			err = r.handlePersonLookup(ctx, event, e)
+		case *domain.PersonShreddedEvent:
+			err = r.redactPerson(ctx, event, e)
 		case *domain.PersonInvitedToTeamEvent:
 			err = r.insertPersonInvitedToTeamEvent(ctx, event, e)
 		case *domain.AccountRegisteredEvent:
@@ -145,11 +170,11 @@ func (r *rdTeamProjector) insertTeamCreatedEvent(ctx context.Context, event *eve
 func (r *rdTeamProjector) insertTeamDeletedEvent(ctx context.Context, event *eventing.JournalEvent, e *domain.TeamDeletedEvent) error {
 	key := r.key(domain.TeamID(e.AggregateID()))
 	cmd := r.rd.B().Del().Key(key).Build()
-	return r.rd.Do(ctx, cmd).Error()
+	return rdeventing.Write(ctx, r.rd, cmd)
 }
 
 func (r *rdTeamProjector) key(id domain.TeamID) string {
-	return fmt.Sprintf("%s%s", ProjectionTeamPrefix, id)
+	return fmt.Sprintf("%s%s", projectionTeamPrefix, id)
 }
 
 func (r *rdTeamProjector) insertPersonInvitedToTeamEvent(ctx context.Context, event *eventing.JournalEvent, e *domain.PersonInvitedToTeamEvent) error {
@@ -172,6 +197,44 @@ func (r *rdTeamProjector) insertPersonInvitedToTeamEvent(ctx context.Context, ev
 
 	// Only update the member property.
 	cmd := r.rd.B().JsonSet().Key(r.key(e.TeamID)).Path(fmt.Sprintf(".members.%s", person.PersonID)).Value(string(val)).Build()
-	res := r.rd.Do(ctx, cmd)
-	return res.Error()
+	return rdeventing.Write(ctx, r.rd, cmd)
+}
+
+// redactPerson redacts the name of a shredded person in all teams the person is a member of.
+// The result matches a replay, in which the person lookup is already created with the redacted name.
+func (r *rdTeamProjector) redactPerson(ctx context.Context, event *eventing.JournalEvent, e *domain.PersonShreddedEvent) error {
+	personID := domain.PersonID(event.AggregateID())
+	if err := insertJSON(ctx, r.rd, r.personLookupKey(personID), &teamPersonLookup{
+		ID:       personID,
+		FullName: domain.RedactedString,
+	}); err != nil {
+		return err
+	}
+	name, err := json.Marshal(domain.RedactedString)
+	if err != nil {
+		return err
+	}
+
+	const pageSize = 100
+	for offset := int64(0); ; offset += pageSize {
+		cmd := r.rd.B().FtSearch().Index(projectionTeamIndex).
+			Query(fmt.Sprintf("@member_person_ids:{%s}", personID)).
+			Nocontent().
+			Limit().OffsetNum(offset, pageSize).
+			Dialect(4).
+			Build()
+		_, docs, err := r.rd.Do(ctx, cmd).AsFtSearch()
+		if err != nil {
+			return fmt.Errorf("failed to search teams of person: %w", err)
+		}
+		for _, doc := range docs {
+			cmd := r.rd.B().JsonSet().Key(doc.Key).Path(fmt.Sprintf(".members.%s.name", personID)).Value(string(name)).Build()
+			if err := rdeventing.Write(ctx, r.rd, cmd); err != nil {
+				return err
+			}
+		}
+		if len(docs) < pageSize {
+			return nil
+		}
+	}
 }
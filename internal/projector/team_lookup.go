@@ -7,8 +7,8 @@ import (
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 )
 
-const (
-	projectionTeamLookupPrefix = "projection_lookup:teams:v1:"
+var (
+	projectionTeamLookupPrefix = ProjectionTeamKeyspace.LookupPrefix(ProjectionTeamKeyspace.Version)
 
 	projectionTeamAccountLookupPrefix = projectionTeamLookupPrefix + "accounts:"
 	projectionTeamPersonLookupPrefix  = projectionTeamLookupPrefix + "persons:"
@@ -34,7 +34,7 @@ func (r *rdTeamProjector) handleAccountLookup(ctx context.Context, event *eventi
 func (r *rdTeamProjector) handleRootAccountLookup(ctx context.Context, event *eventing.JournalEvent, e *domain.RootAccountCreatedEvent) error {
 	return r.insertAccountLookup(ctx, &teamAccountLookup{
 		ID:       domain.AccountID(event.AggregateID()),
-		FullName: fmt.Sprintf("%s %s", e.FirstName, e.LastName),
+		FullName: fmt.Sprintf("%s %s", e.FirstName.Value, e.LastName.Value),
 	})
 }
 
@@ -63,7 +63,7 @@ func (r *rdTeamProjector) handlePersonLookup(ctx context.Context, event *eventin
 	id := domain.PersonID(event.AggregateID())
 	p := &teamPersonLookup{
 		ID:       id,
-		FullName: fmt.Sprintf("%s %s", e.FirstName.Value, e.LastName.Value),
+		FullName: personFullName(e),
 	}
 	return insertJSON(ctx, r.rd, r.personLookupKey(id), p)
 }
@@ -0,0 +1,119 @@
+package projector
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+)
+
+const pgTeamPersonLookup = "persons"
+
+// pgTeamProjector projects the same TeamProjection as rdTeamProjector, but into postgres.
+type pgTeamProjector struct{}
+
+func NewPostgresTeamProjector() eventing.Projector {
+	return &pgTeamProjector{}
+}
+
+func (p *pgTeamProjector) Init(ctx context.Context) error {
+	return nil
+}
+
+func (p *pgTeamProjector) Query() eventing.JournalQuery {
+	return teamQuery()
+}
+
+func (p *pgTeamProjector) Projection() eventing.ProjectionName {
+	return ProjectionTeamName
+}
+
+func (p *pgTeamProjector) Drop(ctx context.Context) error {
+	return dropProjection(ctx, pgTeamTable, ProjectionTeamName)
+}
+
+func (p *pgTeamProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (p *pgTeamProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return p.ProjectWithTx(ctx, tx, events...)
+}
+
+func (p *pgTeamProjector) ProjectWithTx(ctx context.Context, tx pgx.Tx, events ...*eventing.JournalEvent) error {
+	ctx, span := tracing.Tracer.Start(ctx, "projector.postgres.Team.Project")
+	defer span.End()
+
+	var err error
+	for _, event := range events {
+		switch e := event.Event.(type) {
+		case *domain.TeamCreatedEvent:
+			err = putDocument(ctx, tx, pgTeamTable, string(event.AggregateID()), &TeamProjection{
+				ID:           domain.TeamID(event.AggregateID()),
+				Name:         e.Name,
+				Members:      make(TeamMemberSet),
+				Slug:         e.Slug,
+				CreatedAt:    e.CreatedAt,
+				UpdatedAt:    e.CreatedAt,
+				OwningClubID: e.OwningClubID,
+			})
+		case *domain.TeamDeletedEvent:
+			err = deleteDocument(ctx, tx, pgTeamTable, string(event.AggregateID()))
+		case *domain.PersonCreatedEvent:
+			err = putLookup(ctx, tx, ProjectionTeamName, pgTeamPersonLookup, string(event.AggregateID()), &teamPersonLookup{
+				ID:       domain.PersonID(event.AggregateID()),
+				FullName: personFullName(e),
+			})
+		case *domain.PersonShreddedEvent:
+			err = p.redactPerson(ctx, tx, event)
+		case *domain.PersonInvitedToTeamEvent:
+			err = p.insertPersonInvitedToTeamEvent(ctx, tx, event, e)
+		}
+		if err != nil {
+			tracing.RecordError(ctx, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pgTeamProjector) insertPersonInvitedToTeamEvent(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, e *domain.PersonInvitedToTeamEvent) error {
+	var lookup teamPersonLookup
+	if err := getLookup(ctx, tx, ProjectionTeamName, pgTeamPersonLookup, string(e.PersonID), &lookup); err != nil {
+		return err
+	}
+	member := TeamMemberProjection{
+		ID:       domain.TeamMemberID(e.AggregateID()),
+		PersonID: e.PersonID,
+		Name:     lookup.FullName,
+		Role:     e.AssignedRole,
+		JoinedAt: event.InsertedAt(),
+	}
+
+	// Only update the member property.
+	_, err := tx.Exec(ctx, "UPDATE projection_team SET document = jsonb_set(document, ARRAY['members', $2], $3) WHERE id = $1", e.TeamID, member.PersonID, &member)
+	return err
+}
+
+// redactPerson redacts the name of a shredded person in the lookup and in all teams the person is a member of.
+func (p *pgTeamProjector) redactPerson(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent) error {
+	personID := domain.PersonID(event.AggregateID())
+	if err := putLookup(ctx, tx, ProjectionTeamName, pgTeamPersonLookup, string(personID), &teamPersonLookup{
+		ID:       personID,
+		FullName: domain.RedactedString,
+	}); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, redactTeamMemberSQL, personID, domain.RedactedString)
+	return err
+}
+
+const redactTeamMemberSQL = `
+UPDATE projection_team SET document = jsonb_set(document, ARRAY['members', $1::TEXT, 'name'], to_jsonb($2::TEXT))
+WHERE document -> 'members' ? $1::TEXT
+`
@@ -0,0 +1,89 @@
+package projector
+
+import (
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"testing"
+	"time"
+)
+
+func TestTeamReadModel_RedactsShreddedMembers(t *testing.T) {
+	runReadModelTest(t, func(t *testing.T, project projectFunc, models ReadModels) {
+		clubID := idgen.New[domain.ClubID]()
+		teamID := idgen.New[domain.TeamID]()
+		shreddedID := idgen.New[domain.PersonID]()
+		otherID := idgen.New[domain.PersonID]()
+		operator := domain.Operator{ActorID: "account"}
+
+		project(t, ProjectionTeamName, journalEvents(
+			domain.NewPersonCreatedEvent(shreddedID, "Max", "Mustermann", time.Now(), operator, clubID),
+			domain.NewPersonCreatedEvent(otherID, "Erika", "Mustermann", time.Now(), operator, clubID),
+			domain.NewTeamCreatedEvent(teamID, "Team", string(teamID), clubID, operator, time.Now()),
+			domain.NewPersonInvitedToTeamEvent(idgen.New[domain.TeamMemberID](), shreddedID, teamID, operator, domain.TeamMemberRolePlayer),
+			domain.NewPersonInvitedToTeamEvent(idgen.New[domain.TeamMemberID](), otherID, teamID, operator, domain.TeamMemberRoleCoach),
+			domain.NewPersonShreddedEvent(shreddedID, operator, clubID),
+		)...)
+
+		expectMemberNames(t, models, teamID, map[domain.PersonID]string{
+			shreddedID: domain.RedactedString,
+			otherID:    "Erika Mustermann",
+		})
+
+		// A replay decrypts the names of the shredded person to their defaults, which must result in the same name.
+		replayedTeamID := idgen.New[domain.TeamID]()
+		replayedID := idgen.New[domain.PersonID]()
+		created := domain.NewPersonCreatedEvent(replayedID, "", "", time.Now(), operator, clubID)
+		created.FirstName = eventing.EncryptedString{Value: domain.RedactedString, IsShredded: true}
+		created.LastName = eventing.EncryptedString{Value: domain.RedactedString, IsShredded: true}
+		project(t, ProjectionTeamName, journalEvents(
+			created,
+			domain.NewTeamCreatedEvent(replayedTeamID, "Team", string(replayedTeamID), clubID, operator, time.Now()),
+			domain.NewPersonInvitedToTeamEvent(idgen.New[domain.TeamMemberID](), replayedID, replayedTeamID, operator, domain.TeamMemberRolePlayer),
+			domain.NewPersonShreddedEvent(replayedID, operator, clubID),
+		)...)
+
+		expectMemberNames(t, models, replayedTeamID, map[domain.PersonID]string{
+			replayedID: domain.RedactedString,
+		})
+	})
+}
+
+func expectMemberNames(t *testing.T, models ReadModels, teamID domain.TeamID, expected map[domain.PersonID]string) {
+	t.Helper()
+	members, err := models.TeamMembers(context.Background(), teamID)
+	if err != nil {
+		t.Fatalf("failed to read team members: %v", err)
+	}
+	if len(members) != len(expected) {
+		t.Fatalf("expected %d members, got %d", len(expected), len(members))
+	}
+	for _, member := range members {
+		if name, ok := expected[member.PersonID]; !ok || member.Name != name {
+			t.Errorf("expected member %s to be named %q, got %q", member.PersonID, name, member.Name)
+		}
+	}
+}
+
+func TestTeamReadModel_ProjectsEventsIdempotently(t *testing.T) {
+	runReadModelTest(t, func(t *testing.T, project projectFunc, models ReadModels) {
+		clubID := idgen.New[domain.ClubID]()
+		teamID := idgen.New[domain.TeamID]()
+		personID := idgen.New[domain.PersonID]()
+		operator := domain.Operator{ActorID: "account"}
+
+		events := journalEvents(
+			domain.NewPersonCreatedEvent(personID, "Erika", "Mustermann", time.Now(), operator, clubID),
+			domain.NewTeamCreatedEvent(teamID, "Team", string(teamID), clubID, operator, time.Now()),
+			domain.NewPersonInvitedToTeamEvent(idgen.New[domain.TeamMemberID](), personID, teamID, operator, domain.TeamMemberRolePlayer),
+		)
+		project(t, ProjectionTeamName, events...)
+		// Events are projected again if one of their writes failed, so the member must not be added twice.
+		project(t, ProjectionTeamName, events[0], events[2])
+
+		expectMemberNames(t, models, teamID, map[domain.PersonID]string{
+			personID: "Erika Mustermann",
+		})
+	})
+}
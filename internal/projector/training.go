@@ -6,14 +6,27 @@ import (
 	"github.com/redis/rueidis"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"slices"
+	"strings"
 	"time"
 )
 
-const (
-	ProjectionTrainingName    eventing.ProjectionName = "trainings"
-	ProjectionTrainingIDXName                         = "projectionTrainingV1Idx"
-	ProjectionTrainingPrefix                          = "projection:trainings:v1:"
+const ProjectionTrainingName eventing.ProjectionName = "trainings"
+
+// ProjectionTrainingKeyspace holds the trainings projection.
+// Bump its version to rebuild the projection alongside the live one, e.g. after changing the index schema.
+var ProjectionTrainingKeyspace = rdeventing.Keyspace{
+	Projection: ProjectionTrainingName,
+	Name:       "trainings",
+	Index:      "projectionTraining",
+	Version:    1,
+}
+
+var (
+	projectionTrainingPrefix = ProjectionTrainingKeyspace.Prefix(ProjectionTrainingKeyspace.Version)
+	projectionTrainingIndex  = ProjectionTrainingKeyspace.IndexName(ProjectionTrainingKeyspace.Version)
 )
 
 type TrainingProjection struct {
@@ -96,10 +109,10 @@ func (r *rdTrainingProjector) Init(ctx context.Context) error {
 
 	cmd := r.rd.B().
 		FtCreate().
-		Index(ProjectionTrainingIDXName).
+		Index(projectionTrainingIndex).
 		OnJson().
 		Prefix(1).
-		Prefix(ProjectionTrainingPrefix).
+		Prefix(projectionTrainingPrefix).
 		Schema().
 		FieldName("$.owning_club_id").As("owning_club_id").Tag().
 		FieldName("$.owning_team_id").As("owning_team_id").Tag().
@@ -118,12 +131,16 @@ func (r *rdTrainingProjector) Init(ctx context.Context) error {
 }
 
 func (r *rdTrainingProjector) Query() eventing.JournalQuery {
+	return trainingQuery()
+}
+
+func trainingQuery() eventing.JournalQuery {
 	var builder eventing.JournalQueryBuilder
 	return builder.
 		WithAggregate(domain.AccountAggregateType).
 		Events(domain.AccountCreatedEventType, domain.RootAccountCreatedEventType, domain.AccountRegisteredEventType).Finish().
 		WithAggregate(domain.PersonAggregateType).
-		Events(domain.PersonCreatedEventType).Finish().
+		Events(domain.PersonCreatedEventType, domain.PersonShreddedEventType).Finish().
 		WithAggregate(domain.TeamMemberAggregateType).
 		Events(domain.PersonInvitedToTeamEventType).Finish().
 		WithAggregate(domain.TrainingAggregateType).
@@ -135,6 +152,14 @@ func (r *rdTrainingProjector) Projection() eventing.ProjectionName {
 	return ProjectionTrainingName
 }
 
+func (r *rdTrainingProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (r *rdTrainingProjector) Keyspace() rdeventing.Keyspace {
+	return ProjectionTrainingKeyspace
+}
+
 func (r *rdTrainingProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
 	var err error
 	for _, event := range events {
@@ -149,6 +174,8 @@ func (r *rdTrainingProjector) Project(ctx context.Context, events ...*eventing.J
 			err = r.handleRootAccountLookup(ctx, event, e)
 		case *domain.PersonCreatedEvent:
 			err = r.handlePersonLookup(ctx, event, e)
+		case *domain.PersonShreddedEvent:
+			err = r.redactPerson(ctx, event, e)
 		case *domain.PersonInvitedToTeamEvent:
 			err = r.handleTeamMemberLookup(ctx, event, e)
 		case *domain.AccountRegisteredEvent:
@@ -219,7 +246,7 @@ func (r *rdTrainingProjector) insertTrainingScheduledEvent(ctx context.Context,
 }
 
 func (r *rdTrainingProjector) key(id domain.TrainingID) string {
-	return fmt.Sprintf("%s%s", ProjectionTrainingPrefix, id)
+	return fmt.Sprintf("%s%s", projectionTrainingPrefix, id)
 }
 
 func (r *rdTrainingProjector) insertPersonsNominatedForTrainingEvent(ctx context.Context, event *eventing.JournalEvent, e *domain.PersonsNominatedForTrainingEvent) error {
@@ -253,7 +280,9 @@ func (r *rdTrainingProjector) insertPersonsNominatedForTrainingEvent(ctx context
 				OnBehalfOf:    e.NominatedBy.OnBehalfOf,
 			},
 		}
-		projection.NominatedPersonIDs = append(projection.NominatedPersonIDs, playerID)
+		if !slices.Contains(projection.NominatedPersonIDs, playerID) {
+			projection.NominatedPersonIDs = append(projection.NominatedPersonIDs, playerID)
+		}
 	}
 
 	for _, staffID := range e.NominatedStaff {
@@ -282,8 +311,60 @@ func (r *rdTrainingProjector) insertPersonsNominatedForTrainingEvent(ctx context
 				OnBehalfOf:    e.NominatedBy.OnBehalfOf,
 			},
 		}
-		projection.NominatedPersonIDs = append(projection.NominatedPersonIDs, staffID)
+		if !slices.Contains(projection.NominatedPersonIDs, staffID) {
+			projection.NominatedPersonIDs = append(projection.NominatedPersonIDs, staffID)
+		}
+	}
+
+	return insertJSON(ctx, r.rd, r.key(trainingID), projection)
+}
+
+// redactPerson redacts the name of a shredded person in all trainings the person was nominated for.
+// The result matches a replay, in which the person lookup is already created with the redacted name.
+func (r *rdTrainingProjector) redactPerson(ctx context.Context, event *eventing.JournalEvent, e *domain.PersonShreddedEvent) error {
+	personID := domain.PersonID(event.AggregateID())
+	if err := insertJSON(ctx, r.rd, r.personLookupKey(personID), &trainingPersonLookup{
+		ID:       personID,
+		FullName: domain.RedactedString,
+	}); err != nil {
+		return err
 	}
 
+	const pageSize = 100
+	for offset := int64(0); ; offset += pageSize {
+		cmd := r.rd.B().FtSearch().Index(projectionTrainingIndex).
+			Query(fmt.Sprintf("@nominated_person_ids:{%s}", personID)).
+			Nocontent().
+			Limit().OffsetNum(offset, pageSize).
+			Dialect(4).
+			Build()
+		_, docs, err := r.rd.Do(ctx, cmd).AsFtSearch()
+		if err != nil {
+			return fmt.Errorf("failed to search trainings of person: %w", err)
+		}
+		for _, doc := range docs {
+			if err := r.redactNominatedPerson(ctx, domain.TrainingID(strings.TrimPrefix(doc.Key, projectionTrainingPrefix)), personID); err != nil {
+				return err
+			}
+		}
+		if len(docs) < pageSize {
+			return nil
+		}
+	}
+}
+
+func (r *rdTrainingProjector) redactNominatedPerson(ctx context.Context, trainingID domain.TrainingID, personID domain.PersonID) error {
+	projection, err := r.getProjection(ctx, trainingID)
+	if err != nil {
+		return err
+	}
+	if nominated, ok := projection.NominatedPlayers[personID]; ok {
+		nominated.Name = domain.RedactedString
+		projection.NominatedPlayers[personID] = nominated
+	}
+	if nominated, ok := projection.NominatedStaff[personID]; ok {
+		nominated.Name = domain.RedactedString
+		projection.NominatedStaff[personID] = nominated
+	}
 	return insertJSON(ctx, r.rd, r.key(trainingID), projection)
 }
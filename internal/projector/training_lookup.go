@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"github.com/rsmidt/soccerbuddy/internal/domain"
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	rdeventing "github.com/rsmidt/soccerbuddy/internal/redis/eventing"
 )
 
-const (
-	projectionTrainingLookupPrefix = "projection_lookup:trainings:v1:"
+var (
+	projectionTrainingLookupPrefix = ProjectionTrainingKeyspace.LookupPrefix(ProjectionTrainingKeyspace.Version)
 
 	projectionTrainingAccountLookupPrefix        = projectionTrainingLookupPrefix + "accounts:"
 	projectionTrainingPersonLookupPrefix         = projectionTrainingLookupPrefix + "persons:"
@@ -35,7 +36,7 @@ func (r *rdTrainingProjector) handleAccountLookup(ctx context.Context, event *ev
 func (r *rdTrainingProjector) handleRootAccountLookup(ctx context.Context, event *eventing.JournalEvent, e *domain.RootAccountCreatedEvent) error {
 	return r.insertAccountLookup(ctx, &trainingAccountLookup{
 		ID:       domain.AccountID(event.AggregateID()),
-		FullName: fmt.Sprintf("%s %s", e.FirstName, e.LastName),
+		FullName: fmt.Sprintf("%s %s", e.FirstName.Value, e.LastName.Value),
 	})
 }
 
@@ -64,7 +65,7 @@ func (r *rdTrainingProjector) handlePersonLookup(ctx context.Context, event *eve
 	id := domain.PersonID(event.AggregateID())
 	p := &trainingPersonLookup{
 		ID:       id,
-		FullName: fmt.Sprintf("%s %s", e.FirstName.Value, e.LastName.Value),
+		FullName: personFullName(e),
 	}
 	return insertJSON(ctx, r.rd, r.personLookupKey(id), p)
 }
@@ -81,7 +82,7 @@ func (r *rdTrainingProjector) personLookupKey(id domain.PersonID) string {
 
 func (r *rdTrainingProjector) handleTeamMemberLookup(ctx context.Context, event *eventing.JournalEvent, e *domain.PersonInvitedToTeamEvent) error {
 	cmd := r.rd.B().Set().Key(r.personTeamRoleLookupKey(e.PersonID, e.TeamID)).Value(e.AssignedRole.Deref()).Build()
-	return r.rd.Do(ctx, cmd).Error()
+	return rdeventing.Write(ctx, r.rd, cmd)
 }
 
 func (r *rdTrainingProjector) personTeamRoleLookupKey(personID domain.PersonID, teamID domain.TeamID) string {
@@ -0,0 +1,261 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/tracing"
+	"slices"
+)
+
+const (
+	pgTrainingAccountLookup        = "accounts"
+	pgTrainingPersonLookup         = "persons"
+	pgTrainingPersonTeamRoleLookup = "person_team_roles"
+)
+
+// pgTrainingProjector projects the same TrainingProjection as rdTrainingProjector, but into postgres.
+type pgTrainingProjector struct{}
+
+func NewPostgresTrainingProjector() eventing.Projector {
+	return &pgTrainingProjector{}
+}
+
+func (p *pgTrainingProjector) Init(ctx context.Context) error {
+	return nil
+}
+
+func (p *pgTrainingProjector) Query() eventing.JournalQuery {
+	return trainingQuery()
+}
+
+func (p *pgTrainingProjector) Projection() eventing.ProjectionName {
+	return ProjectionTrainingName
+}
+
+func (p *pgTrainingProjector) Drop(ctx context.Context) error {
+	return dropProjection(ctx, pgTrainingTable, ProjectionTrainingName)
+}
+
+func (p *pgTrainingProjector) FailurePolicy() eventing.FailurePolicy {
+	return readModelFailurePolicy
+}
+
+func (p *pgTrainingProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return p.ProjectWithTx(ctx, tx, events...)
+}
+
+func (p *pgTrainingProjector) ProjectWithTx(ctx context.Context, tx pgx.Tx, events ...*eventing.JournalEvent) error {
+	ctx, span := tracing.Tracer.Start(ctx, "projector.postgres.Training.Project")
+	defer span.End()
+
+	var err error
+	for _, event := range events {
+		switch e := event.Event.(type) {
+		case *domain.TrainingScheduledEvent:
+			err = p.insertTrainingScheduledEvent(ctx, tx, event, e)
+		case *domain.PersonsNominatedForTrainingEvent:
+			err = p.insertPersonsNominatedForTrainingEvent(ctx, tx, event, e)
+		case *domain.AccountCreatedEvent:
+			err = p.insertAccountLookup(ctx, tx, event, e.FirstName.Value, e.LastName.Value)
+		case *domain.RootAccountCreatedEvent:
+			err = p.insertAccountLookup(ctx, tx, event, e.FirstName.Value, e.LastName.Value)
+		case *domain.AccountRegisteredEvent:
+			err = p.insertAccountLookup(ctx, tx, event, e.FirstName.Value, e.LastName.Value)
+		case *domain.PersonCreatedEvent:
+			err = putLookup(ctx, tx, ProjectionTrainingName, pgTrainingPersonLookup, string(event.AggregateID()), &trainingPersonLookup{
+				ID:       domain.PersonID(event.AggregateID()),
+				FullName: personFullName(e),
+			})
+		case *domain.PersonShreddedEvent:
+			err = p.redactPerson(ctx, tx, event)
+		case *domain.PersonInvitedToTeamEvent:
+			err = putLookup(ctx, tx, ProjectionTrainingName, pgTrainingPersonTeamRoleLookup, p.personTeamRoleLookupID(e.PersonID, e.TeamID), e.AssignedRole)
+		}
+		if err != nil {
+			tracing.RecordError(ctx, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pgTrainingProjector) insertAccountLookup(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, firstName, lastName string) error {
+	return putLookup(ctx, tx, ProjectionTrainingName, pgTrainingAccountLookup, string(event.AggregateID()), &trainingAccountLookup{
+		ID:       domain.AccountID(event.AggregateID()),
+		FullName: fmt.Sprintf("%s %s", firstName, lastName),
+	})
+}
+
+func (p *pgTrainingProjector) lookupAccount(ctx context.Context, tx pgx.Tx, id domain.AccountID) (*trainingAccountLookup, error) {
+	var lookup trainingAccountLookup
+	return &lookup, getLookup(ctx, tx, ProjectionTrainingName, pgTrainingAccountLookup, string(id), &lookup)
+}
+
+func (p *pgTrainingProjector) lookupPerson(ctx context.Context, tx pgx.Tx, id domain.PersonID) (*trainingPersonLookup, error) {
+	var lookup trainingPersonLookup
+	return &lookup, getLookup(ctx, tx, ProjectionTrainingName, pgTrainingPersonLookup, string(id), &lookup)
+}
+
+func (p *pgTrainingProjector) personTeamRoleLookupID(personID domain.PersonID, teamID domain.TeamID) string {
+	return fmt.Sprintf("%s:%s", personID, teamID)
+}
+
+func (p *pgTrainingProjector) getProjection(ctx context.Context, tx pgx.Tx, id domain.TrainingID) (*TrainingProjection, error) {
+	var projection TrainingProjection
+	return &projection, getDocument(ctx, tx, pgTrainingTable, string(id), &projection)
+}
+
+func (p *pgTrainingProjector) insertTrainingScheduledEvent(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, e *domain.TrainingScheduledEvent) error {
+	actor, err := p.lookupAccount(ctx, tx, e.ScheduledBy.ActorID)
+	if err != nil {
+		return err
+	}
+	var gatheringPoint *TrainingGatheringPointProjection
+	if e.GatheringPoint != nil {
+		gatheringPoint = &TrainingGatheringPointProjection{
+			Location:        e.GatheringPoint.Location,
+			GatherUntil:     e.GatheringPoint.GatherUntil,
+			GatherUntilIANA: e.GatheringPoint.GatherUntilIANA,
+		}
+	}
+	var acknowledgmentSettings *TrainingAcknowledgmentSettingsProjection
+	if e.AcknowledgmentSettings != nil {
+		acknowledgmentSettings = &TrainingAcknowledgmentSettingsProjection{
+			AcknowledgeUntil:     e.AcknowledgmentSettings.AcknowledgeUntil,
+			AcknowledgeUntilIANA: e.AcknowledgmentSettings.AcknowledgeUntilIANA,
+		}
+	}
+	training := TrainingProjection{
+		ID:                     domain.TrainingID(event.AggregateID()),
+		ScheduledAt:            e.ScheduledAt,
+		ScheduledAtIANA:        e.ScheduledAtIANA,
+		ScheduledAtTS:          e.ScheduledAt.Unix(),
+		EndsAt:                 e.EndsAt,
+		EndsAtIANA:             e.EndsAtIANA,
+		EndsAtTS:               e.EndsAt.Unix(),
+		Description:            e.Description,
+		Location:               e.Location,
+		FieldType:              e.FieldType,
+		GatheringPoint:         gatheringPoint,
+		AcknowledgmentSettings: acknowledgmentSettings,
+		RatingSettings: TrainingRatingSettingsProjection{
+			Policy: e.RatingSettings.Policy,
+		},
+		ScheduledBy: OperatorProjection{
+			ActorID:       e.ScheduledBy.ActorID,
+			ActorFullName: actor.FullName,
+			OnBehalfOf:    e.ScheduledBy.OnBehalfOf,
+		},
+		NominatedStaff:   make(TrainingNominatedPersonSet),
+		NominatedPlayers: make(TrainingNominatedPersonSet),
+		OwningClubID:     e.OwningClubID,
+		OwningTeamID:     &e.TeamID,
+	}
+	return putDocument(ctx, tx, pgTrainingTable, string(training.ID), &training)
+}
+
+func (p *pgTrainingProjector) insertPersonsNominatedForTrainingEvent(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent, e *domain.PersonsNominatedForTrainingEvent) error {
+	projection, err := p.getProjection(ctx, tx, domain.TrainingID(event.AggregateID()))
+	if err != nil {
+		return err
+	}
+	actor, err := p.lookupAccount(ctx, tx, e.NominatedBy.ActorID)
+	if err != nil {
+		return err
+	}
+	nominatedBy := OperatorProjection{
+		ActorID:       e.NominatedBy.ActorID,
+		ActorFullName: actor.FullName,
+		OnBehalfOf:    e.NominatedBy.OnBehalfOf,
+	}
+
+	for _, playerID := range e.NominatedPlayers {
+		person, err := p.lookupPerson(ctx, tx, playerID)
+		if err != nil {
+			return err
+		}
+		projection.NominatedPlayers[playerID] = TrainingNominatedPersonProjection{
+			ID:   playerID,
+			Name: person.FullName,
+			Role: domain.TeamMemberRolePlayer,
+			Acknowledgment: TrainingNominationAcknowledgmentProjection{
+				Type: domain.TrainingNominationUnacknowledged,
+			},
+			NominatedAt: event.InsertedAt(),
+			NominatedBy: nominatedBy,
+		}
+		if !slices.Contains(projection.NominatedPersonIDs, playerID) {
+			projection.NominatedPersonIDs = append(projection.NominatedPersonIDs, playerID)
+		}
+	}
+
+	for _, staffID := range e.NominatedStaff {
+		person, err := p.lookupPerson(ctx, tx, staffID)
+		if err != nil {
+			return err
+		}
+		role := domain.TeamMemberRoleGuest
+		if e.TeamID != nil {
+			if err := getLookup(ctx, tx, ProjectionTrainingName, pgTrainingPersonTeamRoleLookup, p.personTeamRoleLookupID(person.ID, *e.TeamID), &role); err != nil {
+				return err
+			}
+		}
+		projection.NominatedStaff[staffID] = TrainingNominatedPersonProjection{
+			ID:   staffID,
+			Name: person.FullName,
+			Role: role,
+			Acknowledgment: TrainingNominationAcknowledgmentProjection{
+				Type: domain.TrainingNominationUnacknowledged,
+			},
+			NominatedAt: event.InsertedAt(),
+			NominatedBy: nominatedBy,
+		}
+		if !slices.Contains(projection.NominatedPersonIDs, staffID) {
+			projection.NominatedPersonIDs = append(projection.NominatedPersonIDs, staffID)
+		}
+	}
+
+	return putDocument(ctx, tx, pgTrainingTable, string(projection.ID), projection)
+}
+
+// redactPerson redacts the name of a shredded person in the lookup and in all trainings the person was nominated for.
+func (p *pgTrainingProjector) redactPerson(ctx context.Context, tx pgx.Tx, event *eventing.JournalEvent) error {
+	personID := domain.PersonID(event.AggregateID())
+	if err := putLookup(ctx, tx, ProjectionTrainingName, pgTrainingPersonLookup, string(personID), &trainingPersonLookup{
+		ID:       personID,
+		FullName: domain.RedactedString,
+	}); err != nil {
+		return err
+	}
+
+	trainings, err := collectDocuments[TrainingProjection](tx.Query(ctx, selectNominatedTrainingsSQL, personID))
+	if err != nil {
+		return fmt.Errorf("failed to query trainings of person: %w", err)
+	}
+	for _, training := range trainings {
+		if nominated, ok := training.NominatedPlayers[personID]; ok {
+			nominated.Name = domain.RedactedString
+			training.NominatedPlayers[personID] = nominated
+		}
+		if nominated, ok := training.NominatedStaff[personID]; ok {
+			nominated.Name = domain.RedactedString
+			training.NominatedStaff[personID] = nominated
+		}
+		if err := putDocument(ctx, tx, pgTrainingTable, string(training.ID), training); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const selectNominatedTrainingsSQL = `
+SELECT document FROM projection_training
+WHERE document @> jsonb_build_object('nominated_person_ids', jsonb_build_array($1::TEXT))
+`
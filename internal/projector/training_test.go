@@ -0,0 +1,82 @@
+package projector
+
+import (
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/domain"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"testing"
+	"time"
+)
+
+func TestTrainingReadModel_ProjectsNominations(t *testing.T) {
+	runReadModelTest(t, func(t *testing.T, project projectFunc, models ReadModels) {
+		ctx := context.Background()
+		f := newReadModelFixture()
+		teamID := idgen.New[domain.TeamID]()
+		playerID := idgen.New[domain.PersonID]()
+		coachID := idgen.New[domain.PersonID]()
+		pastID := idgen.New[domain.TrainingID]()
+		laterID := idgen.New[domain.TrainingID]()
+		soonID := idgen.New[domain.TrainingID]()
+		now := time.Now().UTC().Truncate(time.Second)
+
+		project(t, ProjectionTrainingName, journalEvents(append(f.events(),
+			domain.NewPersonCreatedEvent(playerID, "Erika", "Mustermann", now, f.operator, f.clubID),
+			domain.NewPersonCreatedEvent(coachID, "Max", "Musterfrau", now, f.operator, f.clubID),
+			domain.NewPersonInvitedToTeamEvent(idgen.New[domain.TeamMemberID](), playerID, teamID, f.operator, domain.TeamMemberRolePlayer),
+			domain.NewPersonInvitedToTeamEvent(idgen.New[domain.TeamMemberID](), coachID, teamID, f.operator, domain.TeamMemberRoleCoach),
+			scheduleTraining(pastID, now.Add(-24*time.Hour), teamID, f),
+			scheduleTraining(laterID, now.Add(48*time.Hour), teamID, f),
+			scheduleTraining(soonID, now.Add(24*time.Hour), teamID, f),
+			domain.NewPersonsNominatedForTrainingEvent(laterID, []domain.PersonID{playerID}, []domain.PersonID{coachID}, f.operator, domain.TrainingNominationNotificationPolicySilent, &teamID),
+		)...)...)
+
+		trainings, err := models.Trainings(ctx, teamID, now)
+		if err != nil {
+			t.Fatalf("failed to read trainings: %v", err)
+		}
+		expectTrainingIDs(t, trainings, soonID, laterID)
+
+		nominated, err := models.NominatedTrainings(ctx, teamID, playerID, now)
+		if err != nil {
+			t.Fatalf("failed to read nominated trainings: %v", err)
+		}
+		expectTrainingIDs(t, nominated, laterID)
+
+		training := nominated[0]
+		if player := training.NominatedPlayers[playerID]; player.Name != "Erika Mustermann" || player.Role != domain.TeamMemberRolePlayer {
+			t.Errorf("unexpected nominated player %+v", player)
+		}
+		if coach := training.NominatedStaff[coachID]; coach.Name != "Max Musterfrau" || coach.Role != domain.TeamMemberRoleCoach {
+			t.Errorf("unexpected nominated staff %+v", coach)
+		}
+		if training.ScheduledBy.ActorFullName != "Max Mustermann" {
+			t.Errorf("expected training to be scheduled by Max Mustermann, got %q", training.ScheduledBy.ActorFullName)
+		}
+	})
+}
+
+func scheduleTraining(id domain.TrainingID, scheduledAt time.Time, teamID domain.TeamID, f readModelFixture) eventing.Event {
+	return domain.NewTrainingScheduledEvent(
+		id,
+		scheduledAt, "Europe/Berlin",
+		scheduledAt.Add(90*time.Minute), "Europe/Berlin",
+		nil, nil, nil, nil, nil,
+		domain.TrainingRatingSettings{Policy: domain.TrainingRatingPolicyAllowed},
+		teamID, f.clubID, f.operator,
+	)
+}
+
+// expectTrainingIDs checks that the trainings are returned in the expected order.
+func expectTrainingIDs(t *testing.T, trainings []*TrainingProjection, expected ...domain.TrainingID) {
+	t.Helper()
+	if len(trainings) != len(expected) {
+		t.Fatalf("expected %d trainings, got %d", len(expected), len(trainings))
+	}
+	for i, id := range expected {
+		if trainings[i].ID != id {
+			t.Errorf("expected training %d to be %s, got %s", i, id, trainings[i].ID)
+		}
+	}
+}
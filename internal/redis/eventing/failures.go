@@ -0,0 +1,56 @@
+package eventing
+
+import (
+	"context"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+)
+
+// versionedFailures keys the failures of a projection by the version the projector writes.
+// A version that is built next to the live one neither inherits nor clears the failures of the live version that way.
+type versionedFailures struct {
+	eventing.ProjectionFailureStore
+	version int
+}
+
+// failuresOf returns the failure store scoped to the version the projector writes.
+func (r *redisSupervisor) failuresOf(projector eventing.Projector) eventing.ProjectionFailureStore {
+	if rdProjector, ok := projector.(RedisProjector); ok {
+		return &versionedFailures{ProjectionFailureStore: r.failures, version: rdProjector.Keyspace().Version}
+	}
+	return r.failures
+}
+
+func (f *versionedFailures) name(projection eventing.ProjectionName) eventing.ProjectionName {
+	return eventing.ProjectionName(versionedName(projection, f.version))
+}
+
+func (f *versionedFailures) Record(ctx context.Context, projection eventing.ProjectionName, event *eventing.JournalEvent, cause error, policy eventing.FailurePolicy) (*eventing.ProjectionFailure, error) {
+	return f.ProjectionFailureStore.Record(ctx, f.name(projection), event, cause, policy)
+}
+
+func (f *versionedFailures) Blocked(ctx context.Context, projection eventing.ProjectionName) (bool, error) {
+	return f.ProjectionFailureStore.Blocked(ctx, f.name(projection))
+}
+
+func (f *versionedFailures) Resolve(ctx context.Context, projection eventing.ProjectionName) error {
+	return f.ProjectionFailureStore.Resolve(ctx, f.name(projection))
+}
+
+func (f *versionedFailures) List(ctx context.Context, projection eventing.ProjectionName) ([]*eventing.ProjectionFailure, error) {
+	if projection == "" {
+		return f.ProjectionFailureStore.List(ctx, projection)
+	}
+	return f.ProjectionFailureStore.List(ctx, f.name(projection))
+}
+
+func (f *versionedFailures) Get(ctx context.Context, projection eventing.ProjectionName, eventID eventing.EventID) (*eventing.ProjectionFailure, error) {
+	return f.ProjectionFailureStore.Get(ctx, f.name(projection), eventID)
+}
+
+func (f *versionedFailures) Delete(ctx context.Context, projection eventing.ProjectionName, eventID eventing.EventID) error {
+	return f.ProjectionFailureStore.Delete(ctx, f.name(projection), eventID)
+}
+
+func (f *versionedFailures) Clear(ctx context.Context, projection eventing.ProjectionName) error {
+	return f.ProjectionFailureStore.Clear(ctx, f.name(projection))
+}
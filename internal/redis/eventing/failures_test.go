@@ -0,0 +1,35 @@
+package eventing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+)
+
+// clearedFailures records the projections whose failures have been cleared.
+type clearedFailures struct {
+	eventing.ProjectionFailureStore
+	cleared []eventing.ProjectionName
+}
+
+func (c *clearedFailures) Clear(_ context.Context, projection eventing.ProjectionName) error {
+	c.cleared = append(c.cleared, projection)
+	return nil
+}
+
+func TestVersionedFailures(t *testing.T) {
+	store := &clearedFailures{}
+	for _, version := range []int{1, 2} {
+		failures := &versionedFailures{ProjectionFailureStore: store, version: version}
+		if err := failures.Clear(context.Background(), "trainings"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The first version keeps the name from before projections were versioned.
+	expected := []eventing.ProjectionName{"trainings", "trainings@v2"}
+	if len(store.cleared) != len(expected) || store.cleared[0] != expected[0] || store.cleared[1] != expected[1] {
+		t.Errorf("expected the failures of %v to be cleared, got %v", expected, store.cleared)
+	}
+}
@@ -0,0 +1,87 @@
+package eventing
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/rueidis"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"time"
+)
+
+// liveVersionCacheTTL bounds how long a client caches the live version of a projection.
+// Redis invalidates the cache as soon as the version is switched, so it only matters if the invalidation is lost.
+const liveVersionCacheTTL = time.Minute
+
+// Keyspace names the keys and the search index of a redis projection by version.
+//
+// A new version is built alongside the live one, e.g. after changing the schema of the search index.
+// Once it has caught up, the index alias is switched over to it and the keys of the old version are removed.
+// Queries must therefore search the [Keyspace.Alias] and read keys with the [Keyspace.LivePrefix].
+type Keyspace struct {
+	// Projection is the name of the projection.
+	Projection eventing.ProjectionName
+
+	// Name is the name of the keys, e.g. "trainings" for "projection:trainings:v1:".
+	Name string
+
+	// Index is the base name of the search index, e.g. "projectionTraining" for the
+	// "projectionTrainingV1Idx" index and the "projectionTrainingIdx" alias.
+	Index string
+
+	// Version is the version the projector writes.
+	Version int
+}
+
+// Prefix returns the prefix of the projected documents of the version.
+func (k Keyspace) Prefix(version int) string {
+	return fmt.Sprintf("projection:%s:v%d:", k.Name, version)
+}
+
+// LookupPrefix returns the prefix of the lookups the projector keeps for itself in the version.
+func (k Keyspace) LookupPrefix(version int) string {
+	return fmt.Sprintf("projection_lookup:%s:v%d:", k.Name, version)
+}
+
+// IndexName returns the name of the search index of the version.
+func (k Keyspace) IndexName(version int) string {
+	return fmt.Sprintf("%sV%dIdx", k.Index, version)
+}
+
+// Alias returns the alias of the search index that points to the live version.
+func (k Keyspace) Alias() string {
+	return k.Index + "Idx"
+}
+
+// LivePrefix returns the prefix of the documents of the live version.
+// Falls back to the own version if no version has been activated yet.
+func (k Keyspace) LivePrefix(ctx context.Context, rd rueidis.Client) (string, error) {
+	version, err := LiveVersion(ctx, rd, k.Projection)
+	if err != nil {
+		return "", err
+	}
+	if version == 0 {
+		version = k.Version
+	}
+	return k.Prefix(version), nil
+}
+
+// LiveVersion returns the version of the projection that serves queries or 0 if none has been activated yet.
+// The version is cached on the client.
+func LiveVersion(ctx context.Context, rd rueidis.Client, projection eventing.ProjectionName) (int, error) {
+	cmd := rd.B().Get().Key(liveVersionKey(projection)).Cache()
+	return decodeLiveVersion(rd.DoCache(ctx, cmd, liveVersionCacheTTL), projection)
+}
+
+func decodeLiveVersion(resp rueidis.RedisResult, projection eventing.ProjectionName) (int, error) {
+	version, err := resp.AsInt64()
+	if rueidis.IsRedisNil(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to get live version of projection %s: %w", projection, err)
+	}
+	return int(version), nil
+}
+
+func liveVersionKey(projection eventing.ProjectionName) string {
+	return fmt.Sprintf("projection:live:%s", projection)
+}
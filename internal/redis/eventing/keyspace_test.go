@@ -0,0 +1,28 @@
+package eventing
+
+import "testing"
+
+func TestKeyspace(t *testing.T) {
+	keyspace := Keyspace{Projection: "trainings", Name: "trainings", Index: "projectionTraining", Version: 2}
+
+	// The first version keeps the names from before projections were versioned.
+	tests := []struct {
+		got      string
+		expected string
+	}{
+		{keyspace.Prefix(1), "projection:trainings:v1:"},
+		{keyspace.LookupPrefix(1), "projection_lookup:trainings:v1:"},
+		{keyspace.IndexName(1), "projectionTrainingV1Idx"},
+		{projectionStateKey(keyspace.Projection, 1), "projection:state:trainings:v2"},
+		{keyspace.Prefix(2), "projection:trainings:v2:"},
+		{keyspace.IndexName(2), "projectionTrainingV2Idx"},
+		{projectionStateKey(keyspace.Projection, 2), "projection:state:trainings@v2:v2"},
+		{legacyProjectionStateKey(keyspace.Projection), "projection:state:trainings:v1"},
+		{keyspace.Alias(), "projectionTrainingIdx"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, tt.got)
+		}
+	}
+}
@@ -10,11 +10,33 @@ import (
 	"github.com/rsmidt/soccerbuddy/internal/eventing"
 	"github.com/rsmidt/soccerbuddy/internal/tracing"
 	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+const (
+	// scanBatchSize is the number of keys a single SCAN should inspect while dropping a projection.
+	scanBatchSize = 1000
+
+	// supervisorType is reported in the status of the projections.
+	supervisorType = "redis"
+)
+
+// RedisProjector is a projector that stores its projection in the keys of a [Keyspace].
+// A projector with a newer version than the live one builds its version in the background and
+// activates it once it has caught up. A projector with an older version is no longer advanced.
+type RedisProjector interface {
+	eventing.Projector
+
+	// Keyspace returns the keyspace the projector writes to.
+	Keyspace() Keyspace
+}
+
 type redisSupervisor struct {
 	mu                  sync.RWMutex
 	log                 *slog.Logger
@@ -22,17 +44,23 @@ type redisSupervisor struct {
 	projectorByInterest map[eventing.EventInterest][]eventing.Projector
 	started             atomic.Bool
 	es                  eventing.EventStore
+	counters            eventing.ProjectionCounters
+	positions           eventing.ProjectedPositions
+	failures            eventing.ProjectionFailureStore
+	// migrated holds the projections whose legacy state has been migrated by this instance.
+	migrated sync.Map
 
 	rd     rueidis.Client
 	locker rueidislock.Locker
 }
 
-func NewProjectorSupervisor(log *slog.Logger, es eventing.EventStore, rd rueidis.Client, locker rueidislock.Locker) eventing.ProjectorSupervisor {
+func NewProjectorSupervisor(log *slog.Logger, es eventing.EventStore, failures eventing.ProjectionFailureStore, rd rueidis.Client, locker rueidislock.Locker) eventing.ProjectorSupervisor {
 	return &redisSupervisor{
 		log:                 log,
 		projectors:          make(map[eventing.ProjectionName]eventing.Projector),
 		projectorByInterest: make(map[eventing.EventInterest][]eventing.Projector),
 		es:                  es,
+		failures:            failures,
 		rd:                  rd,
 		locker:              locker,
 	}
@@ -70,15 +98,19 @@ func (r *redisSupervisor) Notify(ctx context.Context, interests ...eventing.Even
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	position, positioned := eventing.NotifiedPositionFromContext(ctx)
 	var triggeredProjectors []eventing.Projector
 	for _, interest := range interests {
-		if projectors, ok := r.projectorByInterest[interest]; ok {
-			triggeredProjectors = append(triggeredProjectors, projectors...)
+		for _, projector := range r.projectorByInterest[interest] {
+			// An earlier run might have already caught up on the event.
+			if positioned && r.positions.Covers(projector.Projection(), position) {
+				continue
+			}
+			triggeredProjectors = append(triggeredProjectors, projector)
 		}
 	}
-	if err := r.trigger(ctx, false, triggeredProjectors...); err != nil {
-		return false
-	}
+	// Failures are logged and counted by trigger. The next notification or polling run retries them, so the listener stays registered.
+	_ = r.trigger(ctx, false, triggeredProjectors...)
 	return true
 }
 
@@ -112,21 +144,47 @@ func (r *redisSupervisor) Trigger(ctx context.Context, projection ...eventing.Pr
 	}
 }
 
+func (r *redisSupervisor) Advance(ctx context.Context, projection eventing.ProjectionName) error {
+	ctx, span := tracing.Tracer.Start(ctx, "rd.ProjectorSupervisor.Advance")
+	defer span.End()
+
+	r.mu.RLock()
+	projector, ok := r.projectors[projection]
+	r.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+	if paused, err := r.paused(ctx, projection); err != nil {
+		tracing.RecordError(ctx, err)
+		return err
+	} else if paused {
+		return eventing.ErrProjectionPaused
+	}
+	if err := r.trigger(ctx, true, projector); err != nil {
+		tracing.RecordError(ctx, err)
+		return err
+	}
+	return nil
+}
+
 func (r *redisSupervisor) trigger(ctx context.Context, wait bool, projectors ...eventing.Projector) error {
+	var rerr error
 	for _, projector := range projectors {
 		if err := r.triggerProjector(ctx, wait, projector); err != nil {
+			r.counters.AddFailure(projector.Projection())
 			r.log.ErrorContext(ctx, "Failed to trigger projector", slog.String("projector", string(projector.Projection())), slog.String("projector_type", "redis"), slog.String("err", err.Error()))
+			rerr = errors.Join(rerr, err)
 		}
 	}
-	return nil
+	return rerr
 }
 
 func (r *redisSupervisor) triggerProjector(ctx context.Context, wait bool, projector eventing.Projector) error {
 	ctx, span := tracing.Tracer.Start(ctx, "rd.ProjectorSupervisor.triggerProjector")
 	defer span.End()
 
-	name := string(projector.Projection())
-	r.log.DebugContext(ctx, "Advancing projector", slog.String("projection", name), slog.String("projector_type", "redis"))
+	name := lockName(projector)
+	r.log.DebugContext(ctx, "Advancing projector", slog.String("projection", string(projector.Projection())), slog.String("projector_type", "redis"))
 
 	// Acquire a lock either by waiting or not.
 	var (
@@ -145,9 +203,54 @@ func (r *redisSupervisor) triggerProjector(ctx context.Context, wait bool, proje
 	}
 	defer cancel()
 
+	return r.catchUp(ctx, projector)
+}
+
+// catchUp projects all pending events and activates the version of the projector once it has caught up.
+// The caller must hold the lock of the projector.
+func (r *redisSupervisor) catchUp(ctx context.Context, projector eventing.Projector) error {
+	if err := r.migrateLegacyState(ctx, projector); err != nil {
+		return fmt.Errorf("failed to migrate legacy state: %w", err)
+	}
+
+	var (
+		keyspace Keyspace
+		live     int
+		err      error
+	)
+	rdProjector, versioned := projector.(RedisProjector)
+	if versioned {
+		keyspace = rdProjector.Keyspace()
+		live, err = r.liveVersion(ctx, keyspace.Projection)
+		if err != nil {
+			return err
+		}
+		switch {
+		case keyspace.Version < live:
+			r.log.DebugContext(ctx, "Skipping superseded projection version", slog.String("projection", string(keyspace.Projection)), slog.Int("version", keyspace.Version), slog.Int("live_version", live))
+			return nil
+		case live == 0:
+			// The first version has nothing to replace, so it serves queries right away.
+			if err := r.activate(ctx, keyspace, 0); err != nil {
+				return err
+			}
+			live = keyspace.Version
+		}
+	}
+
+	// A failed event holds back the projection until its next attempt or until it is re-driven.
+	if blocked, err := r.failuresOf(projector).Blocked(ctx, projector.Projection()); err != nil {
+		return err
+	} else if blocked {
+		return nil
+	}
+	if paused, err := r.paused(ctx, projector.Projection()); err != nil || paused {
+		return err
+	}
+
 	// Get the current state.
 	var state eventing.ProjectionState
-	stateKey := fmt.Sprintf("projection:state:%s:v1", projector.Projection())
+	stateKey := projectionStateKey(projector.Projection(), keyspace.Version)
 	cmd := r.rd.B().JsonGet().Key(stateKey).Path(".").Build().Pin()
 	if err := r.rd.Do(ctx, cmd).DecodeJSON(&state); rueidis.IsRedisNil(err) {
 		state = eventing.ProjectionState{Name: projector.Projection()}
@@ -155,29 +258,428 @@ func (r *redisSupervisor) triggerProjector(ctx context.Context, wait bool, proje
 		return err
 	}
 
-	// Fetch the events from the event store.
-	queryWithPos := eventing.NewJournalQueryBuilderFrom(projector.Query()).
-		WithJournalPositionAfter(eventing.JournalPosition(state.GlobalPosition)).MustBuild()
-	events, err := r.es.Query(ctx, queryWithPos, eventing.WithLimitToOldestRunningTransaction())
+	// Catch up in bounded batches and checkpoint after each one.
+	var caughtUp bool
+	for ctx.Err() == nil {
+		queryWithPos := eventing.NewJournalQueryBuilderFrom(projector.Query()).
+			WithJournalPositionAfter(state.Position).
+			WithLimit(eventing.ProjectionBatchSize).
+			MustBuild()
+		events, err := r.es.Query(ctx, queryWithPos, eventing.WithLimitToOldestRunningTransaction())
+		if err != nil {
+			return fmt.Errorf("failed to query events for projection: %v", err)
+		}
+		handled, projectErr := eventing.ProjectWithPolicy(ctx, r.failuresOf(projector), projector, events, func(ctx context.Context, events ...*eventing.JournalEvent) error {
+			for _, event := range events {
+				// After a failure, the events are projected once more to find the failing one.
+				// The ones before it have already been checkpointed along with their changes.
+				if !event.JournalPosition().After(state.Position) {
+					continue
+				}
+				next, err := r.projectEvent(ctx, projector, stateKey, state, event)
+				if err != nil {
+					return err
+				}
+				state = next
+			}
+			return nil
+		})
+		r.counters.AddProcessed(projector.Projection(), handled)
+
+		// Checkpoint the handled events even if projecting a later one failed, as dead-lettered events are skipped
+		// without a transaction of their own.
+		state = updateState(state, events[:handled])
+		val, err := json.Marshal(&state)
+		if err != nil {
+			return err
+		}
+		cmd = r.rd.B().JsonSet().Key(stateKey).Path(".").Value(string(val)).Build()
+		if err := r.rd.Do(ctx, cmd).Error(); err != nil {
+			return err
+		}
+		r.positions.Advance(projector.Projection(), state.Position)
+		if projectErr != nil {
+			return fmt.Errorf("failed to project: %w", projectErr)
+		}
+		if len(events) < eventing.ProjectionBatchSize {
+			caughtUp = true
+			break
+		}
+	}
+	if versioned && caughtUp && live != keyspace.Version {
+		return r.activate(ctx, keyspace, live)
+	}
+	return nil
+}
+
+// projectEvent projects the event and writes its changes atomically with the checkpoint, so that a crash never
+// leaves changes behind that are applied once more when the event is replayed.
+// The event is only checkpointed if all of its changes have been applied, a failed one fails the event instead.
+func (r *redisSupervisor) projectEvent(ctx context.Context, projector eventing.Projector, stateKey string, state eventing.ProjectionState, event *eventing.JournalEvent) (eventing.ProjectionState, error) {
+	writesCtx, writes := newContextWithPendingWrites(ctx)
+	if err := projector.Project(writesCtx, event); err != nil {
+		return state, err
+	}
+
+	next := updateState(state, []*eventing.JournalEvent{event})
+	val, err := json.Marshal(&next)
+	if err != nil {
+		return state, err
+	}
+	cmdErr, err := execCheckpointed(ctx, r.rd, stateKey, val, writes.cmds...)
+	if err != nil {
+		return state, err
+	}
+	if cmdErr != nil {
+		return state, fmt.Errorf("failed to apply the changes of event %s: %w", event.EventID(), cmdErr)
+	}
+	return next, nil
+}
+
+// liveVersion reads the live version without the client cache, as the supervisor changes it itself.
+func (r *redisSupervisor) liveVersion(ctx context.Context, projection eventing.ProjectionName) (int, error) {
+	return decodeLiveVersion(r.rd.Do(ctx, r.rd.B().Get().Key(liveVersionKey(projection)).Build()), projection)
+}
+
+// activate switches queries over to the version of the keyspace and removes the previous version.
+func (r *redisSupervisor) activate(ctx context.Context, keyspace Keyspace, previous int) error {
+	// Switch the index alias and the live version together, so that searches and reads by key agree.
+	// The previous version is only dropped if both commands succeeded, as queries would lose it otherwise.
+	cmdErr, err := execTx(ctx, r.rd,
+		r.rd.B().FtAliasupdate().Alias(keyspace.Alias()).Index(keyspace.IndexName(keyspace.Version)).Build(),
+		r.rd.B().Set().Key(liveVersionKey(keyspace.Projection)).Value(strconv.Itoa(keyspace.Version)).Build(),
+	)
+	if err = errors.Join(err, cmdErr); err != nil {
+		return fmt.Errorf("failed to activate version %d of projection %s: %w", keyspace.Version, keyspace.Projection, err)
+	}
+	r.log.InfoContext(ctx, "Activated projection version", slog.String("projection", string(keyspace.Projection)), slog.Int("version", keyspace.Version), slog.Int("previous_version", previous))
+	if previous == 0 || previous == keyspace.Version {
+		return nil
+	}
+
+	// Remove the previous version while holding its lock, so that no instance running it still writes to it.
+	ctx, cancel, err := r.locker.WithContext(ctx, versionedName(keyspace.Projection, previous))
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	if err := r.drop(ctx, keyspace, previous); err != nil {
+		return fmt.Errorf("failed to remove version %d of projection %s: %w", previous, keyspace.Projection, err)
+	}
+	return nil
+}
+
+func (r *redisSupervisor) Reset(ctx context.Context, projection eventing.ProjectionName) error {
+	ctx, span := tracing.Tracer.Start(ctx, "rd.ProjectorSupervisor.Reset")
+	defer span.End()
+
+	r.mu.RLock()
+	projector, ok := r.projectors[projection]
+	r.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+
+	// Hold the lock for the whole reset, so that no trigger projects into a partially dropped projection.
+	ctx, cancel, err := r.locker.WithContext(ctx, lockName(projector))
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	r.log.InfoContext(ctx, "Resetting projection", slog.String("projection", string(projection)), slog.String("projector_type", "redis"))
+	return r.reset(ctx, projector)
+}
+
+// reset drops the version of the projection the projector writes, so that it is replayed from the start.
+// The caller must hold the lock of the projector.
+func (r *redisSupervisor) reset(ctx context.Context, projector eventing.Projector) error {
+	projection := projector.Projection()
+	if rdProjector, ok := projector.(RedisProjector); ok {
+		keyspace := rdProjector.Keyspace()
+		live, err := r.liveVersion(ctx, projection)
+		if err != nil {
+			return err
+		}
+		if err := r.drop(ctx, keyspace, keyspace.Version); err != nil {
+			return err
+		}
+		if live == keyspace.Version {
+			// Dropping the index removed its alias as well, so the version has to be activated again.
+			if err := r.rd.Do(ctx, r.rd.B().Del().Key(liveVersionKey(projection)).Build()).Error(); err != nil {
+				return fmt.Errorf("failed to delete live version: %w", err)
+			}
+		}
+	} else if err := r.rd.Do(ctx, r.rd.B().Del().Key(projectionStateKey(projection, 0)).Build()).Error(); err != nil {
+		return fmt.Errorf("failed to delete projection state: %w", err)
+	}
+	r.positions.Forget(projection)
+	// Replaying the projection retries all failed events anyway.
+	if err := r.failuresOf(projector).Clear(ctx, projection); err != nil {
+		return err
+	}
+	if err := projector.Init(ctx); err != nil {
+		return fmt.Errorf("failed to init projection: %w", err)
+	}
+	return nil
+}
+
+// migrateLegacyState rebuilds a projection that has been checkpointed with a global position from before
+// journal positions were made up of transaction IDs and sequences. Such a position can't be mapped to a
+// journal position, and replaying all events on top of the existing documents would apply them twice,
+// so the documents are dropped along with the legacy checkpoint.
+// Checkpoints had not been versioned back then, so only the first version of a projection can have one.
+// No legacy checkpoint is written anymore, so each projection is migrated once per instance.
+// The caller must hold the lock of the projector.
+func (r *redisSupervisor) migrateLegacyState(ctx context.Context, projector eventing.Projector) error {
+	if _, ok := r.migrated.Load(projector.Projection()); ok {
+		return nil
+	}
+	if rdProjector, ok := projector.(RedisProjector); ok && rdProjector.Keyspace().Version > 1 {
+		r.migrated.Store(projector.Projection(), struct{}{})
+		return nil
+	}
+	legacyKey := legacyProjectionStateKey(projector.Projection())
+	exists, err := r.rd.Do(ctx, r.rd.B().Exists().Key(legacyKey).Build()).AsInt64()
 	if err != nil {
-		return fmt.Errorf("failed to query events for projection: %v", err)
+		return err
 	}
-	if err := projector.Project(ctx, events...); err != nil {
-		return fmt.Errorf("failed to project: %v", err)
+	if exists == 0 {
+		r.migrated.Store(projector.Projection(), struct{}{})
+		return nil
+	}
+	r.log.WarnContext(ctx, "Rebuilding projection with a legacy checkpoint", slog.String("projection", string(projector.Projection())), slog.String("projector_type", "redis"))
+	if err := r.reset(ctx, projector); err != nil {
+		return err
 	}
+	if err := r.rd.Do(ctx, r.rd.B().Del().Key(legacyKey).Build()).Error(); err != nil {
+		return fmt.Errorf("failed to delete legacy projection state: %w", err)
+	}
+	r.migrated.Store(projector.Projection(), struct{}{})
+	return nil
+}
 
-	updatedState := updateState(state, events)
-	val, err := json.Marshal(&updatedState)
+// Redrive projects the event of a failure of the projection once more and advances the projection afterward.
+func (r *redisSupervisor) Redrive(ctx context.Context, projection eventing.ProjectionName, eventID eventing.EventID) error {
+	ctx, span := tracing.Tracer.Start(ctx, "rd.ProjectorSupervisor.Redrive")
+	defer span.End()
+
+	r.mu.RLock()
+	projector, ok := r.projectors[projection]
+	r.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+
+	// Hold the lock, so that the event is not projected concurrently.
+	ctx, cancel, err := r.locker.WithContext(ctx, lockName(projector))
 	if err != nil {
 		return err
 	}
-	cmd = r.rd.B().JsonSet().Key(stateKey).Path(".").Value(string(val)).Build()
-	if err := r.rd.Do(ctx, cmd).Error(); err != nil {
+	defer cancel()
+
+	if err := eventing.RedriveFailure(ctx, r.es, r.failuresOf(projector), projector, eventID, projector.Project); err != nil {
 		return err
 	}
+	return r.catchUp(ctx, projector)
+}
+
+func (r *redisSupervisor) Pause(ctx context.Context, projection eventing.ProjectionName) error {
+	ctx, span := tracing.Tracer.Start(ctx, "rd.ProjectorSupervisor.Pause")
+	defer span.End()
+
+	r.mu.RLock()
+	projector, ok := r.projectors[projection]
+	r.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+
+	// Wait for a running advancement, which does not check the flag again.
+	ctx, cancel, err := r.locker.WithContext(ctx, lockName(projector))
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err := r.rd.Do(ctx, r.rd.B().Set().Key(projectionPausedKey(projection)).Value("1").Build()).Error(); err != nil {
+		return fmt.Errorf("failed to pause projection: %w", err)
+	}
+	r.log.InfoContext(ctx, "Paused projection", slog.String("projection", string(projection)), slog.String("projector_type", "redis"))
+	return nil
+}
+
+func (r *redisSupervisor) Resume(ctx context.Context, projection eventing.ProjectionName) error {
+	ctx, span := tracing.Tracer.Start(ctx, "rd.ProjectorSupervisor.Resume")
+	defer span.End()
+
+	r.mu.RLock()
+	projector, ok := r.projectors[projection]
+	r.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+
+	if err := r.rd.Do(ctx, r.rd.B().Del().Key(projectionPausedKey(projection)).Build()).Error(); err != nil {
+		return fmt.Errorf("failed to resume projection: %w", err)
+	}
+	r.log.InfoContext(ctx, "Resumed projection", slog.String("projection", string(projection)), slog.String("projector_type", "redis"))
+	return r.trigger(ctx, true, projector)
+}
+
+// paused reports whether the projection is paused. The flag applies to all versions of the projection.
+func (r *redisSupervisor) paused(ctx context.Context, projection eventing.ProjectionName) (bool, error) {
+	n, err := r.rd.Do(ctx, r.rd.B().Exists().Key(projectionPausedKey(projection)).Build()).AsInt64()
+	return n > 0, err
+}
+
+func (r *redisSupervisor) Await(ctx context.Context, projection eventing.ProjectionName, position eventing.JournalPosition) error {
+	ctx, span := tracing.Tracer.Start(ctx, "rd.ProjectorSupervisor.Await")
+	defer span.End()
+
+	r.mu.RLock()
+	projector, ok := r.projectors[projection]
+	r.mu.RUnlock()
+	if !ok {
+		return eventing.ErrProjectionNotFound
+	}
+	return eventing.AwaitProjection(ctx, r.es, projector, position, func(ctx context.Context) (eventing.JournalPosition, error) {
+		state, err := r.liveState(ctx, projector)
+		return state.Position, err
+	})
+}
+
+func (r *redisSupervisor) Status(ctx context.Context) ([]eventing.ProjectionStatus, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "rd.ProjectorSupervisor.Status")
+	defer span.End()
+
+	r.mu.RLock()
+	projectors := slices.Collect(maps.Values(r.projectors))
+	r.mu.RUnlock()
+
+	statuses := make([]eventing.ProjectionStatus, 0, len(projectors))
+	for _, projector := range projectors {
+		state, err := r.liveState(ctx, projector)
+		if err != nil {
+			return nil, err
+		}
+		state.Paused, err = r.paused(ctx, projector.Projection())
+		if err != nil {
+			return nil, err
+		}
+		status, err := eventing.NewProjectionStatus(ctx, r.es, projector, state, &r.counters)
+		if err != nil {
+			return nil, err
+		}
+		status.Supervisor = supervisorType
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// liveState returns the state of the version of the projection that serves queries.
+// It might not be the version this instance writes, e.g. while a new version is being built.
+func (r *redisSupervisor) liveState(ctx context.Context, projector eventing.Projector) (eventing.ProjectionState, error) {
+	projection := projector.Projection()
+	var version int
+	if _, ok := projector.(RedisProjector); ok {
+		live, err := LiveVersion(ctx, r.rd, projection)
+		if err != nil {
+			return eventing.ProjectionState{}, err
+		}
+		version = live
+	}
+	state := eventing.ProjectionState{Name: projection}
+	cmd := r.rd.B().JsonGet().Key(projectionStateKey(projection, version)).Path(".").Build()
+	if err := r.rd.Do(ctx, cmd).DecodeJSON(&state); rueidis.IsRedisNil(err) {
+		return eventing.ProjectionState{Name: projection}, nil
+	} else if err != nil {
+		return eventing.ProjectionState{}, err
+	}
+	return state, nil
+}
+
+// drop removes the index, the keys and the state of a version of the keyspace.
+func (r *redisSupervisor) drop(ctx context.Context, keyspace Keyspace, version int) error {
+	index := keyspace.IndexName(version)
+	if err := r.dropIndex(ctx, index); err != nil {
+		return fmt.Errorf("failed to drop index %s: %w", index, err)
+	}
+	for _, prefix := range []string{keyspace.Prefix(version), keyspace.LookupPrefix(version)} {
+		if err := r.deleteByPrefix(ctx, prefix); err != nil {
+			return fmt.Errorf("failed to delete keys with prefix %s: %w", prefix, err)
+		}
+	}
+	if err := r.rd.Do(ctx, r.rd.B().Del().Key(projectionStateKey(keyspace.Projection, version)).Build()).Error(); err != nil {
+		return fmt.Errorf("failed to delete projection state: %w", err)
+	}
+	// The failures of the version would never be resolved otherwise.
+	failures := &versionedFailures{ProjectionFailureStore: r.failures, version: version}
+	if err := failures.Clear(ctx, keyspace.Projection); err != nil {
+		return fmt.Errorf("failed to clear failures: %w", err)
+	}
 	return nil
 }
 
+func (r *redisSupervisor) dropIndex(ctx context.Context, index string) error {
+	err := r.rd.Do(ctx, r.rd.B().FtDropindex().Index(index).Build()).Error()
+	if rderr, ok := rueidis.IsRedisErr(err); ok && strings.EqualFold(rderr.Error(), "Unknown Index name") {
+		// The index has not been created yet.
+		return nil
+	}
+	return err
+}
+
+func (r *redisSupervisor) deleteByPrefix(ctx context.Context, prefix string) error {
+	var cursor uint64
+	for {
+		cmd := r.rd.B().Scan().Cursor(cursor).Match(prefix + "*").Count(scanBatchSize).Build()
+		entry, err := r.rd.Do(ctx, cmd).AsScanEntry()
+		if err != nil {
+			return err
+		}
+		if len(entry.Elements) > 0 {
+			if err := r.rd.Do(ctx, r.rd.B().Unlink().Key(entry.Elements...).Build()).Error(); err != nil {
+				return err
+			}
+		}
+		if entry.Cursor == 0 {
+			return nil
+		}
+		cursor = entry.Cursor
+	}
+}
+
+// versionedName distinguishes the state and the lock of the versions of a projection.
+// Version 1 keeps the names from before projections were versioned.
+func versionedName(projection eventing.ProjectionName, version int) string {
+	if version <= 1 {
+		return string(projection)
+	}
+	return fmt.Sprintf("%s@v%d", projection, version)
+}
+
+// lockName returns the name of the lock that guards the version the projector writes.
+func lockName(projector eventing.Projector) string {
+	if rdProjector, ok := projector.(RedisProjector); ok {
+		return versionedName(projector.Projection(), rdProjector.Keyspace().Version)
+	}
+	return string(projector.Projection())
+}
+
+func projectionStateKey(projection eventing.ProjectionName, version int) string {
+	return fmt.Sprintf("projection:state:%s:v2", versionedName(projection, version))
+}
+
+// legacyProjectionStateKey returns the key of the checkpoint from before journal positions were transaction based.
+func legacyProjectionStateKey(projection eventing.ProjectionName) string {
+	return fmt.Sprintf("projection:state:%s:v1", projection)
+}
+
+func projectionPausedKey(projection eventing.ProjectionName) string {
+	return fmt.Sprintf("projection:state:%s:paused", projection)
+}
+
 func updateState(state eventing.ProjectionState, events []*eventing.JournalEvent) eventing.ProjectionState {
 	if len(events) == 0 {
 		return eventing.ProjectionState{
@@ -185,7 +687,7 @@ func updateState(state eventing.ProjectionState, events []*eventing.JournalEvent
 			LastProcessedEventID:   state.LastProcessedEventID,
 			LastProcessedTimestamp: state.LastProcessedTimestamp,
 			AggregateVersion:       state.AggregateVersion,
-			GlobalPosition:         state.GlobalPosition,
+			Position:               state.Position,
 			UpdatedAt:              time.Now(),
 		}
 	}
@@ -193,7 +695,7 @@ func updateState(state eventing.ProjectionState, events []*eventing.JournalEvent
 	// Find the last event.
 	var lastEvent *eventing.JournalEvent
 	for _, event := range events {
-		if lastEvent == nil || event.JournalPosition().Deref().GreaterThan(lastEvent.JournalPosition().Deref()) {
+		if lastEvent == nil || event.JournalPosition().After(lastEvent.JournalPosition()) {
 			lastEvent = event
 		}
 	}
@@ -204,7 +706,7 @@ func updateState(state eventing.ProjectionState, events []*eventing.JournalEvent
 		LastProcessedEventID:   &eventID,
 		LastProcessedTimestamp: &processedTimestamp,
 		AggregateVersion:       lastEvent.AggregateVersion(),
-		GlobalPosition:         lastEvent.JournalPosition().Deref(),
+		Position:               lastEvent.JournalPosition(),
 		UpdatedAt:              time.Now(),
 	}
 }
@@ -0,0 +1,137 @@
+package eventing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/rueidis"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pendingWrites collects the write commands of a projector while the supervisor projects an event.
+type pendingWrites struct {
+	cmds rueidis.Commands
+}
+
+type pendingWritesCtxKey struct{}
+
+func newContextWithPendingWrites(ctx context.Context) (context.Context, *pendingWrites) {
+	writes := &pendingWrites{}
+	return context.WithValue(ctx, pendingWritesCtxKey{}, writes), writes
+}
+
+// Write executes the write commands of a projector.
+// While the supervisor projects an event, the commands are deferred and executed atomically along with
+// the checkpoint of the event. Projectors therefore must not expect to read their own writes of the same event.
+// If one of the commands fails, the ones that succeeded are applied anyway and the event is projected again.
+// Projectors must therefore write idempotently, e.g. by setting the paths of maps instead of appending to arrays,
+// and by replacing the entries of read-modify-write documents that have the same ID.
+func Write(ctx context.Context, rd rueidis.Client, cmds ...rueidis.Completed) error {
+	if writes, ok := ctx.Value(pendingWritesCtxKey{}).(*pendingWrites); ok {
+		writes.cmds = append(writes.cmds, cmds...)
+		return nil
+	}
+	for _, resp := range rd.DoMulti(ctx, cmds...) {
+		if err := resp.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execTx executes the commands in a MULTI/EXEC transaction.
+// err is only set if the transaction has not been applied. Redis still applies the remaining commands of a
+// transaction if one of them fails at runtime, so the errors of such commands are returned as cmdErr.
+func execTx(ctx context.Context, rd rueidis.Client, cmds ...rueidis.Completed) (cmdErr error, err error) {
+	multi := make(rueidis.Commands, 0, len(cmds)+2)
+	multi = append(multi, rd.B().Multi().Build())
+	multi = append(multi, cmds...)
+	multi = append(multi, rd.B().Exec().Build())
+
+	// Commands that fail to queue abort the whole transaction, which is reported by EXEC.
+	resps := rd.DoMulti(ctx, multi...)
+	results, err := resps[len(resps)-1].ToArray()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute transaction: %w", err)
+	}
+	for _, result := range results {
+		cmdErr = errors.Join(cmdErr, result.Error())
+	}
+	return cmdErr, nil
+}
+
+// checkpointScript executes the write commands of an event and only then checkpoints the event, if all of them succeeded.
+// Scripts run atomically just like transactions, but unlike a transaction the script can skip the checkpoint.
+// KEYS[1] is the key of the projection state, followed by all keys the commands write, as scripts have to declare
+// the keys they access. ARGV[1] is the state after the event, followed by the commands, each one as its number of
+// arguments and the arguments themselves.
+var checkpointScript = rueidis.NewLuaScript(`
+local failed = {}
+local i = 2
+while i <= #ARGV do
+	local argc = tonumber(ARGV[i])
+	local reply = redis.pcall(unpack(ARGV, i + 1, i + argc))
+	if type(reply) == 'table' and reply.err then
+		failed[#failed + 1] = reply.err
+	end
+	i = i + argc + 1
+end
+if #failed == 0 then
+	redis.call('JSON.SET', KEYS[1], '.', ARGV[1])
+end
+return failed
+`)
+
+// execCheckpointed executes the commands and writes the state to the key afterward, unless one of the commands failed.
+// As with execTx, err is only set if nothing has been applied and the errors of failed commands are returned as cmdErr.
+// The commands that succeeded are applied regardless, so projectors have to write idempotently.
+func execCheckpointed(ctx context.Context, rd rueidis.Client, stateKey string, state []byte, cmds ...rueidis.Completed) (cmdErr error, err error) {
+	keys := []string{stateKey}
+	args := []string{string(state)}
+	for _, cmd := range cmds {
+		parts := cmd.Commands()
+		for _, key := range writtenKeys(parts) {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+		args = append(args, strconv.Itoa(len(parts)))
+		args = append(args, parts...)
+	}
+	failed, err := checkpointScript.Exec(ctx, rd, keys, args).AsStrSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute writes: %w", err)
+	}
+	for _, msg := range failed {
+		cmdErr = errors.Join(cmdErr, errors.New(msg))
+	}
+	return cmdErr, nil
+}
+
+// writtenKeys returns the keys the command writes.
+// Most commands write the key that follows their name, only the ones writing several keys need to be listed.
+func writtenKeys(parts []string) []string {
+	if len(parts) < 2 {
+		return nil
+	}
+	switch strings.ToUpper(parts[0]) {
+	case "DEL", "UNLINK":
+		return parts[1:]
+	case "MSET":
+		return everyNth(parts[1:], 2)
+	case "JSON.MSET":
+		return everyNth(parts[1:], 3)
+	}
+	return parts[1:2]
+}
+
+// everyNth returns the first of every n elements.
+func everyNth(parts []string, n int) []string {
+	var nth []string
+	for i := 0; i < len(parts); i += n {
+		nth = append(nth, parts[i])
+	}
+	return nth
+}
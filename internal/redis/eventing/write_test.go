@@ -0,0 +1,283 @@
+package eventing
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/redis/rueidis"
+	"github.com/rsmidt/soccerbuddy/internal/core/idgen"
+	"github.com/rsmidt/soccerbuddy/internal/eventing"
+	"github.com/rsmidt/soccerbuddy/internal/redis"
+)
+
+type testEvent struct {
+	*eventing.EventBase
+}
+
+func (e *testEvent) IsShredded() bool {
+	return false
+}
+
+func newTestJournalEvent(sequence uint64) *eventing.JournalEvent {
+	event := &testEvent{EventBase: eventing.NewEventBase("aggregate", "test", "v1", "TestEvent")}
+	position := eventing.JournalPosition{TransactionID: 1, Sequence: sequence}
+	return eventing.NewJournalEvent(event, idgen.New[eventing.EventID](), eventing.AggregateVersion(sequence), position, time.Now())
+}
+
+// testJournal is an event store that only answers the queries of a supervisor.
+type testJournal struct {
+	eventing.EventStore
+	events []*eventing.JournalEvent
+}
+
+func (j *testJournal) Query(_ context.Context, query eventing.JournalQuery, _ ...eventing.QueryOpts) ([]*eventing.JournalEvent, error) {
+	var events []*eventing.JournalEvent
+	for _, event := range j.events {
+		if after := query.JournalPositionAfter(); after != nil && !event.JournalPosition().After(*after) {
+			continue
+		}
+		if query.Limit() > 0 && len(events) == query.Limit() {
+			break
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// testFailures records the failed events without holding back the projection, so that they are retried right away.
+type testFailures struct {
+	eventing.ProjectionFailureStore
+	recorded []eventing.EventID
+}
+
+func (f *testFailures) Record(_ context.Context, projection eventing.ProjectionName, event *eventing.JournalEvent, cause error, policy eventing.FailurePolicy) (*eventing.ProjectionFailure, error) {
+	f.recorded = append(f.recorded, event.EventID())
+	return &eventing.ProjectionFailure{Projection: projection, EventID: event.EventID(), State: eventing.NextFailureState(policy, 1), Attempts: 1, LastError: cause.Error()}, nil
+}
+
+func (f *testFailures) Blocked(context.Context, eventing.ProjectionName) (bool, error) {
+	return false, nil
+}
+
+func (f *testFailures) Resolve(context.Context, eventing.ProjectionName) error {
+	return nil
+}
+
+// testWritingProjector sets a key per event. The write of the poisoned event fails at runtime.
+type testWritingProjector struct {
+	rd        rueidis.Client
+	name      string
+	poisoned  eventing.EventID
+	projected map[eventing.EventID]int
+}
+
+func (p *testWritingProjector) Init(context.Context) error {
+	return nil
+}
+
+func (p *testWritingProjector) Query() eventing.JournalQuery {
+	var builder eventing.JournalQueryBuilder
+	return builder.WithAggregate("test").Events("TestEvent").Finish().MustBuild()
+}
+
+func (p *testWritingProjector) Projection() eventing.ProjectionName {
+	return eventing.ProjectionName(p.name)
+}
+
+func (p *testWritingProjector) Project(ctx context.Context, events ...*eventing.JournalEvent) error {
+	for _, event := range events {
+		p.projected[event.EventID()]++
+		cmds := rueidis.Commands{p.rd.B().Set().Key(p.key(event)).Value("value").Build()}
+		if event.EventID() == p.poisoned {
+			// INCR fails on a value that is not a number.
+			cmds = append(cmds, p.rd.B().Incr().Key(p.key(event)).Build())
+		}
+		if err := Write(ctx, p.rd, cmds...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *testWritingProjector) key(event *eventing.JournalEvent) string {
+	return p.name + ":" + string(event.EventID())
+}
+
+func exists(t *testing.T, rd rueidis.Client, key string) bool {
+	t.Helper()
+	n, err := rd.Do(context.Background(), rd.B().Exists().Key(key).Build()).AsInt64()
+	if err != nil {
+		t.Fatalf("failed to check key %s: %v", key, err)
+	}
+	return n == 1
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	rd, cleanup := redis.GetTestClient()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+	prefix := "test:" + idgen.NewString()
+
+	// Outside of a projection, the commands are executed right away.
+	if err := Write(ctx, rd, rd.B().Set().Key(prefix+":direct").Value("value").Build()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exists(t, rd, prefix+":direct") {
+		t.Errorf("expected the direct write to be executed")
+	}
+
+	// While projecting, they are deferred until the supervisor executes them along with the checkpoint.
+	writesCtx, writes := newContextWithPendingWrites(ctx)
+	if err := Write(writesCtx, rd, rd.B().Set().Key(prefix+":deferred").Value("value").Build()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exists(t, rd, prefix+":deferred") || len(writes.cmds) != 1 {
+		t.Errorf("expected the write to be deferred, got %d pending commands", len(writes.cmds))
+	}
+}
+
+func TestExecTx(t *testing.T) {
+	t.Parallel()
+
+	rd, cleanup := redis.GetTestClient()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+	prefix := "test:" + idgen.NewString()
+
+	cmdErr, err := execTx(ctx, rd,
+		rd.B().Set().Key(prefix+":a").Value("value").Build(),
+		rd.B().Incr().Key(prefix+":a").Build(),
+		rd.B().Set().Key(prefix+":b").Value("value").Build(),
+	)
+	if err != nil {
+		t.Fatalf("expected the transaction to be applied, got %v", err)
+	}
+	if cmdErr == nil {
+		t.Errorf("expected the error of INCR to be reported")
+	}
+	// Redis applies the remaining commands regardless.
+	if !exists(t, rd, prefix+":b") {
+		t.Errorf("expected the commands after the failed one to be applied")
+	}
+}
+
+func TestExecCheckpointed(t *testing.T) {
+	t.Parallel()
+
+	rd, cleanup := redis.GetTestClient()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+	prefix := "test:" + idgen.NewString()
+
+	cmdErr, err := execCheckpointed(ctx, rd, prefix+":state", []byte(`{"name":"test"}`), rd.B().Set().Key(prefix+":a").Value("value").Build())
+	if err != nil || cmdErr != nil {
+		t.Fatalf("unexpected error: %v, %v", err, cmdErr)
+	}
+	if !exists(t, rd, prefix+":a") || !exists(t, rd, prefix+":state") {
+		t.Errorf("expected the write and the checkpoint to be applied")
+	}
+
+	cmdErr, err = execCheckpointed(ctx, rd, prefix+":skipped", []byte(`{"name":"test"}`),
+		rd.B().Incr().Key(prefix+":a").Build(),
+		rd.B().Set().Key(prefix+":b").Value("value").Build(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdErr == nil {
+		t.Errorf("expected the error of INCR to be reported")
+	}
+	if exists(t, rd, prefix+":skipped") {
+		t.Errorf("expected the checkpoint to be skipped")
+	}
+	if !exists(t, rd, prefix+":b") {
+		t.Errorf("expected the commands after the failed one to be applied")
+	}
+}
+
+func TestCatchUpReplaysPartialBatch(t *testing.T) {
+	t.Parallel()
+
+	rd, cleanup := redis.GetTestClient()
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	events := []*eventing.JournalEvent{newTestJournalEvent(1), newTestJournalEvent(2), newTestJournalEvent(3)}
+	failures := &testFailures{}
+	projector := &testWritingProjector{
+		rd:        rd,
+		name:      "test:" + idgen.NewString(),
+		poisoned:  events[1].EventID(),
+		projected: make(map[eventing.EventID]int),
+	}
+	r := &redisSupervisor{
+		log:      slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		es:       &testJournal{events: events},
+		failures: failures,
+		rd:       rd,
+	}
+	stateKey := projectionStateKey(projector.Projection(), 0)
+	readPosition := func() eventing.JournalPosition {
+		var state eventing.ProjectionState
+		if err := rd.Do(ctx, rd.B().JsonGet().Key(stateKey).Path(".").Build()).DecodeJSON(&state); err != nil {
+			t.Fatalf("failed to read projection state: %v", err)
+		}
+		return state.Position
+	}
+
+	// The event with the failed write is not checkpointed, but recorded as failure.
+	if err := r.catchUp(ctx, projector); err == nil {
+		t.Fatalf("expected the failed write to fail the projection")
+	}
+	if position := readPosition(); position != events[0].JournalPosition() {
+		t.Errorf("expected the projection to stop at %s, got %s", events[0].JournalPosition(), position)
+	}
+	if len(failures.recorded) != 1 || failures.recorded[0] != events[1].EventID() {
+		t.Errorf("expected the failure of %s to be recorded, got %v", events[1].EventID(), failures.recorded)
+	}
+	if exists(t, rd, projector.key(events[2])) {
+		t.Errorf("expected the events after the failed one not to be projected")
+	}
+
+	// The next run replays the batch from the failed event on.
+	projector.poisoned = ""
+	if err := r.catchUp(ctx, projector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if position := readPosition(); position != events[2].JournalPosition() {
+		t.Errorf("expected the projection to reach %s, got %s", events[2].JournalPosition(), position)
+	}
+	if projector.projected[events[0].EventID()] != 1 || projector.projected[events[2].EventID()] != 1 {
+		t.Errorf("expected the checkpointed events to be projected exactly once, got %v", projector.projected)
+	}
+	if !exists(t, rd, projector.key(events[2])) {
+		t.Errorf("expected the last event to be projected")
+	}
+}
+
+func TestWrittenKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []string
+		want  []string
+	}{
+		{name: "single key", parts: []string{"JSON.SET", "a", "$", "{}"}, want: []string{"a"}},
+		{name: "del", parts: []string{"DEL", "a", "b"}, want: []string{"a", "b"}},
+		{name: "mset", parts: []string{"MSET", "a", "1", "b", "2"}, want: []string{"a", "b"}},
+		{name: "json mset", parts: []string{"JSON.MSET", "a", "$", "{}", "b", "$", "{}"}, want: []string{"a", "b"}},
+		{name: "no key", parts: []string{"PING"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := writtenKeys(tt.parts); !slices.Equal(got, tt.want) {
+				t.Errorf("expected keys %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package redis
+
+import (
+	"github.com/redis/rueidis"
+	"github.com/rsmidt/soccerbuddy/internal/core"
+)
+
+// GetTestClient connects to the redis of the docker compose setup.
+// All tests share the instance, so each test has to write keys of its own.
+func GetTestClient() (rueidis.Client, func()) {
+	client := core.Must2(rueidis.NewClient(rueidis.ClientOption{InitAddress: []string{"localhost:6379"}}))
+	return client, client.Close
+}
@@ -0,0 +1,22 @@
+-- Create table to store the latest snapshot of an aggregate.
+CREATE TABLE aggregate_snapshot
+(
+    aggregate_id      TEXT                     NOT NULL,
+    aggregate_type    TEXT                     NOT NULL,
+
+    -- The aggregate version the snapshot was taken at.
+    aggregate_version BIGINT                   NOT NULL,
+
+    -- The schema version of the payload. Snapshots with an outdated version are discarded.
+    snapshot_version  BIGINT                   NOT NULL,
+
+    payload           BYTEA                    NOT NULL,
+
+    -- Encrypted payloads are encrypted with the key of the aggregate.
+    encrypted         BOOLEAN                  NOT NULL DEFAULT FALSE,
+
+    created_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (aggregate_id, aggregate_type)
+);
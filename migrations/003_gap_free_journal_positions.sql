@@ -0,0 +1,44 @@
+-- Replace the timestamp based global position with the ID of the appending transaction and a sequence.
+-- Sequence values are acquired in insertion order, not in commit order. Ordering by the transaction ID first
+-- allows readers to only consider transactions that are older than the oldest running one without skipping events.
+CREATE SEQUENCE event_journal_sequence;
+
+ALTER TABLE event_journal
+    ADD COLUMN transaction_id xid8,
+    ADD COLUMN sequence       BIGINT;
+
+-- Existing events keep their order and are treated as appended by the very first transaction.
+UPDATE event_journal ej
+SET transaction_id = '0'::xid8,
+    sequence       = ordered.sequence
+FROM (SELECT id, row_number() OVER (ORDER BY global_position, id) AS sequence FROM event_journal) ordered
+WHERE ej.id = ordered.id;
+
+SELECT setval('event_journal_sequence', COALESCE((SELECT max(sequence) FROM event_journal), 0) + 1, false);
+
+ALTER TABLE event_journal
+    ALTER COLUMN transaction_id SET DEFAULT pg_current_xact_id(),
+    ALTER COLUMN transaction_id SET NOT NULL,
+    ALTER COLUMN sequence SET DEFAULT nextval('event_journal_sequence'),
+    ALTER COLUMN sequence SET NOT NULL;
+
+ALTER SEQUENCE event_journal_sequence OWNED BY event_journal.sequence;
+
+-- Move the projection states to the new positions.
+ALTER TABLE projection_state
+    ADD COLUMN transaction_id xid8   NOT NULL DEFAULT '0',
+    ADD COLUMN sequence       BIGINT NOT NULL DEFAULT 0;
+
+UPDATE projection_state ps
+SET sequence = COALESCE((SELECT max(ej.sequence) FROM event_journal ej WHERE ej.global_position <= ps.global_position), 0);
+
+DROP INDEX idx_event_journal_projection;
+ALTER TABLE event_journal DROP COLUMN global_position;
+ALTER TABLE projection_state DROP COLUMN global_position;
+DROP FUNCTION global_position();
+
+-- Create index for position-based querying.
+CREATE INDEX idx_event_journal_position ON event_journal (transaction_id, sequence);
+
+-- Create index for projection querying.
+CREATE INDEX idx_event_journal_projection ON event_journal (aggregate_type, event_type, transaction_id, sequence);
@@ -0,0 +1,8 @@
+-- Data keys are wrapped with a key-encryption key, identified by key_id.
+-- Rows without a key_id still hold a plain data key and are wrapped by the next key rotation.
+ALTER TABLE keys
+    ADD COLUMN key_id     TEXT,
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+-- Create index to find keys that are not wrapped with the active key-encryption key.
+CREATE INDEX idx_keys_key_id ON keys (key_id);
@@ -0,0 +1,15 @@
+-- The outbox holds the events that still have to be delivered to a handler.
+-- Entries are written in the same transaction as their events and deleted once delivered.
+CREATE TABLE outbox
+(
+    id              TEXT PRIMARY KEY,
+    handler         TEXT    NOT NULL,
+    event_id        TEXT    NOT NULL REFERENCES event_journal (id) ON DELETE CASCADE,
+    attempts        INTEGER NOT NULL         DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index to find the due entries of a handler.
+CREATE INDEX idx_outbox_handler_next_attempt_at ON outbox (handler, next_attempt_at);
@@ -0,0 +1,13 @@
+-- Events a projector failed to project, either waiting for their next attempt, dead-lettered or halting the projection.
+CREATE TABLE projection_failure
+(
+    projection_name TEXT    NOT NULL,
+    event_id        TEXT    NOT NULL REFERENCES event_journal (id) ON DELETE CASCADE,
+    state           TEXT    NOT NULL,
+    attempts        INTEGER NOT NULL         DEFAULT 0,
+    last_error      TEXT    NOT NULL,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (projection_name, event_id)
+);
@@ -0,0 +1,55 @@
+-- Read models for deployments that project them to postgres instead of redis.
+-- Every projection is stored as the same JSON document as in redis, the columns that are filtered on are derived from it.
+CREATE TABLE projection_account
+(
+    id       TEXT PRIMARY KEY,
+    document JSONB NOT NULL
+);
+
+CREATE TABLE projection_club
+(
+    id       TEXT PRIMARY KEY,
+    document JSONB NOT NULL
+);
+
+CREATE TABLE projection_person
+(
+    id             TEXT PRIMARY KEY,
+    owning_club_id TEXT GENERATED ALWAYS AS (document ->> 'owning_club_id') STORED,
+    document       JSONB NOT NULL
+);
+
+-- Create indices to list the persons of a club and to search them by their pending links and teams.
+CREATE INDEX idx_projection_person_owning_club_id ON projection_person (owning_club_id);
+CREATE INDEX idx_projection_person_document ON projection_person USING GIN (document jsonb_path_ops);
+
+CREATE TABLE projection_team
+(
+    id             TEXT PRIMARY KEY,
+    owning_club_id TEXT GENERATED ALWAYS AS (document ->> 'owning_club_id') STORED,
+    document       JSONB NOT NULL
+);
+
+CREATE INDEX idx_projection_team_owning_club_id ON projection_team (owning_club_id);
+
+CREATE TABLE projection_training
+(
+    id              TEXT PRIMARY KEY,
+    owning_team_id  TEXT GENERATED ALWAYS AS (document ->> 'owning_team_id') STORED,
+    scheduled_at_ts BIGINT GENERATED ALWAYS AS ((document ->> 'scheduled_at_ts')::BIGINT) STORED,
+    document        JSONB NOT NULL
+);
+
+-- Create indices to list the upcoming trainings of a team and to find the trainings a person is nominated for.
+CREATE INDEX idx_projection_training_owning_team_id_scheduled_at_ts ON projection_training (owning_team_id, scheduled_at_ts);
+CREATE INDEX idx_projection_training_document ON projection_training USING GIN (document jsonb_path_ops);
+
+-- Lookups are the data of other aggregates that a projector keeps to denormalize it into its documents.
+CREATE TABLE projection_lookup
+(
+    projection_name TEXT  NOT NULL,
+    kind            TEXT  NOT NULL,
+    id              TEXT  NOT NULL,
+    document        JSONB NOT NULL,
+    PRIMARY KEY (projection_name, kind, id)
+);
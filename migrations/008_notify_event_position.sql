@@ -0,0 +1,20 @@
+-- Send the position of the appended event along with its type, so that listeners can skip events they already projected.
+-- The transaction ID is sent as a string, as it exceeds the integer range of JSON numbers.
+CREATE OR REPLACE FUNCTION notify_event_store()
+    RETURNS trigger AS $$
+DECLARE
+    channel_name TEXT;
+BEGIN
+    -- Set the channel name based on the aggregate_type.
+    channel_name := 'event_store_' || NEW.aggregate_type;
+
+    -- Send the notification to the grouped channel
+    PERFORM pg_notify(channel_name, json_build_object(
+        'event_type', NEW.event_type,
+        'transaction_id', NEW.transaction_id::TEXT,
+        'sequence', NEW.sequence
+    )::TEXT);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+-- Allow operators to pause projections on all instances.
+ALTER TABLE projection_state
+    ADD COLUMN paused BOOLEAN NOT NULL DEFAULT FALSE;
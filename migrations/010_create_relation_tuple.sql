@@ -0,0 +1,14 @@
+-- Relations evaluated by the embedded authorizer for deployments without a permify server.
+-- Every row is a permify tuple "entity_type:entity_id#relation@subject_type:subject_id".
+CREATE TABLE relation_tuple
+(
+    entity_type  TEXT NOT NULL,
+    entity_id    TEXT NOT NULL,
+    relation     TEXT NOT NULL,
+    subject_type TEXT NOT NULL,
+    subject_id   TEXT NOT NULL,
+    PRIMARY KEY (entity_type, entity_id, relation, subject_type, subject_id)
+);
+
+-- Create an index to look up the entities related to a subject and to remove all relations of a subject.
+CREATE INDEX idx_relation_tuple_subject ON relation_tuple (subject_type, subject_id, entity_type, relation);
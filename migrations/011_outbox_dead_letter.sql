@@ -0,0 +1,7 @@
+-- Entries that exceeded their attempts are kept as dead letters instead of being retried forever.
+ALTER TABLE outbox
+    ADD COLUMN dead_lettered_at TIMESTAMP WITH TIME ZONE;
+
+-- Dead letters are never due again.
+DROP INDEX idx_outbox_handler_next_attempt_at;
+CREATE INDEX idx_outbox_handler_next_attempt_at ON outbox (handler, next_attempt_at) WHERE dead_lettered_at IS NULL;